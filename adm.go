@@ -0,0 +1,142 @@
+package main
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rokett/citrix-netscaler-exporter/config"
+	"github.com/rokett/citrix-netscaler-exporter/netscaler"
+)
+
+var (
+	// admInstances maps the URL of each instance discovered from NetScaler ADM to the instance, so that scrapes of it are proxied through ADM.
+	admInstances   = make(map[string]admInstance)
+	admInstancesMu sync.Mutex
+)
+
+// admInstance is an instance managed by NetScaler ADM.
+type admInstance struct {
+	// adm is the URL of the NetScaler ADM which manages the instance.
+	adm string
+	ip  string
+}
+
+// listADMInstances logs in to NetScaler ADM and lists the instances it manages, returning the URL of each one.
+// The instances are remembered, so that the exporter connects to them through ADM; any which ADM no longer manages are forgotten.
+// Instances which are also listed as targets in the configuration file, by IP address or by hostname, are left out, so that they are still connected to directly.
+func listADMInstances(adm config.ADM) ([]string, error) {
+	nsClient, err := netscaler.NewNitroClient(adm.URL, admUser(), admPass(), adm.IgnoreCert)
+	if err != nil {
+		return nil, err
+	}
+	defer nsClient.CloseIdleConnection()
+
+	err = netscaler.Connect(nsClient)
+	if err != nil {
+		return nil, err
+	}
+	defer netscaler.Disconnect(nsClient)
+
+	devices, err := netscaler.GetManagedDevices(nsClient, netscaler.Query{})
+	if err != nil {
+		return nil, err
+	}
+
+	types := make(map[string]bool)
+	for _, t := range adm.Types {
+		types[strings.ToLower(t)] = true
+	}
+
+	current := make(map[string]admInstance)
+
+	for _, d := range devices {
+		if d.IPAddress == "" || (len(types) > 0 && !types[strings.ToLower(d.Type)]) {
+			continue
+		}
+
+		if configuredTarget(d.IPAddress) != "" {
+			continue
+		}
+
+		u := admInstanceURL(d.IPAddress)
+
+		current[u] = admInstance{
+			adm: adm.URL,
+			ip:  d.IPAddress,
+		}
+	}
+
+	admInstancesMu.Lock()
+	defer admInstancesMu.Unlock()
+
+	for u, inst := range admInstances {
+		if _, ok := current[u]; !ok && inst.adm == adm.URL {
+			delete(admInstances, u)
+		}
+	}
+
+	urls := make([]string, 0, len(current))
+	for u, inst := range current {
+		admInstances[u] = inst
+		urls = append(urls, u)
+	}
+
+	sort.Strings(urls)
+
+	return urls, nil
+}
+
+// admInstanceURL returns the target URL for an instance managed by NetScaler ADM.
+// The exporter never connects to the URL; it identifies the instance in the target parameter and the ns_instance label.
+func admInstanceURL(ip string) string {
+	host := ip
+	if strings.Contains(ip, ":") {
+		host = "[" + ip + "]"
+	}
+
+	return (&url.URL{Scheme: "https", Host: host}).String()
+}
+
+// admEndpoint returns the NetScaler ADM instance for the target, if the target was discovered from ADM.
+func admEndpoint(target string) (admInstance, bool) {
+	admInstancesMu.Lock()
+	defer admInstancesMu.Unlock()
+
+	inst, ok := admInstances[target]
+
+	return inst, ok
+}
+
+// admProxyInstance returns the IP address which requests for the target are proxied to through NetScaler ADM, or empty if the target is not managed by ADM.
+func admProxyInstance(target string) string {
+	inst, ok := admEndpoint(target)
+	if !ok {
+		return ""
+	}
+
+	return inst.ip
+}
+
+// admUser and admPass return the credentials for NetScaler ADM; the NetScaler credentials are used if none are given.
+func admUser() string {
+	if *admUsername != "" {
+		return *admUsername
+	}
+
+	return *username
+}
+
+func admPass() string {
+	if *admUsername != "" {
+		return *admPassword
+	}
+
+	return *password
+}
+
+// admLabels returns the labels added by service discovery to the instances of a NetScaler ADM.
+func admLabels(adm config.ADM) map[string]string {
+	return map[string]string{"adm": targetHost(adm.URL)}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rokett/citrix-netscaler-exporter/config"
+	"github.com/rokett/citrix-netscaler-exporter/netscaler/nitrotest"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+// admTestServer returns a server standing in for NetScaler ADM, and points the exporter's ADM credentials at it for the rest of the test.
+func admTestServer(t *testing.T) *nitrotest.Server {
+	t.Helper()
+
+	adm := nitrotest.NewServer()
+	adm.Username = "admuser"
+	adm.Password = "admpass"
+
+	user, pass, c := *admUsername, *admPassword, cfg
+	*admUsername, *admPassword = adm.Username, adm.Password
+	logger = log.NewNopLogger()
+
+	t.Cleanup(func() {
+		adm.Close()
+
+		*admUsername, *admPassword, cfg = user, pass, c
+
+		admInstancesMu.Lock()
+		admInstances = make(map[string]admInstance)
+		admInstancesMu.Unlock()
+	})
+
+	return adm
+}
+
+func TestListADMInstances(t *testing.T) {
+	adm := admTestServer(t)
+
+	adm.SetFixture("config/managed_device", []byte(`{"errorcode":0,"message":"Done","managed_device":[`+
+		`{"ip_address":"192.0.2.10","type":"nsvpx"},{"ip_address":"192.0.2.11","type":"nsmpx"},`+
+		`{"ip_address":"192.0.2.12","type":"NSVPX"},{"ip_address":"2001:db8::10","type":"nsvpx"},{"ip_address":"","type":"nsvpx"},`+
+		`{"ip_address":"192.0.2.13","type":"nsvpx"}]}`))
+
+	// An instance which is also configured as a target, by IP address or by hostname, is still connected to directly.
+	cfg = &config.Config{Targets: []config.Target{{URL: "https://192.0.2.12"}, {URL: "https://ns13.domain.tld"}}}
+	stubLookupHost(t, map[string][]string{"ns13.domain.tld": {"192.0.2.13"}})
+
+	admConfig := config.ADM{Target: config.Target{URL: adm.URL}, Types: []string{"nsvpx"}}
+
+	urls, err := listADMInstances(admConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"https://192.0.2.10", "https://[2001:db8::10]"}
+	if !reflect.DeepEqual(urls, want) {
+		t.Errorf("listADMInstances() = %v, want %v", urls, want)
+	}
+
+	if ip := admProxyInstance("https://[2001:db8::10]"); ip != "2001:db8::10" {
+		t.Errorf("admProxyInstance() = %q, want 2001:db8::10", ip)
+	}
+
+	url, user, pass, err := nitroEndpoint("https://192.0.2.10")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if url != adm.URL || user != "admuser" || pass != "admpass" {
+		t.Errorf("nitroEndpoint() = %q, %q, %q, want the ADM URL and credentials", url, user, pass)
+	}
+
+	if n := adm.Requests("config/logout"); n != 1 {
+		t.Errorf("logged out of ADM %d times, want 1", n)
+	}
+
+	// Instances which ADM no longer manages are forgotten.
+	adm.SetFixture("config/managed_device", []byte(`{"errorcode":0,"message":"Done","managed_device":[{"ip_address":"192.0.2.10","type":"nsvpx"}]}`))
+
+	urls, err = listADMInstances(admConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(urls) != 1 || urls[0] != "https://192.0.2.10" {
+		t.Errorf("listADMInstances() = %v, want https://192.0.2.10", urls)
+	}
+
+	if _, ok := admEndpoint("https://[2001:db8::10]"); ok {
+		t.Error("admEndpoint() found an instance which ADM no longer manages")
+	}
+}
+
+func TestADMProxiedScrape(t *testing.T) {
+	adm := admTestServer(t)
+
+	// The instance's credentials are not known to the exporter, so logging in to it directly would fail.
+	instance := nitrotest.NewServer()
+	defer instance.Close()
+
+	instance.Username = "nsroot"
+	instance.Password = "unknown"
+
+	instance.SetFixture("config/nspartition", []byte(`{"errorcode":0,"message":"Done","nspartition":[{"partitionname":"default"},{"partitionname":"tenant1"}]}`))
+	instance.SetFixture("stat/lbvserver", []byte(`{"errorcode":0,"message":"Done","lbvserver":[{"name":"vs_default","state":"UP"}]}`))
+	instance.SetFixture(nitrotest.PartitionKey("tenant1", "stat/lbvserver"), []byte(`{"errorcode":0,"message":"Done","lbvserver":[{"name":"vs_tenant","state":"UP"}]}`))
+
+	adm.AddManagedInstance("192.0.2.10", instance)
+
+	urls, err := listADMInstances(config.ADM{Target: config.Target{URL: adm.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(urls) != 1 || urls[0] != "https://192.0.2.10" {
+		t.Fatalf("listADMInstances() = %v, want https://192.0.2.10", urls)
+	}
+
+	exporter, err := newExporter(urls[0], config.ModuleADC, false, []string{"all"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(exporter)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	for _, mf := range families {
+		expfmt.MetricFamilyToText(&buf, mf)
+	}
+
+	got := buf.String()
+
+	for _, want := range []string{
+		`citrix_netscaler_up{ns_instance="192.0.2.10"} 1`,
+		`virtual_servers_state{ns_instance="192.0.2.10",partition="default",td="",virtual_server="vs_default"} 1`,
+		`virtual_servers_state{ns_instance="192.0.2.10",partition="tenant1",td="0",virtual_server="vs_tenant"} 1`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("metrics are missing %s", want)
+		}
+	}
+
+	if n := instance.Requests("config/login"); n != 0 {
+		t.Errorf("logged in to the instance %d times, want 0", n)
+	}
+
+	// The session with ADM is switched into tenant1 on the instance, and back to default.
+	key := nitrotest.Key("config/nspartition", "action=Switch")
+	if n := instance.Requests(key); n != 3 {
+		t.Errorf("Requests(%q) = %d, want 3", key, n)
+	}
+}
@@ -9,7 +9,7 @@ import (
 )
 
 var (
-	aaaAuthSuccess = prometheus.NewCounterVec(
+	aaaAuthSuccess = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "aaa_auth_success",
 			Help: "Count of authentication successes",
@@ -19,7 +19,7 @@ var (
 		},
 	)
 
-	aaaAuthFail = prometheus.NewCounterVec(
+	aaaAuthFail = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "aaa_auth_fail",
 			Help: "Count of authentication failures",
@@ -29,7 +29,7 @@ var (
 		},
 	)
 
-	aaaAuthOnlyHTTPSuccess = prometheus.NewCounterVec(
+	aaaAuthOnlyHTTPSuccess = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "aaa_auth_only_http_success",
 			Help: "Count of HTTP connections that succeeded authorisation",
@@ -39,7 +39,7 @@ var (
 		},
 	)
 
-	aaaAuthOnlyHTTPFail = prometheus.NewCounterVec(
+	aaaAuthOnlyHTTPFail = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "aaa_auth_only_http_fail",
 			Help: "Count of HTTP connections that failed authorisation",
@@ -49,7 +49,7 @@ var (
 		},
 	)
 
-	aaaCurIcaSessions = prometheus.NewCounterVec(
+	aaaCurIcaSessions = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "aaa_current_ica_sessions",
 			Help: "Count of current Basic ICA only sessions",
@@ -59,7 +59,7 @@ var (
 		},
 	)
 
-	aaaCurIcaOnlyConn = prometheus.NewCounterVec(
+	aaaCurIcaOnlyConn = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "aaa_current_ica_only_connections",
 			Help: "Count of current Basic ICA only connections",
@@ -68,46 +68,63 @@ var (
 			"ns_instance",
 		},
 	)
+
+	aaaCurIcaConn = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "aaa_current_ica_connections",
+			Help: "Count of current ICA connections, including those proxied through SmartAccess sessions",
+		},
+		[]string{
+			"ns_instance",
+		},
+	)
 )
 
-func (e *Exporter) collectAaaAuthSuccess(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectAaaAuthSuccess(stats netscaler.AAAStats) {
 	e.aaaAuthSuccess.Reset()
 
-	val, _ := strconv.ParseFloat(ns.AAAStats.AuthSuccess, 64)
+	val, _ := strconv.ParseFloat(stats.AuthSuccess, 64)
 	e.aaaAuthSuccess.WithLabelValues(e.nsInstance).Set(val)
 }
 
-func (e *Exporter) collectAaaAuthFail(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectAaaAuthFail(stats netscaler.AAAStats) {
 	e.aaaAuthFail.Reset()
 
-	val, _ := strconv.ParseFloat(ns.AAAStats.AuthFail, 64)
+	val, _ := strconv.ParseFloat(stats.AuthFail, 64)
 	e.aaaAuthFail.WithLabelValues(e.nsInstance).Set(val)
 }
 
-func (e *Exporter) collectAaaAuthOnlyHTTPSuccess(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectAaaAuthOnlyHTTPSuccess(stats netscaler.AAAStats) {
 	e.aaaAuthOnlyHTTPSuccess.Reset()
 
-	val, _ := strconv.ParseFloat(ns.AAAStats.AuthOnlyHTTPSuccess, 64)
+	val, _ := strconv.ParseFloat(stats.AuthOnlyHTTPSuccess, 64)
 	e.aaaAuthOnlyHTTPSuccess.WithLabelValues(e.nsInstance).Set(val)
 }
 
-func (e *Exporter) collectAaaAuthOnlyHTTPFail(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectAaaAuthOnlyHTTPFail(stats netscaler.AAAStats) {
 	e.aaaAuthOnlyHTTPFail.Reset()
 
-	val, _ := strconv.ParseFloat(ns.AAAStats.AuthOnlyHTTPFail, 64)
+	val, _ := strconv.ParseFloat(stats.AuthOnlyHTTPFail, 64)
 	e.aaaAuthOnlyHTTPFail.WithLabelValues(e.nsInstance).Set(val)
 }
 
-func (e *Exporter) collectAaaCurIcaSessions(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectAaaCurIcaSessions(stats netscaler.AAAStats) {
 	e.aaaCurIcaSessions.Reset()
 
-	val, _ := strconv.ParseFloat(ns.AAAStats.CurrentIcaSessions, 64)
+	val, _ := strconv.ParseFloat(stats.CurrentIcaSessions, 64)
 	e.aaaCurIcaSessions.WithLabelValues(e.nsInstance).Set(val)
 }
 
-func (e *Exporter) collectAaaCurIcaOnlyConn(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectAaaCurIcaOnlyConn(stats netscaler.AAAStats) {
 	e.aaaCurIcaOnlyConn.Reset()
 
-	val, _ := strconv.ParseFloat(ns.AAAStats.CurrentIcaOnlyConnections, 64)
+	val, _ := strconv.ParseFloat(stats.CurrentIcaOnlyConnections, 64)
 	e.aaaCurIcaOnlyConn.WithLabelValues(e.nsInstance).Set(val)
 }
+
+func (e *Exporter) collectAaaCurIcaConn(stats netscaler.AAAStats) {
+	e.aaaCurIcaConn.Reset()
+
+	val, _ := strconv.ParseFloat(stats.CurrentIcaConnections, 64)
+	e.aaaCurIcaConn.WithLabelValues(e.nsInstance).Set(val)
+}
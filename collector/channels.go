@@ -0,0 +1,101 @@
+package collector
+
+import (
+	"strconv"
+
+	"github.com/rokett/citrix-netscaler-exporter/netscaler"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	channelsLinkState = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "channels_link_state",
+			Help: "Link state of the link aggregation channel; 1 if the link is up",
+		},
+		[]string{
+			"ns_instance",
+			"channel",
+			"alias",
+		},
+	)
+
+	channelsSpeed = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "channels_speed_mbps",
+			Help: "Actual speed of the link aggregation channel in Mbps",
+		},
+		[]string{
+			"ns_instance",
+			"channel",
+			"alias",
+		},
+	)
+
+	channelsMembers = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "channels_members",
+			Help: "Number of interfaces bound to the link aggregation channel",
+		},
+		[]string{
+			"ns_instance",
+			"channel",
+			"alias",
+		},
+	)
+
+	channelsMember = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "channels_member_info",
+			Help: "Interface bound to the link aggregation channel; always 1",
+		},
+		[]string{
+			"ns_instance",
+			"channel",
+			"alias",
+			"interface",
+		},
+	)
+)
+
+func (e *Exporter) collectChannelsLinkState(channels []netscaler.ChannelStats) {
+	e.channelsLinkState.Reset()
+
+	for _, channel := range channels {
+		state := 0.0
+
+		if channel.LinkState == "UP" {
+			state = 1.0
+		}
+
+		e.channelsLinkState.WithLabelValues(e.nsInstance, channel.ID, channel.Alias).Set(state)
+	}
+}
+
+func (e *Exporter) collectChannelsSpeed(channels []netscaler.Channel) {
+	e.channelsSpeed.Reset()
+
+	for _, channel := range channels {
+		val, _ := strconv.ParseFloat(channel.ActualSpeed, 64)
+		e.channelsSpeed.WithLabelValues(e.nsInstance, channel.ID, channel.Alias).Set(val)
+	}
+}
+
+func (e *Exporter) collectChannelsMembers(channels []netscaler.Channel) {
+	e.channelsMembers.Reset()
+
+	for _, channel := range channels {
+		e.channelsMembers.WithLabelValues(e.nsInstance, channel.ID, channel.Alias).Set(float64(len(channel.Members)))
+	}
+}
+
+func (e *Exporter) collectChannelsMember(channels []netscaler.Channel) {
+	e.channelsMember.Reset()
+
+	for _, channel := range channels {
+		for _, member := range channel.Members {
+			e.channelsMember.WithLabelValues(e.nsInstance, channel.ID, channel.Alias, member).Set(1)
+		}
+	}
+}
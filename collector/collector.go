@@ -2,7 +2,7 @@ package collector
 
 import (
 	"strconv"
-	"strings"
+	"time"
 
 	"github.com/rokett/citrix-netscaler-exporter/netscaler"
 
@@ -15,101 +15,191 @@ func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
 	nsClient, err := netscaler.NewNitroClient(e.url, e.username, e.password, e.ignoreCert)
 	if err != nil {
 		level.Error(e.logger).Log("msg", err)
+		e.recordConnectError(err)
+		e.collectTargetStatus(ch, false)
 		return
 	}
 	defer nsClient.CloseIdleConnection()
 
+	// Instances managed by NetScaler ADM are collected through ADM, which the exporter logs in to instead.
+	if e.proxyInstance != "" {
+		nsClient.ProxyThroughADM(e.proxyInstance)
+	}
+
+	// A target which is down is reported via the up metric, rather than failing the scrape, so that alerting can tell the NetScaler being down apart from the exporter being broken.
 	err = netscaler.Connect(nsClient)
 	if err != nil {
 		level.Error(e.logger).Log("msg", err)
-		ch <- prometheus.NewInvalidMetric(prometheus.NewDesc("citrix_netscaler_exporter_error", "Error scraping target", nil, nil), err)
+		e.recordConnectError(err)
+		e.collectTargetStatus(ch, false)
 		return
 	}
 
-	nslicense, err := netscaler.GetNSLicense(nsClient, "")
+	e.collectTargetStatus(ch, true)
+
+	nslicense, err := netscaler.GetNSLicense(nsClient, netscaler.Query{})
 	if err != nil {
-		level.Error(e.logger).Log("msg", err)
+		e.logAPIError(err)
 	}
 
-	ns, err := netscaler.GetNSStats(nsClient, "")
+	ns, err := netscaler.GetNSStats(nsClient, netscaler.Query{})
 	if err != nil {
-		level.Error(e.logger).Log("msg", err)
+		e.logAPIError(err)
 	}
 
-	interfaces, err := netscaler.GetInterfaceStats(nsClient, "")
+	interfaces, err := netscaler.GetInterfaceStats(nsClient, netscaler.Query{})
 	if err != nil {
-		level.Error(e.logger).Log("msg", err)
+		e.logAPIError(err)
 	}
 
-	virtualServers, err := netscaler.GetVirtualServerStats(nsClient, "")
+	interfaceConfig, err := netscaler.GetInterfaces(nsClient, netscaler.Query{})
 	if err != nil {
-		level.Error(e.logger).Log("msg", err)
+		e.logAPIError(err)
 	}
 
-	services, err := netscaler.GetServiceStats(nsClient, "")
+	channels, err := netscaler.GetChannels(nsClient, netscaler.Query{})
 	if err != nil {
-		level.Error(e.logger).Log("msg", err)
+		e.logAPIError(err)
 	}
 
-	gslbServices, err := netscaler.GetGSLBServiceStats(nsClient, "")
+	channelStats, err := netscaler.GetChannelStats(nsClient, netscaler.Query{})
 	if err != nil {
-		level.Error(e.logger).Log("msg", err)
+		e.logAPIError(err)
 	}
 
-	gslbVirtualServers, err := netscaler.GetGSLBVirtualServerStats(nsClient, "")
+	vlanInterfaceBindings, err := netscaler.GetVLANInterfaceBindings(nsClient)
 	if err != nil {
-		level.Error(e.logger).Log("msg", err)
+		e.logAPIError(err)
 	}
 
-	csVirtualServers, err := netscaler.GetCSVirtualServerStats(nsClient, "")
+	gslbServices, err := netscaler.GetGSLBServiceStats(nsClient, netscaler.Query{})
 	if err != nil {
-		level.Error(e.logger).Log("msg", err)
+		e.logAPIError(err)
 	}
 
-	vpnVirtualServers, err := netscaler.GetVPNVirtualServerStats(nsClient, "")
+	gslbVirtualServers, err := netscaler.GetGSLBVirtualServerStats(nsClient, netscaler.Query{})
 	if err != nil {
-		level.Error(e.logger).Log("msg", err)
+		e.logAPIError(err)
 	}
 
-	aaa, err := netscaler.GetAAAStats(nsClient, "")
+	csVirtualServers, err := netscaler.GetCSVirtualServerStats(nsClient, netscaler.Query{})
 	if err != nil {
-		level.Error(e.logger).Log("msg", err)
+		e.logAPIError(err)
+	}
+
+	vpnVirtualServers, err := netscaler.GetVPNVirtualServerStats(nsClient, netscaler.Query{})
+	if err != nil {
+		e.logAPIError(err)
+	}
+
+	aaa, err := netscaler.GetAAAStats(nsClient, netscaler.Query{})
+	if err != nil {
+		e.logAPIError(err)
+	}
+
+	responderPolicies, err := netscaler.GetResponderPolicyStats(nsClient, netscaler.Query{})
+	if err != nil {
+		e.logAPIError(err)
+	}
+
+	rewritePolicies, err := netscaler.GetRewritePolicyStats(nsClient, netscaler.Query{})
+	if err != nil {
+		e.logAPIError(err)
+	}
+
+	csPolicies, err := netscaler.GetCSPolicyStats(nsClient, netscaler.Query{})
+	if err != nil {
+		e.logAPIError(err)
+	}
+
+	authenticationPolicies, err := netscaler.GetAuthenticationPolicyStats(nsClient, netscaler.Query{})
+	if err != nil {
+		e.logAPIError(err)
+	}
+
+	vpn, err := netscaler.GetVPNStats(nsClient, netscaler.Query{})
+	if err != nil {
+		e.logAPIError(err)
 	}
 
-	fltModelID, _ := strconv.ParseFloat(nslicense.NSLicense.ModelID, 64)
+	capacity, err := netscaler.GetNSCapacity(nsClient, netscaler.Query{})
+	if err != nil {
+		e.logAPIError(err)
+	}
+
+	nsversion, err := netscaler.GetNSVersion(nsClient, netscaler.Query{})
+	if err != nil {
+		e.logAPIError(err)
+	}
+
+	nshardware, err := netscaler.GetNSHardware(nsClient, netscaler.Query{})
+	if err != nil {
+		e.logAPIError(err)
+	}
+
+	nshostname, err := netscaler.GetNSHostname(nsClient, netscaler.Query{})
+	if err != nil {
+		e.logAPIError(err)
+	}
+
+	fltModelID, _ := strconv.ParseFloat(nslicense.ModelID, 64)
+
+	fltTotRxMB, _ := strconv.ParseFloat(ns.TotalReceivedMB, 64)
+	fltTotTxMB, _ := strconv.ParseFloat(ns.TotalTransmitMB, 64)
+	fltHTTPRequests, _ := strconv.ParseFloat(ns.HTTPRequests, 64)
+	fltHTTPResponses, _ := strconv.ParseFloat(ns.HTTPResponses, 64)
+
+	fltTCPCurrentClientConnections, _ := strconv.ParseFloat(ns.TCPCurrentClientConnections, 64)
+	fltTCPCurrentClientConnectionsEstablished, _ := strconv.ParseFloat(ns.TCPCurrentClientConnectionsEstablished, 64)
+	fltTCPCurrentServerConnections, _ := strconv.ParseFloat(ns.TCPCurrentServerConnections, 64)
+	fltTCPCurrentServerConnectionsEstablished, _ := strconv.ParseFloat(ns.TCPCurrentServerConnectionsEstablished, 64)
+
+	version, build := parseNSVersion(nsversion.Version)
+
+	hostname := ""
+	if len(nshostname) > 0 {
+		hostname = nshostname[0].Hostname
+	}
 
-	fltTotRxMB, _ := strconv.ParseFloat(ns.NSStats.TotalReceivedMB, 64)
-	fltTotTxMB, _ := strconv.ParseFloat(ns.NSStats.TotalTransmitMB, 64)
-	fltHTTPRequests, _ := strconv.ParseFloat(ns.NSStats.HTTPRequests, 64)
-	fltHTTPResponses, _ := strconv.ParseFloat(ns.NSStats.HTTPResponses, 64)
+	ch <- prometheus.MustNewConstMetric(
+		nsVersionInfo, prometheus.GaugeValue, 1, e.nsInstance, version, build, nshardware.Description, nshardware.SerialNumber, nshardware.HostID, hostname,
+	)
 
-	fltTCPCurrentClientConnections, _ := strconv.ParseFloat(ns.NSStats.TCPCurrentClientConnections, 64)
-	fltTCPCurrentClientConnectionsEstablished, _ := strconv.ParseFloat(ns.NSStats.TCPCurrentClientConnectionsEstablished, 64)
-	fltTCPCurrentServerConnections, _ := strconv.ParseFloat(ns.NSStats.TCPCurrentServerConnections, 64)
-	fltTCPCurrentServerConnectionsEstablished, _ := strconv.ParseFloat(ns.NSStats.TCPCurrentServerConnectionsEstablished, 64)
+	startTime, err := parseNSStartTime(ns.StartTime)
+	if err != nil {
+		level.Error(e.logger).Log("msg", "error parsing NetScaler start time", "starttime", ns.StartTime, "err", err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(
+			bootTime, prometheus.GaugeValue, float64(startTime.Unix()), e.nsInstance,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			uptime, prometheus.GaugeValue, time.Since(startTime).Seconds(), e.nsInstance,
+		)
+	}
 
 	ch <- prometheus.MustNewConstMetric(
 		modelID, prometheus.GaugeValue, fltModelID, e.nsInstance,
 	)
 
 	ch <- prometheus.MustNewConstMetric(
-		mgmtCPUUsage, prometheus.GaugeValue, ns.NSStats.MgmtCPUUsagePcnt, e.nsInstance,
+		mgmtCPUUsage, prometheus.GaugeValue, ns.MgmtCPUUsagePcnt, e.nsInstance,
 	)
 
 	ch <- prometheus.MustNewConstMetric(
-		memUsage, prometheus.GaugeValue, ns.NSStats.MemUsagePcnt, e.nsInstance,
+		memUsage, prometheus.GaugeValue, ns.MemUsagePcnt, e.nsInstance,
 	)
 
 	ch <- prometheus.MustNewConstMetric(
-		pktCPUUsage, prometheus.GaugeValue, ns.NSStats.PktCPUUsagePcnt, e.nsInstance,
+		pktCPUUsage, prometheus.GaugeValue, ns.PktCPUUsagePcnt, e.nsInstance,
 	)
 
 	ch <- prometheus.MustNewConstMetric(
-		flashPartitionUsage, prometheus.GaugeValue, ns.NSStats.FlashPartitionUsage, e.nsInstance,
+		flashPartitionUsage, prometheus.GaugeValue, ns.FlashPartitionUsage, e.nsInstance,
 	)
 
 	ch <- prometheus.MustNewConstMetric(
-		varPartitionUsage, prometheus.GaugeValue, ns.NSStats.VarPartitionUsage, e.nsInstance,
+		varPartitionUsage, prometheus.GaugeValue, ns.VarPartitionUsage, e.nsInstance,
 	)
 
 	ch <- prometheus.MustNewConstMetric(
@@ -165,89 +255,98 @@ func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
 	e.collectInterfacesErrorPacketsRx(interfaces)
 	e.interfacesErrorPacketsRx.Collect(ch)
 
-	e.collectVirtualServerState(virtualServers)
-	e.virtualServersState.Collect(ch)
+	e.collectInterfacesErrorPacketsTx(interfaces)
+	e.interfacesErrorPacketsTx.Collect(ch)
 
-	e.collectVirtualServerWaitingRequests(virtualServers)
-	e.virtualServersWaitingRequests.Collect(ch)
+	e.collectInterfacesInboundDiscards(interfaces)
+	e.interfacesInboundDiscards.Collect(ch)
 
-	e.collectVirtualServerHealth(virtualServers)
-	e.virtualServersHealth.Collect(ch)
+	e.collectInterfacesOutboundDiscards(interfaces)
+	e.interfacesOutboundDiscards.Collect(ch)
 
-	e.collectVirtualServerInactiveServices(virtualServers)
-	e.virtualServersInactiveServices.Collect(ch)
+	e.collectInterfacesDroppedPacketsRx(interfaces)
+	e.interfacesDroppedPacketsRx.Collect(ch)
 
-	e.collectVirtualServerActiveServices(virtualServers)
-	e.virtualServersActiveServices.Collect(ch)
+	e.collectInterfacesDroppedPacketsTx(interfaces)
+	e.interfacesDroppedPacketsTx.Collect(ch)
 
-	e.collectVirtualServerTotalHits(virtualServers)
-	e.virtualServersTotalHits.Collect(ch)
+	e.collectInterfacesCRCErrorsRx(interfaces)
+	e.interfacesCRCErrorsRx.Collect(ch)
 
-	e.collectVirtualServerTotalRequests(virtualServers)
-	e.virtualServersTotalRequests.Collect(ch)
+	e.collectInterfacesLinkHangs(interfaces)
+	e.interfacesLinkHangs.Collect(ch)
 
-	e.collectVirtualServerTotalResponses(virtualServers)
-	e.virtualServersTotalResponses.Collect(ch)
+	e.collectInterfacesLinkReinitialisations(interfaces)
+	e.interfacesLinkReinitialisations.Collect(ch)
 
-	e.collectVirtualServerTotalRequestBytes(virtualServers)
-	e.virtualServersTotalRequestBytes.Collect(ch)
+	e.collectInterfacesErrorDisables(interfaces)
+	e.interfacesErrorDisables.Collect(ch)
 
-	e.collectVirtualServerTotalResponseBytes(virtualServers)
-	e.virtualServersTotalResponseBytes.Collect(ch)
+	e.collectInterfacesTxStalls(interfaces)
+	e.interfacesTxStalls.Collect(ch)
 
-	e.collectVirtualServerCurrentClientConnections(virtualServers)
-	e.virtualServersCurrentClientConnections.Collect(ch)
+	e.collectInterfacesRxStalls(interfaces)
+	e.interfacesRxStalls.Collect(ch)
 
-	e.collectVirtualServerCurrentServerConnections(virtualServers)
-	e.virtualServersCurrentServerConnections.Collect(ch)
+	e.collectInterfacesLinkDowns(interfaces)
+	e.interfacesLinkDowns.Collect(ch)
 
-	e.collectServicesThroughput(services)
-	e.servicesThroughput.Collect(ch)
+	e.collectInterfacesBandwidthLimitDrops(interfaces)
+	e.interfacesBandwidthLimitDrops.Collect(ch)
 
-	e.collectServicesAvgTTFB(services)
-	e.servicesAvgTTFB.Collect(ch)
+	e.collectInterfacesRxBytesRate(interfaces)
+	e.interfacesRxBytesRate.Collect(ch)
 
-	e.collectServicesState(services)
-	e.servicesState.Collect(ch)
+	e.collectInterfacesTxBytesRate(interfaces)
+	e.interfacesTxBytesRate.Collect(ch)
 
-	e.collectServicesTotalRequests(services)
-	e.servicesTotalRequests.Collect(ch)
+	e.collectInterfacesLinkState(interfaceConfig)
+	e.interfacesLinkState.Collect(ch)
 
-	e.collectServicesTotalResponses(services)
-	e.servicesTotalResponses.Collect(ch)
+	e.collectInterfacesSpeed(interfaceConfig)
+	e.interfacesSpeed.Collect(ch)
 
-	e.collectServicesTotalRequestBytes(services)
-	e.servicesTotalRequestBytes.Collect(ch)
+	e.collectInterfacesFullDuplex(interfaceConfig)
+	e.interfacesFullDuplex.Collect(ch)
 
-	e.collectServicesTotalResponseBytes(services)
-	e.servicesTotalResponseBytes.Collect(ch)
+	e.collectInterfacesMTU(interfaceConfig)
+	e.interfacesMTU.Collect(ch)
 
-	e.collectServicesCurrentClientConns(services)
-	e.servicesCurrentClientConns.Collect(ch)
+	e.collectInterfacesLACPActorInSync(interfaceConfig)
+	e.interfacesLACPActorInSync.Collect(ch)
 
-	e.collectServicesSurgeCount(services)
-	e.servicesSurgeCount.Collect(ch)
+	e.collectInterfacesLACPActorCollecting(interfaceConfig)
+	e.interfacesLACPActorCollecting.Collect(ch)
 
-	e.collectServicesCurrentServerConns(services)
-	e.servicesCurrentServerConns.Collect(ch)
+	e.collectInterfacesLACPActorDistributing(interfaceConfig)
+	e.interfacesLACPActorDistributing.Collect(ch)
 
-	e.collectServicesServerEstablishedConnections(services)
-	e.servicesServerEstablishedConnections.Collect(ch)
+	e.collectInterfacesLACPPartnerInSync(interfaceConfig)
+	e.interfacesLACPPartnerInSync.Collect(ch)
 
-	e.collectServicesCurrentReusePool(services)
-	e.servicesCurrentReusePool.Collect(ch)
+	e.collectInterfacesLACPPartnerCollecting(interfaceConfig)
+	e.interfacesLACPPartnerCollecting.Collect(ch)
 
-	e.collectServicesMaxClients(services)
-	e.servicesMaxClients.Collect(ch)
+	e.collectInterfacesLACPPartnerDistributing(interfaceConfig)
+	e.interfacesLACPPartnerDistributing.Collect(ch)
 
-	e.collectServicesCurrentLoad(services)
-	e.servicesCurrentLoad.Collect(ch)
+	e.collectInterfacesChannel(interfaceConfig, channels)
+	e.interfacesChannel.Collect(ch)
 
-	e.collectServicesVirtualServerServiceHits(services)
-	e.servicesVirtualServerServiceHits.Collect(ch)
+	e.collectChannelsLinkState(channelStats)
+	e.channelsLinkState.Collect(ch)
 
-	e.collectServicesActiveTransactions(services)
-	e.servicesActiveTransactions.Collect(ch)
+	e.collectChannelsSpeed(channels)
+	e.channelsSpeed.Collect(ch)
+
+	e.collectChannelsMembers(channels)
+	e.channelsMembers.Collect(ch)
+
+	e.collectChannelsMember(channels)
+	e.channelsMember.Collect(ch)
+
+	e.collectVLANsInterfaceBinding(vlanInterfaceBindings)
+	e.vlansInterfaceBinding.Collect(ch)
 
 	e.collectGSLBServicesState(gslbServices)
 	e.gslbServicesState.Collect(ch)
@@ -381,6 +480,12 @@ func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
 	e.collectVPNVirtualServerState(vpnVirtualServers)
 	e.vpnVirtualServersState.Collect(ch)
 
+	e.collectVPNVirtualServerCurrentUsers(vpnVirtualServers)
+	e.vpnVirtualServersCurrentUsers.Collect(ch)
+
+	e.collectVPNVirtualServerCurrentSSLVPNUsers(vpnVirtualServers)
+	e.vpnVirtualServersCurrentSSLVPNUsers.Collect(ch)
+
 	e.collectAaaAuthSuccess(aaa)
 	e.aaaAuthSuccess.Collect(ch)
 
@@ -399,61 +504,331 @@ func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
 	e.collectAaaCurIcaOnlyConn(aaa)
 	e.aaaCurIcaOnlyConn.Collect(ch)
 
-	servicegroups, err := netscaler.GetServiceGroups(nsClient, "attrs=servicegroupname")
-	if err != nil {
-		level.Error(e.logger).Log("msg", err)
+	e.collectAaaCurIcaConn(aaa)
+	e.aaaCurIcaConn.Collect(ch)
+
+	e.collectResponderPoliciesHits(responderPolicies)
+	e.responderPoliciesHits.Collect(ch)
+
+	e.collectResponderPoliciesUndefinedHits(responderPolicies)
+	e.responderPoliciesUndefinedHits.Collect(ch)
+
+	e.collectRewritePoliciesHits(rewritePolicies)
+	e.rewritePoliciesHits.Collect(ch)
+
+	e.collectRewritePoliciesUndefinedHits(rewritePolicies)
+	e.rewritePoliciesUndefinedHits.Collect(ch)
+
+	e.collectCSPoliciesHits(csPolicies)
+	e.csPoliciesHits.Collect(ch)
+
+	e.collectAuthenticationPoliciesHits(authenticationPolicies)
+	e.authenticationPoliciesHits.Collect(ch)
+
+	e.collectAuthenticationPoliciesUndefinedHits(authenticationPolicies)
+	e.authenticationPoliciesUndefinedHits.Collect(ch)
+
+	e.collectVPNLoginFailures(vpn, aaa)
+	e.vpnLoginFailures.Collect(ch)
+
+	e.collectVPNClientSecurityCheckRequests(vpn)
+	e.vpnClientSecurityCheckRequests.Collect(ch)
+
+	e.collectVPNClientSecurityCheckSuccesses(vpn)
+	e.vpnClientSecurityCheckSuccesses.Collect(ch)
+
+	e.collectVPNSTAConnectionSuccesses(vpn)
+	e.vpnSTAConnectionSuccesses.Collect(ch)
+
+	e.collectVPNSTAConnectionFailures(vpn)
+	e.vpnSTAConnectionFailures.Collect(ch)
+
+	e.collectVPNSTATicketValidationsNotStarted(vpn)
+	e.vpnSTATicketValidationsNotStarted.Collect(ch)
+
+	if e.vpnSessions {
+		aaaSessions, icaConnections, total := e.getVPNSessions(nsClient)
+
+		sessions, notExported := e.vpnSessionList(aaaSessions, icaConnections, total, vpnVirtualServers)
+
+		e.collectVPNSessionInfo(sessions)
+		e.vpnSessionInfo.Collect(ch)
+
+		e.collectVPNSessionDuration(sessions)
+		e.vpnSessionDuration.Collect(ch)
+
+		e.collectVPNSessionsNotExported(notExported)
+		e.vpnSessionsNotExported.Collect(ch)
 	}
 
-	for _, sg := range servicegroups.ServiceGroups {
-		stats, err2 := netscaler.GetServiceGroupMemberStats(nsClient, sg.Name)
-		if err2 != nil {
-			level.Error(e.logger).Log("msg", err2)
+	e.collectLicenseFeatureEnabled(nslicense)
+	e.licenseFeatureEnabled.Collect(ch)
+
+	e.collectLicenseEdition(nslicense)
+	e.licenseEdition.Collect(ch)
+
+	e.collectLicenseDaysToExpiration(nslicense)
+	e.licenseDaysToExpiration.Collect(ch)
+
+	e.collectLicenseCapacityBandwidth(capacity)
+	e.licenseCapacityBandwidth.Collect(ch)
+
+	e.collectLicenseCapacityVCPUs(capacity)
+	e.licenseCapacityVCPUs.Collect(ch)
+
+	licenseExpiries := e.licenseFileExpiriesCached(nsClient)
+
+	e.collectLicenseFileExpiryTimestamp(licenseExpiries)
+	e.licenseFileExpiryTimestamp.Collect(ch)
+
+	e.collectLicenseFileDaysToExpiration(licenseExpiries)
+	e.licenseFileDaysToExpiration.Collect(ch)
+
+	if len(e.partitions) > 0 {
+		partitionStats, err := netscaler.GetNSPartitionStats(nsClient, netscaler.Query{})
+		if err != nil {
+			e.logAPIError(err)
 		}
 
-		for _, s := range stats.ServiceGroups[0].ServiceGroupMembers {
-			servicegroupnameParts := strings.Split(s.ServiceGroupName, "?")
+		e.collectPartitionBandwidth(partitionStats)
+		e.partitionBandwidth.Collect(ch)
+
+		e.collectPartitionMaxBandwidth(partitionStats)
+		e.partitionMaxBandwidth.Collect(ch)
 
-			e.collectServiceGroupsState(s, sg.Name, servicegroupnameParts[1])
-			e.serviceGroupsState.Collect(ch)
+		e.collectPartitionConnections(partitionStats)
+		e.partitionConnections.Collect(ch)
 
-			e.collectServiceGroupsAvgTTFB(s, sg.Name, servicegroupnameParts[1])
-			e.serviceGroupsAvgTTFB.Collect(ch)
+		e.collectPartitionMaxConnections(partitionStats)
+		e.partitionMaxConnections.Collect(ch)
+
+		e.collectPartitionMemoryUsage(partitionStats)
+		e.partitionMemoryUsage.Collect(ch)
+
+		e.collectPartitionMaxMemory(partitionStats)
+		e.partitionMaxMemory.Collect(ch)
+	}
+
+	if e.trafficDomains {
+		trafficDomains, err := netscaler.GetTrafficDomains(nsClient, netscaler.Query{})
+		if err != nil {
+			e.logAPIError(err)
+		}
+
+		e.collectTrafficDomainInfo(trafficDomains)
+		e.trafficDomainInfo.Collect(ch)
+
+		trafficDomainStats, err := netscaler.GetTrafficDomainStats(nsClient, netscaler.Query{})
+		if err != nil {
+			e.logAPIError(err)
+		}
 
-			e.collectServiceGroupsTotalRequests(s, sg.Name, servicegroupnameParts[1])
-			e.serviceGroupsTotalRequests.Collect(ch)
+		e.collectTrafficDomainReceivedPackets(trafficDomainStats)
+		e.trafficDomainReceivedPackets.Collect(ch)
 
-			e.collectServiceGroupsTotalResponses(s, sg.Name, servicegroupnameParts[1])
-			e.serviceGroupsTotalResponses.Collect(ch)
+		e.collectTrafficDomainReceivedPacketsRate(trafficDomainStats)
+		e.trafficDomainReceivedPacketsRate.Collect(ch)
 
-			e.collectServiceGroupsTotalRequestBytes(s, sg.Name, servicegroupnameParts[1])
-			e.serviceGroupsTotalRequestBytes.Collect(ch)
+		e.collectTrafficDomainTransmitPackets(trafficDomainStats)
+		e.trafficDomainTransmitPackets.Collect(ch)
 
-			e.collectServiceGroupsTotalResponseBytes(s, sg.Name, servicegroupnameParts[1])
-			e.serviceGroupsTotalResponseBytes.Collect(ch)
+		e.collectTrafficDomainTransmitPacketsRate(trafficDomainStats)
+		e.trafficDomainTransmitPacketsRate.Collect(ch)
 
-			e.collectServiceGroupsCurrentClientConnections(s, sg.Name, servicegroupnameParts[1])
-			e.serviceGroupsCurrentClientConnections.Collect(ch)
+		e.collectTrafficDomainDroppedPackets(trafficDomainStats)
+		e.trafficDomainDroppedPackets.Collect(ch)
 
-			e.collectServiceGroupsSurgeCount(s, sg.Name, servicegroupnameParts[1])
-			e.serviceGroupsSurgeCount.Collect(ch)
+		e.collectTrafficDomainDroppedPacketsRate(trafficDomainStats)
+		e.trafficDomainDroppedPacketsRate.Collect(ch)
+	}
 
-			e.collectServiceGroupsCurrentServerConnections(s, sg.Name, servicegroupnameParts[1])
-			e.serviceGroupsCurrentServerConnections.Collect(ch)
+	for _, partition := range e.partitionNames(nsClient) {
+		if partition != "" {
+			err = netscaler.SwitchPartition(nsClient, partition)
+			if err != nil {
+				e.logAPIError(err)
+				continue
+			}
+		}
 
-			e.collectServiceGroupsServerEstablishedConnections(s, sg.Name, servicegroupnameParts[1])
-			e.serviceGroupsServerEstablishedConnections.Collect(ch)
+		// Without partitions the session is not switched, so it stays in the default partition.
+		e.partition = partition
+		if e.partition == "" {
+			e.partition = "default"
+		}
 
-			e.collectServiceGroupsCurrentReusePool(s, sg.Name, servicegroupnameParts[1])
-			e.serviceGroupsCurrentReusePool.Collect(ch)
+		e.collectPartition(nsClient, ch)
+	}
 
-			e.collectServiceGroupsMaxClients(s, sg.Name, servicegroupnameParts[1])
-			e.serviceGroupsMaxClients.Collect(ch)
+	// Anything collected from here on belongs to the default partition.
+	if e.partition != "default" {
+		err = netscaler.SwitchPartition(nsClient, "default")
+		if err != nil {
+			e.logAPIError(err)
 		}
 	}
 
+	e.collectMappings(nsClient, ch)
+
+	e.collectAPIErrors(ch)
+
 	err = netscaler.Disconnect(nsClient)
 	if err != nil {
 		level.Error(e.logger).Log("msg", err)
 		return
 	}
 }
+
+// collectPartition collects the metrics for the virtual servers, services and service groups in the session's current partition.
+func (e *Exporter) collectPartition(nsClient *netscaler.NitroClient, ch chan<- prometheus.Metric) {
+	virtualServers, err := netscaler.GetVirtualServerStats(nsClient, netscaler.Query{})
+	if err != nil {
+		e.logAPIError(err)
+	}
+
+	services, err := netscaler.GetServiceStats(nsClient, netscaler.Query{})
+	if err != nil {
+		e.logAPIError(err)
+	}
+
+	members := e.serviceGroupMembers(nsClient)
+
+	servers, err := netscaler.GetServers(nsClient, netscaler.Query{Attrs: []string{"name", "ipaddress", "state", "td"}, PageSize: configPageSize})
+	if err != nil {
+		e.logAPIError(err)
+	}
+
+	// Traffic domains can only be configured in the default partition, so they are not looked up in any other.
+	if e.trafficDomains {
+		if e.partition == "default" {
+			e.setTrafficDomains(nsClient, virtualServers, services, members, servers)
+		} else {
+			setDefaultTrafficDomain(virtualServers, services, members)
+		}
+	}
+
+	e.collectServersState(servers)
+	e.serversState.Collect(ch)
+
+	e.collectVirtualServerState(virtualServers)
+	e.virtualServersState.Collect(ch)
+
+	e.collectVirtualServerWaitingRequests(virtualServers)
+	e.virtualServersWaitingRequests.Collect(ch)
+
+	e.collectVirtualServerHealth(virtualServers)
+	e.virtualServersHealth.Collect(ch)
+
+	e.collectVirtualServerInactiveServices(virtualServers)
+	e.virtualServersInactiveServices.Collect(ch)
+
+	e.collectVirtualServerActiveServices(virtualServers)
+	e.virtualServersActiveServices.Collect(ch)
+
+	e.collectVirtualServerTotalHits(virtualServers)
+	e.virtualServersTotalHits.Collect(ch)
+
+	e.collectVirtualServerTotalRequests(virtualServers)
+	e.virtualServersTotalRequests.Collect(ch)
+
+	e.collectVirtualServerTotalResponses(virtualServers)
+	e.virtualServersTotalResponses.Collect(ch)
+
+	e.collectVirtualServerTotalRequestBytes(virtualServers)
+	e.virtualServersTotalRequestBytes.Collect(ch)
+
+	e.collectVirtualServerTotalResponseBytes(virtualServers)
+	e.virtualServersTotalResponseBytes.Collect(ch)
+
+	e.collectVirtualServerCurrentClientConnections(virtualServers)
+	e.virtualServersCurrentClientConnections.Collect(ch)
+
+	e.collectVirtualServerCurrentServerConnections(virtualServers)
+	e.virtualServersCurrentServerConnections.Collect(ch)
+
+	e.collectServicesThroughput(services)
+	e.servicesThroughput.Collect(ch)
+
+	e.collectServicesAvgTTFB(services)
+	e.servicesAvgTTFB.Collect(ch)
+
+	e.collectServicesState(services)
+	e.servicesState.Collect(ch)
+
+	e.collectServicesTotalRequests(services)
+	e.servicesTotalRequests.Collect(ch)
+
+	e.collectServicesTotalResponses(services)
+	e.servicesTotalResponses.Collect(ch)
+
+	e.collectServicesTotalRequestBytes(services)
+	e.servicesTotalRequestBytes.Collect(ch)
+
+	e.collectServicesTotalResponseBytes(services)
+	e.servicesTotalResponseBytes.Collect(ch)
+
+	e.collectServicesCurrentClientConns(services)
+	e.servicesCurrentClientConns.Collect(ch)
+
+	e.collectServicesSurgeCount(services)
+	e.servicesSurgeCount.Collect(ch)
+
+	e.collectServicesCurrentServerConns(services)
+	e.servicesCurrentServerConns.Collect(ch)
+
+	e.collectServicesServerEstablishedConnections(services)
+	e.servicesServerEstablishedConnections.Collect(ch)
+
+	e.collectServicesCurrentReusePool(services)
+	e.servicesCurrentReusePool.Collect(ch)
+
+	e.collectServicesMaxClients(services)
+	e.servicesMaxClients.Collect(ch)
+
+	e.collectServicesCurrentLoad(services)
+	e.servicesCurrentLoad.Collect(ch)
+
+	e.collectServicesVirtualServerServiceHits(services)
+	e.servicesVirtualServerServiceHits.Collect(ch)
+
+	e.collectServicesActiveTransactions(services)
+	e.servicesActiveTransactions.Collect(ch)
+
+	for _, m := range members {
+		e.collectServiceGroupsState(m.stats, m.serviceGroup, m.server)
+		e.serviceGroupsState.Collect(ch)
+
+		e.collectServiceGroupsAvgTTFB(m.stats, m.serviceGroup, m.server)
+		e.serviceGroupsAvgTTFB.Collect(ch)
+
+		e.collectServiceGroupsTotalRequests(m.stats, m.serviceGroup, m.server)
+		e.serviceGroupsTotalRequests.Collect(ch)
+
+		e.collectServiceGroupsTotalResponses(m.stats, m.serviceGroup, m.server)
+		e.serviceGroupsTotalResponses.Collect(ch)
+
+		e.collectServiceGroupsTotalRequestBytes(m.stats, m.serviceGroup, m.server)
+		e.serviceGroupsTotalRequestBytes.Collect(ch)
+
+		e.collectServiceGroupsTotalResponseBytes(m.stats, m.serviceGroup, m.server)
+		e.serviceGroupsTotalResponseBytes.Collect(ch)
+
+		e.collectServiceGroupsCurrentClientConnections(m.stats, m.serviceGroup, m.server)
+		e.serviceGroupsCurrentClientConnections.Collect(ch)
+
+		e.collectServiceGroupsSurgeCount(m.stats, m.serviceGroup, m.server)
+		e.serviceGroupsSurgeCount.Collect(ch)
+
+		e.collectServiceGroupsCurrentServerConnections(m.stats, m.serviceGroup, m.server)
+		e.serviceGroupsCurrentServerConnections.Collect(ch)
+
+		e.collectServiceGroupsServerEstablishedConnections(m.stats, m.serviceGroup, m.server)
+		e.serviceGroupsServerEstablishedConnections.Collect(ch)
+
+		e.collectServiceGroupsCurrentReusePool(m.stats, m.serviceGroup, m.server)
+		e.serviceGroupsCurrentReusePool.Collect(ch)
+
+		e.collectServiceGroupsMaxClients(m.stats, m.serviceGroup, m.server)
+		e.serviceGroupsMaxClients.Collect(ch)
+	}
+}
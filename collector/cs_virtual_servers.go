@@ -9,7 +9,7 @@ import (
 )
 
 var (
-	csVirtualServersState = prometheus.NewGaugeVec(
+	csVirtualServersState = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "cs_virtual_servers_state",
 			Help: "Current state of the server",
@@ -20,7 +20,7 @@ var (
 		},
 	)
 
-	csVirtualServersTotalHits = prometheus.NewCounterVec(
+	csVirtualServersTotalHits = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "cs_virtual_servers_total_hits",
 			Help: "Total virtual server hits",
@@ -31,7 +31,7 @@ var (
 		},
 	)
 
-	csVirtualServersTotalRequests = prometheus.NewCounterVec(
+	csVirtualServersTotalRequests = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "cs_virtual_servers_total_requests",
 			Help: "Total virtual server requests",
@@ -42,7 +42,7 @@ var (
 		},
 	)
 
-	csVirtualServersTotalResponses = prometheus.NewCounterVec(
+	csVirtualServersTotalResponses = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "cs_virtual_servers_total_responses",
 			Help: "Total virtual server responses",
@@ -53,7 +53,7 @@ var (
 		},
 	)
 
-	csVirtualServersTotalRequestBytes = prometheus.NewCounterVec(
+	csVirtualServersTotalRequestBytes = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "cs_virtual_servers_total_request_bytes",
 			Help: "Total virtual server request bytes",
@@ -64,7 +64,7 @@ var (
 		},
 	)
 
-	csVirtualServersTotalResponseBytes = prometheus.NewCounterVec(
+	csVirtualServersTotalResponseBytes = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "cs_virtual_servers_total_response_bytes",
 			Help: "Total virtual server response bytes",
@@ -75,7 +75,7 @@ var (
 		},
 	)
 
-	csVirtualServersCurrentClientConnections = prometheus.NewGaugeVec(
+	csVirtualServersCurrentClientConnections = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "cs_virtual_servers_current_client_connections",
 			Help: "Number of current client connections on a specific virtual server",
@@ -86,7 +86,7 @@ var (
 		},
 	)
 
-	csVirtualServersCurrentServerConnections = prometheus.NewGaugeVec(
+	csVirtualServersCurrentServerConnections = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "cs_virtual_servers_current_server_connections",
 			Help: "Number of current connections to the actual servers behind the specific virtual server.",
@@ -97,7 +97,7 @@ var (
 		},
 	)
 
-	csVirtualServersEstablishedConnections = prometheus.NewGaugeVec(
+	csVirtualServersEstablishedConnections = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "cs_virtual_servers_established_connections",
 			Help: "Number of client connections in ESTABLISHED state.",
@@ -108,7 +108,7 @@ var (
 		},
 	)
 
-	csVirtualServersTotalPacketsReceived = prometheus.NewCounterVec(
+	csVirtualServersTotalPacketsReceived = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "cs_virtual_servers_total_packets_received",
 			Help: "Total number of packets received",
@@ -119,7 +119,7 @@ var (
 		},
 	)
 
-	csVirtualServersTotalPacketsSent = prometheus.NewCounterVec(
+	csVirtualServersTotalPacketsSent = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "cs_virtual_servers_total_packets_sent",
 			Help: "Total number of packets sent.",
@@ -130,7 +130,7 @@ var (
 		},
 	)
 
-	csVirtualServersTotalSpillovers = prometheus.NewCounterVec(
+	csVirtualServersTotalSpillovers = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "cs_virtual_servers_total_spillovers",
 			Help: "Number of times vserver experienced spill over.",
@@ -141,7 +141,7 @@ var (
 		},
 	)
 
-	csVirtualServersDeferredRequests = prometheus.NewCounterVec(
+	csVirtualServersDeferredRequests = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "cs_virtual_servers_deferred_requests",
 			Help: "Number of deferred request on this vserver",
@@ -152,7 +152,7 @@ var (
 		},
 	)
 
-	csVirtualServersNumberInvalidRequestResponse = prometheus.NewCounterVec(
+	csVirtualServersNumberInvalidRequestResponse = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "cs_virtual_servers_number_invalid_request_response",
 			Help: "Number invalid requests/responses on this vserver",
@@ -163,7 +163,7 @@ var (
 		},
 	)
 
-	csVirtualServersNumberInvalidRequestResponseDropped = prometheus.NewCounterVec(
+	csVirtualServersNumberInvalidRequestResponseDropped = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "cs_virtual_servers_number_invalid_request_response_dropped",
 			Help: "Number invalid requests/responses dropped on this vserver",
@@ -174,7 +174,7 @@ var (
 		},
 	)
 
-	csVirtualServersTotalVServerDownBackupHits = prometheus.NewCounterVec(
+	csVirtualServersTotalVServerDownBackupHits = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "cs_virtual_servers_total_vserver_down_backup_hits",
 			Help: "Number of times traffic was diverted to backup vserver since primary vserver was DOWN.",
@@ -185,7 +185,7 @@ var (
 		},
 	)
 
-	csVirtualServersCurrentMultipathSessions = prometheus.NewGaugeVec(
+	csVirtualServersCurrentMultipathSessions = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "cs_virtual_servers_current_multipath_sessions",
 			Help: "Current Multipath TCP sessions",
@@ -196,7 +196,7 @@ var (
 		},
 	)
 
-	csVirtualServersCurrentMultipathSubflows = prometheus.NewGaugeVec(
+	csVirtualServersCurrentMultipathSubflows = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "cs_virtual_servers_current_multipath_subflows",
 			Help: "Current Multipath TCP subflows",
@@ -208,10 +208,10 @@ var (
 	)
 )
 
-func (e *Exporter) collectCSVirtualServerState(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectCSVirtualServerState(virtualServers []netscaler.CSVirtualServerStats) {
 	e.csVirtualServersState.Reset()
 
-	for _, vs := range ns.CSVirtualServerStats {
+	for _, vs := range virtualServers {
 		state := 0.0
 
 		if vs.State == "UP" {
@@ -222,154 +222,154 @@ func (e *Exporter) collectCSVirtualServerState(ns netscaler.NSAPIResponse) {
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerTotalHits(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectCSVirtualServerTotalHits(virtualServers []netscaler.CSVirtualServerStats) {
 	e.csVirtualServersTotalHits.Reset()
 
-	for _, vs := range ns.CSVirtualServerStats {
+	for _, vs := range virtualServers {
 		totalHits, _ := strconv.ParseFloat(vs.TotalHits, 64)
 		e.csVirtualServersTotalHits.WithLabelValues(e.nsInstance, vs.Name).Set(totalHits)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerTotalRequests(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectCSVirtualServerTotalRequests(virtualServers []netscaler.CSVirtualServerStats) {
 	e.csVirtualServersTotalRequests.Reset()
 
-	for _, vs := range ns.CSVirtualServerStats {
+	for _, vs := range virtualServers {
 		totalRequests, _ := strconv.ParseFloat(vs.TotalRequests, 64)
 		e.csVirtualServersTotalRequests.WithLabelValues(e.nsInstance, vs.Name).Set(totalRequests)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerTotalResponses(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectCSVirtualServerTotalResponses(virtualServers []netscaler.CSVirtualServerStats) {
 	e.csVirtualServersTotalResponses.Reset()
 
-	for _, vs := range ns.CSVirtualServerStats {
+	for _, vs := range virtualServers {
 		totalResponses, _ := strconv.ParseFloat(vs.TotalResponses, 64)
 		e.csVirtualServersTotalResponses.WithLabelValues(e.nsInstance, vs.Name).Set(totalResponses)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerTotalRequestBytes(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectCSVirtualServerTotalRequestBytes(virtualServers []netscaler.CSVirtualServerStats) {
 	e.csVirtualServersTotalRequestBytes.Reset()
 
-	for _, vs := range ns.CSVirtualServerStats {
+	for _, vs := range virtualServers {
 		totalRequestBytes, _ := strconv.ParseFloat(vs.TotalRequestBytes, 64)
 		e.csVirtualServersTotalRequestBytes.WithLabelValues(e.nsInstance, vs.Name).Set(totalRequestBytes)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerTotalResponseBytes(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectCSVirtualServerTotalResponseBytes(virtualServers []netscaler.CSVirtualServerStats) {
 	e.csVirtualServersTotalResponseBytes.Reset()
 
-	for _, vs := range ns.CSVirtualServerStats {
+	for _, vs := range virtualServers {
 		totalResponseBytes, _ := strconv.ParseFloat(vs.TotalResponseBytes, 64)
 		e.csVirtualServersTotalResponseBytes.WithLabelValues(e.nsInstance, vs.Name).Set(totalResponseBytes)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerCurrentClientConnections(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectCSVirtualServerCurrentClientConnections(virtualServers []netscaler.CSVirtualServerStats) {
 	e.csVirtualServersCurrentClientConnections.Reset()
 
-	for _, vs := range ns.CSVirtualServerStats {
+	for _, vs := range virtualServers {
 		currentClientConnections, _ := strconv.ParseFloat(vs.CurrentClientConnections, 64)
 		e.csVirtualServersCurrentClientConnections.WithLabelValues(e.nsInstance, vs.Name).Set(currentClientConnections)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerCurrentServerConnections(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectCSVirtualServerCurrentServerConnections(virtualServers []netscaler.CSVirtualServerStats) {
 	e.csVirtualServersCurrentServerConnections.Reset()
 
-	for _, vs := range ns.CSVirtualServerStats {
+	for _, vs := range virtualServers {
 		currentServerConnections, _ := strconv.ParseFloat(vs.CurrentServerConnections, 64)
 		e.csVirtualServersCurrentServerConnections.WithLabelValues(e.nsInstance, vs.Name).Set(currentServerConnections)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerEstablishedConnections(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectCSVirtualServerEstablishedConnections(virtualServers []netscaler.CSVirtualServerStats) {
 	e.csVirtualServersEstablishedConnections.Reset()
 
-	for _, vs := range ns.CSVirtualServerStats {
+	for _, vs := range virtualServers {
 		EstablishedConnections, _ := strconv.ParseFloat(vs.EstablishedConnections, 64)
 		e.csVirtualServersEstablishedConnections.WithLabelValues(e.nsInstance, vs.Name).Set(EstablishedConnections)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerTotalPacketsReceived(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectCSVirtualServerTotalPacketsReceived(virtualServers []netscaler.CSVirtualServerStats) {
 	e.csVirtualServersTotalPacketsReceived.Reset()
 
-	for _, vs := range ns.CSVirtualServerStats {
+	for _, vs := range virtualServers {
 		totalPacketsReceived, _ := strconv.ParseFloat(vs.TotalPacketsReceived, 64)
 		e.csVirtualServersTotalPacketsReceived.WithLabelValues(e.nsInstance, vs.Name).Set(totalPacketsReceived)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerTotalPacketsSent(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectCSVirtualServerTotalPacketsSent(virtualServers []netscaler.CSVirtualServerStats) {
 	e.csVirtualServersTotalPacketsSent.Reset()
 
-	for _, vs := range ns.CSVirtualServerStats {
+	for _, vs := range virtualServers {
 		totalPacketsSent, _ := strconv.ParseFloat(vs.TotalPacketsSent, 64)
 		e.csVirtualServersTotalPacketsSent.WithLabelValues(e.nsInstance, vs.Name).Set(totalPacketsSent)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerTotalSpillovers(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectCSVirtualServerTotalSpillovers(virtualServers []netscaler.CSVirtualServerStats) {
 	e.csVirtualServersTotalSpillovers.Reset()
 
-	for _, vs := range ns.CSVirtualServerStats {
+	for _, vs := range virtualServers {
 		totalSpillovers, _ := strconv.ParseFloat(vs.TotalSpillovers, 64)
 		e.csVirtualServersTotalSpillovers.WithLabelValues(e.nsInstance, vs.Name).Set(totalSpillovers)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerDeferredRequests(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectCSVirtualServerDeferredRequests(virtualServers []netscaler.CSVirtualServerStats) {
 	e.csVirtualServersDeferredRequests.Reset()
 
-	for _, vs := range ns.CSVirtualServerStats {
+	for _, vs := range virtualServers {
 		deferredRequests, _ := strconv.ParseFloat(vs.DeferredRequests, 64)
 		e.csVirtualServersDeferredRequests.WithLabelValues(e.nsInstance, vs.Name).Set(deferredRequests)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerNumberInvalidRequestResponse(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectCSVirtualServerNumberInvalidRequestResponse(virtualServers []netscaler.CSVirtualServerStats) {
 	e.csVirtualServersNumberInvalidRequestResponse.Reset()
 
-	for _, vs := range ns.CSVirtualServerStats {
+	for _, vs := range virtualServers {
 		numberInvalidRequestResponse, _ := strconv.ParseFloat(vs.InvalidRequestResponse, 64)
 		e.csVirtualServersNumberInvalidRequestResponse.WithLabelValues(e.nsInstance, vs.Name).Set(numberInvalidRequestResponse)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerNumberInvalidRequestResponseDropped(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectCSVirtualServerNumberInvalidRequestResponseDropped(virtualServers []netscaler.CSVirtualServerStats) {
 	e.csVirtualServersNumberInvalidRequestResponseDropped.Reset()
 
-	for _, vs := range ns.CSVirtualServerStats {
+	for _, vs := range virtualServers {
 		numberInvalidRequestResponseDropped, _ := strconv.ParseFloat(vs.InvalidRequestResponseDropped, 64)
 		e.csVirtualServersNumberInvalidRequestResponseDropped.WithLabelValues(e.nsInstance, vs.Name).Set(numberInvalidRequestResponseDropped)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerTotalVServerDownBackupHits(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectCSVirtualServerTotalVServerDownBackupHits(virtualServers []netscaler.CSVirtualServerStats) {
 	e.csVirtualServersTotalVServerDownBackupHits.Reset()
 
-	for _, vs := range ns.CSVirtualServerStats {
+	for _, vs := range virtualServers {
 		totalVServerDownBackupHits, _ := strconv.ParseFloat(vs.TotalVServerDownBackupHits, 64)
 		e.csVirtualServersTotalVServerDownBackupHits.WithLabelValues(e.nsInstance, vs.Name).Set(totalVServerDownBackupHits)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerCurrentMultipathSessions(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectCSVirtualServerCurrentMultipathSessions(virtualServers []netscaler.CSVirtualServerStats) {
 	e.csVirtualServersCurrentMultipathSessions.Reset()
 
-	for _, vs := range ns.CSVirtualServerStats {
+	for _, vs := range virtualServers {
 		currentMultipathSessions, _ := strconv.ParseFloat(vs.CurrentMultipathSessions, 64)
 		e.csVirtualServersCurrentMultipathSessions.WithLabelValues(e.nsInstance, vs.Name).Set(currentMultipathSessions)
 	}
 }
 
-func (e *Exporter) collectCSVirtualServerCurrentMultipathSubflows(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectCSVirtualServerCurrentMultipathSubflows(virtualServers []netscaler.CSVirtualServerStats) {
 	e.csVirtualServersCurrentMultipathSubflows.Reset()
 
-	for _, vs := range ns.CSVirtualServerStats {
+	for _, vs := range virtualServers {
 		currentMultipathSubflows, _ := strconv.ParseFloat(vs.CurrentMultipathSubflows, 64)
 		e.csVirtualServersCurrentMultipathSubflows.WithLabelValues(e.nsInstance, vs.Name).Set(currentMultipathSubflows)
 	}
@@ -2,6 +2,9 @@ package collector
 
 import (
 	"errors"
+	"regexp"
+
+	"github.com/rokett/citrix-netscaler-exporter/config"
 
 	"github.com/go-kit/kit/log"
 
@@ -10,6 +13,10 @@ import (
 
 // Exporter represents the metrics exported to Prometheus
 type Exporter struct {
+	target
+	nsVersionInfo                                       *prometheus.Desc
+	bootTime                                            *prometheus.Desc
+	uptime                                              *prometheus.Desc
 	modelID                                             *prometheus.Desc
 	mgmtCPUUsage                                        *prometheus.Desc
 	memUsage                                            *prometheus.Desc
@@ -24,13 +31,44 @@ type Exporter struct {
 	tcpCurrentClientConnectionsEstablished              *prometheus.Desc
 	tcpCurrentServerConnections                         *prometheus.Desc
 	tcpCurrentServerConnectionsEstablished              *prometheus.Desc
-	interfacesRxBytes                                   *prometheus.GaugeVec
-	interfacesTxBytes                                   *prometheus.GaugeVec
-	interfacesRxPackets                                 *prometheus.GaugeVec
-	interfacesTxPackets                                 *prometheus.GaugeVec
-	interfacesJumboPacketsRx                            *prometheus.GaugeVec
-	interfacesJumboPacketsTx                            *prometheus.GaugeVec
-	interfacesErrorPacketsRx                            *prometheus.GaugeVec
+	interfacesRxBytes                                   *prometheus.CounterVec
+	interfacesTxBytes                                   *prometheus.CounterVec
+	interfacesRxPackets                                 *prometheus.CounterVec
+	interfacesTxPackets                                 *prometheus.CounterVec
+	interfacesJumboPacketsRx                            *prometheus.CounterVec
+	interfacesJumboPacketsTx                            *prometheus.CounterVec
+	interfacesErrorPacketsRx                            *prometheus.CounterVec
+	interfacesErrorPacketsTx                            *prometheus.CounterVec
+	interfacesInboundDiscards                           *prometheus.CounterVec
+	interfacesOutboundDiscards                          *prometheus.CounterVec
+	interfacesDroppedPacketsRx                          *prometheus.CounterVec
+	interfacesDroppedPacketsTx                          *prometheus.CounterVec
+	interfacesCRCErrorsRx                               *prometheus.CounterVec
+	interfacesLinkHangs                                 *prometheus.CounterVec
+	interfacesLinkReinitialisations                     *prometheus.CounterVec
+	interfacesErrorDisables                             *prometheus.CounterVec
+	interfacesTxStalls                                  *prometheus.CounterVec
+	interfacesRxStalls                                  *prometheus.CounterVec
+	interfacesLinkDowns                                 *prometheus.CounterVec
+	interfacesBandwidthLimitDrops                       *prometheus.CounterVec
+	interfacesRxBytesRate                               *prometheus.GaugeVec
+	interfacesTxBytesRate                               *prometheus.GaugeVec
+	interfacesLinkState                                 *prometheus.GaugeVec
+	interfacesSpeed                                     *prometheus.GaugeVec
+	interfacesFullDuplex                                *prometheus.GaugeVec
+	interfacesMTU                                       *prometheus.GaugeVec
+	interfacesLACPActorInSync                           *prometheus.GaugeVec
+	interfacesLACPActorCollecting                       *prometheus.GaugeVec
+	interfacesLACPActorDistributing                     *prometheus.GaugeVec
+	interfacesLACPPartnerInSync                         *prometheus.GaugeVec
+	interfacesLACPPartnerCollecting                     *prometheus.GaugeVec
+	interfacesLACPPartnerDistributing                   *prometheus.GaugeVec
+	interfacesChannel                                   *prometheus.GaugeVec
+	channelsLinkState                                   *prometheus.GaugeVec
+	channelsSpeed                                       *prometheus.GaugeVec
+	channelsMembers                                     *prometheus.GaugeVec
+	channelsMember                                      *prometheus.GaugeVec
+	vlansInterfaceBinding                               *prometheus.GaugeVec
 	virtualServersState                                 *prometheus.GaugeVec
 	virtualServersWaitingRequests                       *prometheus.GaugeVec
 	virtualServersHealth                                *prometheus.GaugeVec
@@ -115,22 +153,104 @@ type Exporter struct {
 	vpnVirtualServersTotalRequestBytes                  *prometheus.CounterVec
 	vpnVirtualServersTotalResponseBytes                 *prometheus.CounterVec
 	vpnVirtualServersState                              *prometheus.GaugeVec
+	vpnVirtualServersCurrentUsers                       *prometheus.GaugeVec
+	vpnVirtualServersCurrentSSLVPNUsers                 *prometheus.GaugeVec
 	aaaAuthSuccess                                      *prometheus.CounterVec
 	aaaAuthFail                                         *prometheus.CounterVec
 	aaaAuthOnlyHTTPSuccess                              *prometheus.CounterVec
 	aaaAuthOnlyHTTPFail                                 *prometheus.CounterVec
 	aaaCurIcaSessions                                   *prometheus.CounterVec
 	aaaCurIcaOnlyConn                                   *prometheus.CounterVec
+	aaaCurIcaConn                                       *prometheus.GaugeVec
+	responderPoliciesHits                               *prometheus.CounterVec
+	responderPoliciesUndefinedHits                      *prometheus.CounterVec
+	rewritePoliciesHits                                 *prometheus.CounterVec
+	rewritePoliciesUndefinedHits                        *prometheus.CounterVec
+	csPoliciesHits                                      *prometheus.CounterVec
+	authenticationPoliciesHits                          *prometheus.CounterVec
+	authenticationPoliciesUndefinedHits                 *prometheus.CounterVec
+	vpnLoginFailures                                    *prometheus.CounterVec
+	vpnClientSecurityCheckRequests                      *prometheus.CounterVec
+	vpnClientSecurityCheckSuccesses                     *prometheus.CounterVec
+	vpnSTAConnectionSuccesses                           *prometheus.CounterVec
+	vpnSTAConnectionFailures                            *prometheus.CounterVec
+	vpnSTATicketValidationsNotStarted                   *prometheus.CounterVec
+	vpnSessionInfo                                      *prometheus.GaugeVec
+	vpnSessionDuration                                  *prometheus.GaugeVec
+	vpnSessionsNotExported                              *prometheus.GaugeVec
+	licenseFeatureEnabled                               *prometheus.GaugeVec
+	licenseEdition                                      *prometheus.GaugeVec
+	licenseDaysToExpiration                             *prometheus.GaugeVec
+	licenseFileExpiryTimestamp                          *prometheus.GaugeVec
+	licenseFileDaysToExpiration                         *prometheus.GaugeVec
+	licenseCapacityBandwidth                            *prometheus.GaugeVec
+	licenseCapacityVCPUs                                *prometheus.GaugeVec
+	partitionBandwidth                                  *prometheus.GaugeVec
+	partitionMaxBandwidth                               *prometheus.GaugeVec
+	partitionConnections                                *prometheus.GaugeVec
+	partitionMaxConnections                             *prometheus.GaugeVec
+	partitionMemoryUsage                                *prometheus.GaugeVec
+	partitionMaxMemory                                  *prometheus.GaugeVec
+	trafficDomainInfo                                   *prometheus.GaugeVec
+	trafficDomainReceivedPackets                        *prometheus.CounterVec
+	trafficDomainReceivedPacketsRate                    *prometheus.GaugeVec
+	trafficDomainTransmitPackets                        *prometheus.CounterVec
+	trafficDomainTransmitPacketsRate                    *prometheus.GaugeVec
+	trafficDomainDroppedPackets                         *prometheus.CounterVec
+	trafficDomainDroppedPacketsRate                     *prometheus.GaugeVec
+	serversState                                        *prometheus.GaugeVec
 	username                                            string
 	password                                            string
 	url                                                 string
 	ignoreCert                                          bool
-	logger                                              log.Logger
-	nsInstance                                          string
+	proxyInstance                                       string
+	policyFilter                                        *regexp.Regexp
+	vpnSessions                                         bool
+	vpnSessionsMaxSeries                                int
+	serviceGroupBulk                                    bool
+	trafficDomains                                      bool
+	partitions                                          []string
+	partition                                           string
+	mappings                                            []config.Mapping
+	mappingDescs                                        [][]mappingDesc
+}
+
+// newGaugeVec returns a function which creates a GaugeVec with the given options and labels.
+// Each exporter creates its own vecs, as exporters for targets which are polled at the same time would otherwise reset and overwrite each other's metrics.
+func newGaugeVec(opts prometheus.GaugeOpts, labels []string) func() *prometheus.GaugeVec {
+	return func() *prometheus.GaugeVec {
+		return prometheus.NewGaugeVec(opts, labels)
+	}
+}
+
+// newCounterVec returns a function which creates a CounterVec with the given options and labels; see newGaugeVec.
+func newCounterVec(opts prometheus.CounterOpts, labels []string) func() *prometheus.CounterVec {
+	return func() *prometheus.CounterVec {
+		return prometheus.NewCounterVec(opts, labels)
+	}
+}
+
+// Options holds the optional settings of an exporter.  The zero value collects the standard metrics from the default partition.
+type Options struct {
+	// ProxyInstance is the IP address of the managed instance to collect from, when the URL is of NetScaler ADM rather than the NetScaler.
+	ProxyInstance string
+	// PolicyFilter limits the policies whose hit counters are exported; all are exported if it is nil.
+	PolicyFilter *regexp.Regexp
+	// VPNSessions exports each Gateway session and ICA connection, up to VPNSessionsMaxSeries of each.
+	VPNSessions          bool
+	VPNSessionsMaxSeries int
+	// ServiceGroupBulk fetches every service group member in a single request.
+	ServiceGroupBulk bool
+	// TrafficDomains labels virtual servers, services, service groups and servers with their traffic domain.
+	TrafficDomains bool
+	// Partitions are the admin partitions to collect from; "all" collects from every partition.
+	Partitions []string
+	// Mappings are the metrics declared in the configuration file.
+	Mappings []config.Mapping
 }
 
 // NewExporter initialises the exporter
-func NewExporter(url string, username string, password string, ignoreCert bool, logger log.Logger, nsInstance string) (*Exporter, error) {
+func NewExporter(url string, username string, password string, ignoreCert bool, logger log.Logger, nsInstance string, opts Options) (*Exporter, error) {
 	if url == "" {
 		return nil, errors.New("no Url Specified")
 	}
@@ -143,7 +263,14 @@ func NewExporter(url string, username string, password string, ignoreCert bool,
 		return nil, errors.New("no Password Specified")
 	}
 
+	if opts.VPNSessionsMaxSeries < 0 {
+		return nil, errors.New("VPNSessionsMaxSeries must not be negative")
+	}
+
 	return &Exporter{
+		nsVersionInfo:                                       nsVersionInfo,
+		bootTime:                                            bootTime,
+		uptime:                                              uptime,
 		modelID:                                             modelID,
 		mgmtCPUUsage:                                        mgmtCPUUsage,
 		memUsage:                                            memUsage,
@@ -158,114 +285,203 @@ func NewExporter(url string, username string, password string, ignoreCert bool,
 		tcpCurrentClientConnectionsEstablished:              tcpCurrentClientConnectionsEstablished,
 		tcpCurrentServerConnections:                         tcpCurrentServerConnections,
 		tcpCurrentServerConnectionsEstablished:              tcpCurrentServerConnectionsEstablished,
-		interfacesRxBytes:                                   interfacesRxBytes,
-		interfacesTxBytes:                                   interfacesTxBytes,
-		interfacesRxPackets:                                 interfacesRxPackets,
-		interfacesTxPackets:                                 interfacesTxPackets,
-		interfacesJumboPacketsRx:                            interfacesJumboPacketsRx,
-		interfacesJumboPacketsTx:                            interfacesJumboPacketsTx,
-		interfacesErrorPacketsRx:                            interfacesErrorPacketsRx,
-		virtualServersState:                                 virtualServersState,
-		virtualServersWaitingRequests:                       virtualServersWaitingRequests,
-		virtualServersHealth:                                virtualServersHealth,
-		virtualServersInactiveServices:                      virtualServersInactiveServices,
-		virtualServersActiveServices:                        virtualServersActiveServices,
-		virtualServersTotalHits:                             virtualServersTotalHits,
-		virtualServersTotalRequests:                         virtualServersTotalRequests,
-		virtualServersTotalResponses:                        virtualServersTotalResponses,
-		virtualServersTotalRequestBytes:                     virtualServersTotalRequestBytes,
-		virtualServersTotalResponseBytes:                    virtualServersTotalResponseBytes,
-		virtualServersCurrentClientConnections:              virtualServersCurrentClientConnections,
-		virtualServersCurrentServerConnections:              virtualServersCurrentServerConnections,
-		servicesThroughput:                                  servicesThroughput,
-		servicesAvgTTFB:                                     servicesAvgTTFB,
-		servicesState:                                       servicesState,
-		servicesTotalRequests:                               servicesTotalRequests,
-		servicesTotalResponses:                              servicesTotalResponses,
-		servicesTotalRequestBytes:                           servicesTotalRequestBytes,
-		servicesTotalResponseBytes:                          servicesTotalResponseBytes,
-		servicesCurrentClientConns:                          servicesCurrentClientConns,
-		servicesSurgeCount:                                  servicesSurgeCount,
-		servicesCurrentServerConns:                          servicesCurrentServerConns,
-		servicesServerEstablishedConnections:                servicesServerEstablishedConnections,
-		servicesCurrentReusePool:                            servicesCurrentReusePool,
-		servicesMaxClients:                                  servicesMaxClients,
-		servicesCurrentLoad:                                 servicesCurrentLoad,
-		servicesVirtualServerServiceHits:                    servicesVirtualServerServiceHits,
-		servicesActiveTransactions:                          servicesActiveTransactions,
-		serviceGroupsState:                                  serviceGroupsState,
-		serviceGroupsAvgTTFB:                                serviceGroupsAvgTTFB,
-		serviceGroupsTotalRequests:                          serviceGroupsTotalRequests,
-		serviceGroupsTotalResponses:                         serviceGroupsTotalResponses,
-		serviceGroupsTotalRequestBytes:                      serviceGroupsTotalRequestBytes,
-		serviceGroupsTotalResponseBytes:                     serviceGroupsTotalResponseBytes,
-		serviceGroupsCurrentClientConnections:               serviceGroupsCurrentClientConnections,
-		serviceGroupsSurgeCount:                             serviceGroupsSurgeCount,
-		serviceGroupsCurrentServerConnections:               serviceGroupsCurrentServerConnections,
-		serviceGroupsServerEstablishedConnections:           serviceGroupsServerEstablishedConnections,
-		serviceGroupsCurrentReusePool:                       serviceGroupsCurrentReusePool,
-		serviceGroupsMaxClients:                             serviceGroupsMaxClients,
-		gslbServicesState:                                   gslbServicesState,
-		gslbServicesTotalRequests:                           gslbServicesTotalRequests,
-		gslbServicesTotalResponses:                          gslbServicesTotalResponses,
-		gslbServicesTotalRequestBytes:                       gslbServicesTotalRequestBytes,
-		gslbServicesTotalResponseBytes:                      gslbServicesTotalResponseBytes,
-		gslbServicesCurrentClientConns:                      gslbServicesCurrentClientConns,
-		gslbServicesCurrentServerConns:                      gslbServicesCurrentServerConns,
-		gslbServicesCurrentLoad:                             gslbServicesCurrentLoad,
-		gslbServicesVirtualServerServiceHits:                gslbServicesVirtualServerServiceHits,
-		gslbServicesEstablishedConnections:                  gslbServicesEstablishedConnections,
-		gslbVirtualServersState:                             gslbVirtualServersState,
-		gslbVirtualServersHealth:                            gslbVirtualServersHealth,
-		gslbVirtualServersInactiveServices:                  gslbVirtualServersInactiveServices,
-		gslbVirtualServersActiveServices:                    gslbVirtualServersActiveServices,
-		gslbVirtualServersTotalHits:                         gslbVirtualServersTotalHits,
-		gslbVirtualServersTotalRequests:                     gslbVirtualServersTotalRequests,
-		gslbVirtualServersTotalResponses:                    gslbVirtualServersTotalResponses,
-		gslbVirtualServersTotalRequestBytes:                 gslbVirtualServersTotalRequestBytes,
-		gslbVirtualServersTotalResponseBytes:                gslbVirtualServersTotalResponseBytes,
-		gslbVirtualServersCurrentClientConnections:          gslbVirtualServersCurrentClientConnections,
-		gslbVirtualServersCurrentServerConnections:          gslbVirtualServersCurrentServerConnections,
-		csVirtualServersState:                               csVirtualServersState,
-		csVirtualServersTotalHits:                           csVirtualServersTotalHits,
-		csVirtualServersTotalRequests:                       csVirtualServersTotalRequests,
-		csVirtualServersTotalResponses:                      csVirtualServersTotalResponses,
-		csVirtualServersTotalRequestBytes:                   csVirtualServersTotalRequestBytes,
-		csVirtualServersTotalResponseBytes:                  csVirtualServersTotalResponseBytes,
-		csVirtualServersCurrentClientConnections:            csVirtualServersCurrentClientConnections,
-		csVirtualServersCurrentServerConnections:            csVirtualServersCurrentServerConnections,
-		csVirtualServersEstablishedConnections:              csVirtualServersEstablishedConnections,
-		csVirtualServersTotalPacketsReceived:                csVirtualServersTotalPacketsReceived,
-		csVirtualServersTotalPacketsSent:                    csVirtualServersTotalPacketsSent,
-		csVirtualServersTotalSpillovers:                     csVirtualServersTotalSpillovers,
-		csVirtualServersDeferredRequests:                    csVirtualServersDeferredRequests,
-		csVirtualServersNumberInvalidRequestResponse:        csVirtualServersNumberInvalidRequestResponse,
-		csVirtualServersNumberInvalidRequestResponseDropped: csVirtualServersNumberInvalidRequestResponseDropped,
-		csVirtualServersTotalVServerDownBackupHits:          csVirtualServersTotalVServerDownBackupHits,
-		csVirtualServersCurrentMultipathSessions:            csVirtualServersCurrentMultipathSessions,
-		csVirtualServersCurrentMultipathSubflows:            csVirtualServersCurrentMultipathSubflows,
-		vpnVirtualServersTotalRequests:                      vpnVirtualServersTotalRequests,
-		vpnVirtualServersTotalResponses:                     vpnVirtualServersTotalResponses,
-		vpnVirtualServersTotalRequestBytes:                  vpnVirtualServersTotalRequestBytes,
-		vpnVirtualServersTotalResponseBytes:                 vpnVirtualServersTotalResponseBytes,
-		vpnVirtualServersState:                              vpnVirtualServersState,
-		aaaAuthSuccess:                                      aaaAuthSuccess,
-		aaaAuthFail:                                         aaaAuthFail,
-		aaaAuthOnlyHTTPSuccess:                              aaaAuthOnlyHTTPSuccess,
-		aaaAuthOnlyHTTPFail:                                 aaaAuthOnlyHTTPFail,
-		aaaCurIcaSessions:                                   aaaCurIcaSessions,
-		aaaCurIcaOnlyConn:                                   aaaCurIcaOnlyConn,
+		interfacesRxBytes:                                   interfacesRxBytes(),
+		interfacesTxBytes:                                   interfacesTxBytes(),
+		interfacesRxPackets:                                 interfacesRxPackets(),
+		interfacesTxPackets:                                 interfacesTxPackets(),
+		interfacesJumboPacketsRx:                            interfacesJumboPacketsRx(),
+		interfacesJumboPacketsTx:                            interfacesJumboPacketsTx(),
+		interfacesErrorPacketsRx:                            interfacesErrorPacketsRx(),
+		interfacesErrorPacketsTx:                            interfacesErrorPacketsTx(),
+		interfacesInboundDiscards:                           interfacesInboundDiscards(),
+		interfacesOutboundDiscards:                          interfacesOutboundDiscards(),
+		interfacesDroppedPacketsRx:                          interfacesDroppedPacketsRx(),
+		interfacesDroppedPacketsTx:                          interfacesDroppedPacketsTx(),
+		interfacesCRCErrorsRx:                               interfacesCRCErrorsRx(),
+		interfacesLinkHangs:                                 interfacesLinkHangs(),
+		interfacesLinkReinitialisations:                     interfacesLinkReinitialisations(),
+		interfacesErrorDisables:                             interfacesErrorDisables(),
+		interfacesTxStalls:                                  interfacesTxStalls(),
+		interfacesRxStalls:                                  interfacesRxStalls(),
+		interfacesLinkDowns:                                 interfacesLinkDowns(),
+		interfacesBandwidthLimitDrops:                       interfacesBandwidthLimitDrops(),
+		interfacesRxBytesRate:                               interfacesRxBytesRate(),
+		interfacesTxBytesRate:                               interfacesTxBytesRate(),
+		interfacesLinkState:                                 interfacesLinkState(),
+		interfacesSpeed:                                     interfacesSpeed(),
+		interfacesFullDuplex:                                interfacesFullDuplex(),
+		interfacesMTU:                                       interfacesMTU(),
+		interfacesLACPActorInSync:                           interfacesLACPActorInSync(),
+		interfacesLACPActorCollecting:                       interfacesLACPActorCollecting(),
+		interfacesLACPActorDistributing:                     interfacesLACPActorDistributing(),
+		interfacesLACPPartnerInSync:                         interfacesLACPPartnerInSync(),
+		interfacesLACPPartnerCollecting:                     interfacesLACPPartnerCollecting(),
+		interfacesLACPPartnerDistributing:                   interfacesLACPPartnerDistributing(),
+		interfacesChannel:                                   interfacesChannel(),
+		channelsLinkState:                                   channelsLinkState(),
+		channelsSpeed:                                       channelsSpeed(),
+		channelsMembers:                                     channelsMembers(),
+		channelsMember:                                      channelsMember(),
+		vlansInterfaceBinding:                               vlansInterfaceBinding(),
+		virtualServersState:                                 virtualServersState(),
+		virtualServersWaitingRequests:                       virtualServersWaitingRequests(),
+		virtualServersHealth:                                virtualServersHealth(),
+		virtualServersInactiveServices:                      virtualServersInactiveServices(),
+		virtualServersActiveServices:                        virtualServersActiveServices(),
+		virtualServersTotalHits:                             virtualServersTotalHits(),
+		virtualServersTotalRequests:                         virtualServersTotalRequests(),
+		virtualServersTotalResponses:                        virtualServersTotalResponses(),
+		virtualServersTotalRequestBytes:                     virtualServersTotalRequestBytes(),
+		virtualServersTotalResponseBytes:                    virtualServersTotalResponseBytes(),
+		virtualServersCurrentClientConnections:              virtualServersCurrentClientConnections(),
+		virtualServersCurrentServerConnections:              virtualServersCurrentServerConnections(),
+		servicesThroughput:                                  servicesThroughput(),
+		servicesAvgTTFB:                                     servicesAvgTTFB(),
+		servicesState:                                       servicesState(),
+		servicesTotalRequests:                               servicesTotalRequests(),
+		servicesTotalResponses:                              servicesTotalResponses(),
+		servicesTotalRequestBytes:                           servicesTotalRequestBytes(),
+		servicesTotalResponseBytes:                          servicesTotalResponseBytes(),
+		servicesCurrentClientConns:                          servicesCurrentClientConns(),
+		servicesSurgeCount:                                  servicesSurgeCount(),
+		servicesCurrentServerConns:                          servicesCurrentServerConns(),
+		servicesServerEstablishedConnections:                servicesServerEstablishedConnections(),
+		servicesCurrentReusePool:                            servicesCurrentReusePool(),
+		servicesMaxClients:                                  servicesMaxClients(),
+		servicesCurrentLoad:                                 servicesCurrentLoad(),
+		servicesVirtualServerServiceHits:                    servicesVirtualServerServiceHits(),
+		servicesActiveTransactions:                          servicesActiveTransactions(),
+		serviceGroupsState:                                  serviceGroupsState(),
+		serviceGroupsAvgTTFB:                                serviceGroupsAvgTTFB(),
+		serviceGroupsTotalRequests:                          serviceGroupsTotalRequests(),
+		serviceGroupsTotalResponses:                         serviceGroupsTotalResponses(),
+		serviceGroupsTotalRequestBytes:                      serviceGroupsTotalRequestBytes(),
+		serviceGroupsTotalResponseBytes:                     serviceGroupsTotalResponseBytes(),
+		serviceGroupsCurrentClientConnections:               serviceGroupsCurrentClientConnections(),
+		serviceGroupsSurgeCount:                             serviceGroupsSurgeCount(),
+		serviceGroupsCurrentServerConnections:               serviceGroupsCurrentServerConnections(),
+		serviceGroupsServerEstablishedConnections:           serviceGroupsServerEstablishedConnections(),
+		serviceGroupsCurrentReusePool:                       serviceGroupsCurrentReusePool(),
+		serviceGroupsMaxClients:                             serviceGroupsMaxClients(),
+		gslbServicesState:                                   gslbServicesState(),
+		gslbServicesTotalRequests:                           gslbServicesTotalRequests(),
+		gslbServicesTotalResponses:                          gslbServicesTotalResponses(),
+		gslbServicesTotalRequestBytes:                       gslbServicesTotalRequestBytes(),
+		gslbServicesTotalResponseBytes:                      gslbServicesTotalResponseBytes(),
+		gslbServicesCurrentClientConns:                      gslbServicesCurrentClientConns(),
+		gslbServicesCurrentServerConns:                      gslbServicesCurrentServerConns(),
+		gslbServicesCurrentLoad:                             gslbServicesCurrentLoad(),
+		gslbServicesVirtualServerServiceHits:                gslbServicesVirtualServerServiceHits(),
+		gslbServicesEstablishedConnections:                  gslbServicesEstablishedConnections(),
+		gslbVirtualServersState:                             gslbVirtualServersState(),
+		gslbVirtualServersHealth:                            gslbVirtualServersHealth(),
+		gslbVirtualServersInactiveServices:                  gslbVirtualServersInactiveServices(),
+		gslbVirtualServersActiveServices:                    gslbVirtualServersActiveServices(),
+		gslbVirtualServersTotalHits:                         gslbVirtualServersTotalHits(),
+		gslbVirtualServersTotalRequests:                     gslbVirtualServersTotalRequests(),
+		gslbVirtualServersTotalResponses:                    gslbVirtualServersTotalResponses(),
+		gslbVirtualServersTotalRequestBytes:                 gslbVirtualServersTotalRequestBytes(),
+		gslbVirtualServersTotalResponseBytes:                gslbVirtualServersTotalResponseBytes(),
+		gslbVirtualServersCurrentClientConnections:          gslbVirtualServersCurrentClientConnections(),
+		gslbVirtualServersCurrentServerConnections:          gslbVirtualServersCurrentServerConnections(),
+		csVirtualServersState:                               csVirtualServersState(),
+		csVirtualServersTotalHits:                           csVirtualServersTotalHits(),
+		csVirtualServersTotalRequests:                       csVirtualServersTotalRequests(),
+		csVirtualServersTotalResponses:                      csVirtualServersTotalResponses(),
+		csVirtualServersTotalRequestBytes:                   csVirtualServersTotalRequestBytes(),
+		csVirtualServersTotalResponseBytes:                  csVirtualServersTotalResponseBytes(),
+		csVirtualServersCurrentClientConnections:            csVirtualServersCurrentClientConnections(),
+		csVirtualServersCurrentServerConnections:            csVirtualServersCurrentServerConnections(),
+		csVirtualServersEstablishedConnections:              csVirtualServersEstablishedConnections(),
+		csVirtualServersTotalPacketsReceived:                csVirtualServersTotalPacketsReceived(),
+		csVirtualServersTotalPacketsSent:                    csVirtualServersTotalPacketsSent(),
+		csVirtualServersTotalSpillovers:                     csVirtualServersTotalSpillovers(),
+		csVirtualServersDeferredRequests:                    csVirtualServersDeferredRequests(),
+		csVirtualServersNumberInvalidRequestResponse:        csVirtualServersNumberInvalidRequestResponse(),
+		csVirtualServersNumberInvalidRequestResponseDropped: csVirtualServersNumberInvalidRequestResponseDropped(),
+		csVirtualServersTotalVServerDownBackupHits:          csVirtualServersTotalVServerDownBackupHits(),
+		csVirtualServersCurrentMultipathSessions:            csVirtualServersCurrentMultipathSessions(),
+		csVirtualServersCurrentMultipathSubflows:            csVirtualServersCurrentMultipathSubflows(),
+		vpnVirtualServersTotalRequests:                      vpnVirtualServersTotalRequests(),
+		vpnVirtualServersTotalResponses:                     vpnVirtualServersTotalResponses(),
+		vpnVirtualServersTotalRequestBytes:                  vpnVirtualServersTotalRequestBytes(),
+		vpnVirtualServersTotalResponseBytes:                 vpnVirtualServersTotalResponseBytes(),
+		vpnVirtualServersState:                              vpnVirtualServersState(),
+		vpnVirtualServersCurrentUsers:                       vpnVirtualServersCurrentUsers(),
+		vpnVirtualServersCurrentSSLVPNUsers:                 vpnVirtualServersCurrentSSLVPNUsers(),
+		aaaAuthSuccess:                                      aaaAuthSuccess(),
+		aaaAuthFail:                                         aaaAuthFail(),
+		aaaAuthOnlyHTTPSuccess:                              aaaAuthOnlyHTTPSuccess(),
+		aaaAuthOnlyHTTPFail:                                 aaaAuthOnlyHTTPFail(),
+		aaaCurIcaSessions:                                   aaaCurIcaSessions(),
+		aaaCurIcaOnlyConn:                                   aaaCurIcaOnlyConn(),
+		aaaCurIcaConn:                                       aaaCurIcaConn(),
+		responderPoliciesHits:                               responderPoliciesHits(),
+		responderPoliciesUndefinedHits:                      responderPoliciesUndefinedHits(),
+		rewritePoliciesHits:                                 rewritePoliciesHits(),
+		rewritePoliciesUndefinedHits:                        rewritePoliciesUndefinedHits(),
+		csPoliciesHits:                                      csPoliciesHits(),
+		authenticationPoliciesHits:                          authenticationPoliciesHits(),
+		authenticationPoliciesUndefinedHits:                 authenticationPoliciesUndefinedHits(),
+		vpnLoginFailures:                                    vpnLoginFailures(),
+		vpnClientSecurityCheckRequests:                      vpnClientSecurityCheckRequests(),
+		vpnClientSecurityCheckSuccesses:                     vpnClientSecurityCheckSuccesses(),
+		vpnSTAConnectionSuccesses:                           vpnSTAConnectionSuccesses(),
+		vpnSTAConnectionFailures:                            vpnSTAConnectionFailures(),
+		vpnSTATicketValidationsNotStarted:                   vpnSTATicketValidationsNotStarted(),
+		vpnSessionInfo:                                      vpnSessionInfo(),
+		vpnSessionDuration:                                  vpnSessionDuration(),
+		vpnSessionsNotExported:                              vpnSessionsNotExported(),
+		licenseFeatureEnabled:                               licenseFeatureEnabled(),
+		licenseEdition:                                      licenseEdition(),
+		licenseDaysToExpiration:                             licenseDaysToExpiration(),
+		licenseFileExpiryTimestamp:                          licenseFileExpiryTimestamp(),
+		licenseFileDaysToExpiration:                         licenseFileDaysToExpiration(),
+		licenseCapacityBandwidth:                            licenseCapacityBandwidth(),
+		licenseCapacityVCPUs:                                licenseCapacityVCPUs(),
+		partitionBandwidth:                                  partitionBandwidth(),
+		partitionMaxBandwidth:                               partitionMaxBandwidth(),
+		partitionConnections:                                partitionConnections(),
+		partitionMaxConnections:                             partitionMaxConnections(),
+		partitionMemoryUsage:                                partitionMemoryUsage(),
+		partitionMaxMemory:                                  partitionMaxMemory(),
+		trafficDomainInfo:                                   trafficDomainInfo(),
+		trafficDomainReceivedPackets:                        trafficDomainReceivedPackets(),
+		trafficDomainReceivedPacketsRate:                    trafficDomainReceivedPacketsRate(),
+		trafficDomainTransmitPackets:                        trafficDomainTransmitPackets(),
+		trafficDomainTransmitPacketsRate:                    trafficDomainTransmitPacketsRate(),
+		trafficDomainDroppedPackets:                         trafficDomainDroppedPackets(),
+		trafficDomainDroppedPacketsRate:                     trafficDomainDroppedPacketsRate(),
+		serversState:                                        serversState(),
 		username:                                            username,
 		password:                                            password,
 		url:                                                 url,
 		ignoreCert:                                          ignoreCert,
-		logger:                                              logger,
-		nsInstance:                                          nsInstance,
+		proxyInstance:                                       opts.ProxyInstance,
+		policyFilter:                                        opts.PolicyFilter,
+		vpnSessions:                                         opts.VPNSessions,
+		vpnSessionsMaxSeries:                                opts.VPNSessionsMaxSeries,
+		serviceGroupBulk:                                    opts.ServiceGroupBulk,
+		trafficDomains:                                      opts.TrafficDomains,
+		partitions:                                          opts.Partitions,
+		mappings:                                            opts.Mappings,
+		mappingDescs:                                        newMappingDescs(opts.Mappings),
+		target: target{
+			logger:     logger,
+			nsInstance: nsInstance,
+		},
 	}, nil
 }
 
 // Describe implements Collector
 func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- up
+	ch <- loginFailures
+	ch <- lastErrorInfo
+	ch <- apiErrors
+	ch <- nsVersionInfo
+	ch <- bootTime
+	ch <- uptime
 	ch <- modelID
 	ch <- mgmtCPUUsage
 	ch <- memUsage
@@ -288,6 +504,39 @@ func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
 	e.interfacesJumboPacketsRx.Describe(ch)
 	e.interfacesJumboPacketsTx.Describe(ch)
 	e.interfacesErrorPacketsRx.Describe(ch)
+	e.interfacesErrorPacketsTx.Describe(ch)
+	e.interfacesInboundDiscards.Describe(ch)
+	e.interfacesOutboundDiscards.Describe(ch)
+	e.interfacesDroppedPacketsRx.Describe(ch)
+	e.interfacesDroppedPacketsTx.Describe(ch)
+	e.interfacesCRCErrorsRx.Describe(ch)
+	e.interfacesLinkHangs.Describe(ch)
+	e.interfacesLinkReinitialisations.Describe(ch)
+	e.interfacesErrorDisables.Describe(ch)
+	e.interfacesTxStalls.Describe(ch)
+	e.interfacesRxStalls.Describe(ch)
+	e.interfacesLinkDowns.Describe(ch)
+	e.interfacesBandwidthLimitDrops.Describe(ch)
+	e.interfacesRxBytesRate.Describe(ch)
+	e.interfacesTxBytesRate.Describe(ch)
+	e.interfacesLinkState.Describe(ch)
+	e.interfacesSpeed.Describe(ch)
+	e.interfacesFullDuplex.Describe(ch)
+	e.interfacesMTU.Describe(ch)
+	e.interfacesLACPActorInSync.Describe(ch)
+	e.interfacesLACPActorCollecting.Describe(ch)
+	e.interfacesLACPActorDistributing.Describe(ch)
+	e.interfacesLACPPartnerInSync.Describe(ch)
+	e.interfacesLACPPartnerCollecting.Describe(ch)
+	e.interfacesLACPPartnerDistributing.Describe(ch)
+	e.interfacesChannel.Describe(ch)
+
+	e.channelsLinkState.Describe(ch)
+	e.channelsSpeed.Describe(ch)
+	e.channelsMembers.Describe(ch)
+	e.channelsMember.Describe(ch)
+
+	e.vlansInterfaceBinding.Describe(ch)
 
 	e.virtualServersState.Describe(ch)
 	e.virtualServersWaitingRequests.Describe(ch)
@@ -379,6 +628,8 @@ func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
 	e.vpnVirtualServersTotalRequestBytes.Describe(ch)
 	e.vpnVirtualServersTotalResponseBytes.Describe(ch)
 	e.vpnVirtualServersState.Describe(ch)
+	e.vpnVirtualServersCurrentUsers.Describe(ch)
+	e.vpnVirtualServersCurrentSSLVPNUsers.Describe(ch)
 
 	e.aaaAuthSuccess.Describe(ch)
 	e.aaaAuthFail.Describe(ch)
@@ -386,4 +637,53 @@ func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
 	e.aaaAuthOnlyHTTPFail.Describe(ch)
 	e.aaaCurIcaSessions.Describe(ch)
 	e.aaaCurIcaOnlyConn.Describe(ch)
+	e.aaaCurIcaConn.Describe(ch)
+
+	e.responderPoliciesHits.Describe(ch)
+	e.responderPoliciesUndefinedHits.Describe(ch)
+	e.rewritePoliciesHits.Describe(ch)
+	e.rewritePoliciesUndefinedHits.Describe(ch)
+	e.csPoliciesHits.Describe(ch)
+	e.authenticationPoliciesHits.Describe(ch)
+	e.authenticationPoliciesUndefinedHits.Describe(ch)
+
+	e.vpnLoginFailures.Describe(ch)
+	e.vpnClientSecurityCheckRequests.Describe(ch)
+	e.vpnClientSecurityCheckSuccesses.Describe(ch)
+	e.vpnSTAConnectionSuccesses.Describe(ch)
+	e.vpnSTAConnectionFailures.Describe(ch)
+	e.vpnSTATicketValidationsNotStarted.Describe(ch)
+
+	e.vpnSessionInfo.Describe(ch)
+	e.vpnSessionDuration.Describe(ch)
+	e.vpnSessionsNotExported.Describe(ch)
+
+	e.licenseFeatureEnabled.Describe(ch)
+	e.licenseEdition.Describe(ch)
+	e.licenseDaysToExpiration.Describe(ch)
+	e.licenseFileExpiryTimestamp.Describe(ch)
+	e.licenseFileDaysToExpiration.Describe(ch)
+	e.licenseCapacityBandwidth.Describe(ch)
+	e.licenseCapacityVCPUs.Describe(ch)
+
+	e.partitionBandwidth.Describe(ch)
+	e.partitionMaxBandwidth.Describe(ch)
+	e.partitionConnections.Describe(ch)
+	e.partitionMaxConnections.Describe(ch)
+	e.partitionMemoryUsage.Describe(ch)
+	e.partitionMaxMemory.Describe(ch)
+	e.trafficDomainInfo.Describe(ch)
+	e.trafficDomainReceivedPackets.Describe(ch)
+	e.trafficDomainReceivedPacketsRate.Describe(ch)
+	e.trafficDomainTransmitPackets.Describe(ch)
+	e.trafficDomainTransmitPacketsRate.Describe(ch)
+	e.trafficDomainDroppedPackets.Describe(ch)
+	e.trafficDomainDroppedPacketsRate.Describe(ch)
+	e.serversState.Describe(ch)
+
+	for _, descs := range e.mappingDescs {
+		for _, d := range descs {
+			ch <- d.desc
+		}
+	}
 }
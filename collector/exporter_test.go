@@ -0,0 +1,69 @@
+package collector
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/rokett/citrix-netscaler-exporter/netscaler/nitrotest"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestExportersDoNotShareMetrics(t *testing.T) {
+	srv, err := nitrotest.NewServerWithFixtures(nitrotest.Fixtures())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	instances := []string{"shared-ns01", "shared-ns02"}
+
+	var exporters []*Exporter
+	for _, instance := range instances {
+		e, err := NewExporter(srv.URL, "user", "pass", false, log.NewNopLogger(), instance, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		exporters = append(exporters, e)
+	}
+
+	if exporters[0].interfacesRxBytes == exporters[1].interfacesRxBytes {
+		t.Fatal("exporters share the interfaces_received_bytes vec")
+	}
+
+	// Targets polled at the same time are collected concurrently; each exporter's metrics must only carry its own instance.
+	for i := 0; i < 10; i++ {
+		var wg sync.WaitGroup
+
+		for n, e := range exporters {
+			wg.Add(1)
+
+			go func(e *Exporter, instance string) {
+				defer wg.Done()
+
+				registry := prometheus.NewRegistry()
+				registry.MustRegister(e)
+
+				families, err := registry.Gather()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				for _, mf := range families {
+					for _, m := range mf.Metric {
+						for _, l := range m.Label {
+							if l.GetName() == "ns_instance" && l.GetValue() != instance {
+								t.Errorf("%s from the exporter for %s has ns_instance %q", mf.GetName(), instance, l.GetValue())
+							}
+						}
+					}
+				}
+			}(e, instances[n])
+		}
+
+		wg.Wait()
+	}
+}
@@ -0,0 +1,152 @@
+package collector
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rokett/citrix-netscaler-exporter/netscaler/nitrotest"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+var update = flag.Bool("update", false, "Rewrite the golden files in testdata with the current output")
+
+// volatileMetrics depend on the time the test runs, so their values are replaced with 0 before comparing.
+var volatileMetrics = map[string]bool{
+	"uptime_seconds":                  true,
+	"license_file_days_to_expiration": true,
+	"vpn_session_duration_seconds":    true,
+}
+
+// TestGolden runs the exporter against recordings in testdata, made with the record subcommand, and compares the metrics with the golden file for each test.
+// Run go test -update to rewrite the golden files after an intended change, and check the diff.
+func TestGolden(t *testing.T) {
+	tests := []struct {
+		name           string
+		recording      string
+		vpnSessions    bool
+		bulk           bool
+		trafficDomains bool
+		partitions     []string
+		// requests are the number of requests expected for some keys; service group members give the same metrics whether or not they are fetched in bulk.
+		requests map[string]int
+	}{
+		{
+			// Everything optional turned on, as the recording was made.
+			name:           "adc",
+			recording:      "adc",
+			vpnSessions:    true,
+			bulk:           true,
+			trafficDomains: true,
+			partitions:     []string{"all"},
+			// One request in each of the two partitions.
+			requests: map[string]int{
+				"stat/servicegroupmember": 2,
+				nitrotest.Key("config/servicegroup", "attrs=servicegroupname&pagesize=1000&pageno=1"): 0,
+			},
+		},
+		{
+			name:      "adc_defaults",
+			recording: "adc",
+			requests: map[string]int{
+				"stat/servicegroupmember": 0,
+				nitrotest.Key("config/servicegroup", "attrs=servicegroupname&pagesize=1000&pageno=1"): 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, err := nitrotest.NewServerWithFixtures(os.DirFS(filepath.Join("testdata", tt.recording)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer srv.Close()
+
+			// The recording was made with the default vpn_sessions_max_series, which sets the page size of the session requests.
+			e, err := NewExporter(srv.URL, "user", "pass", false, log.NewNopLogger(), "golden-"+tt.name, Options{
+				VPNSessions:          tt.vpnSessions,
+				VPNSessionsMaxSeries: 500,
+				ServiceGroupBulk:     tt.bulk,
+				TrafficDomains:       tt.trafficDomains,
+				Partitions:           tt.partitions,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := gather(t, e)
+
+			for key, want := range tt.requests {
+				if n := srv.Requests(key); n != want {
+					t.Errorf("Requests(%q) = %d, want %d", key, n, want)
+				}
+			}
+
+			compareGolden(t, tt.name, got)
+		})
+	}
+}
+
+// compareGolden compares the metrics with the named golden file in testdata, or rewrites the file with -update.
+func compareGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	golden := filepath.Join("testdata", name+".golden")
+
+	if *update {
+		err := os.WriteFile(golden, got, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("metrics do not match %s; run go test -update and check the diff\n%s", golden, got)
+	}
+}
+
+// gather collects from the exporter, returning the metrics in the text exposition format.
+func gather(t *testing.T, e prometheus.Collector) []byte {
+	t.Helper()
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(e)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+
+	for _, mf := range families {
+		if volatileMetrics[mf.GetName()] {
+			for _, m := range mf.Metric {
+				switch {
+				case m.Gauge != nil:
+					m.Gauge.Value = new(float64)
+				case m.Counter != nil:
+					m.Counter.Value = new(float64)
+				}
+			}
+		}
+
+		_, err = expfmt.MetricFamilyToText(&buf, mf)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return buf.Bytes()
+}
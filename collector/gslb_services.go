@@ -9,7 +9,7 @@ import (
 )
 
 var (
-	gslbServicesState = prometheus.NewGaugeVec(
+	gslbServicesState = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "gslb_service_state",
 			Help: "Current state of the service",
@@ -20,7 +20,7 @@ var (
 		},
 	)
 
-	gslbServicesTotalRequests = prometheus.NewCounterVec(
+	gslbServicesTotalRequests = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "gslb_service_total_requests",
 			Help: "Total number of requests received on this service",
@@ -31,7 +31,7 @@ var (
 		},
 	)
 
-	gslbServicesTotalResponses = prometheus.NewCounterVec(
+	gslbServicesTotalResponses = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "gslb_service_total_responses",
 			Help: "Total number of responses received on this service",
@@ -42,7 +42,7 @@ var (
 		},
 	)
 
-	gslbServicesTotalRequestBytes = prometheus.NewCounterVec(
+	gslbServicesTotalRequestBytes = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "gslb_service_total_request_bytes",
 			Help: "Total number of request bytes received on this service",
@@ -53,7 +53,7 @@ var (
 		},
 	)
 
-	gslbServicesTotalResponseBytes = prometheus.NewCounterVec(
+	gslbServicesTotalResponseBytes = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "gslb_service_total_response_bytes",
 			Help: "Total number of response bytes received on this service",
@@ -64,7 +64,7 @@ var (
 		},
 	)
 
-	gslbServicesCurrentClientConns = prometheus.NewGaugeVec(
+	gslbServicesCurrentClientConns = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "gslb_service_current_client_connections",
 			Help: "Number of current client connections",
@@ -75,7 +75,7 @@ var (
 		},
 	)
 
-	gslbServicesCurrentServerConns = prometheus.NewGaugeVec(
+	gslbServicesCurrentServerConns = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "gslb_service_current_server_connections",
 			Help: "Number of current connections to the actual servers",
@@ -86,7 +86,7 @@ var (
 		},
 	)
 
-	gslbServicesEstablishedConnections = prometheus.NewGaugeVec(
+	gslbServicesEstablishedConnections = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "gslb_service_established_connections",
 			Help: "Number of server connections in ESTABLISHED state",
@@ -97,7 +97,7 @@ var (
 		},
 	)
 
-	gslbServicesCurrentLoad = prometheus.NewGaugeVec(
+	gslbServicesCurrentLoad = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "gslb_service_current_load",
 			Help: "Load on the service that is calculated from the bound load based monitor",
@@ -108,7 +108,7 @@ var (
 		},
 	)
 
-	gslbServicesVirtualServerServiceHits = prometheus.NewCounterVec(
+	gslbServicesVirtualServerServiceHits = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "gslb_service_virtual_server_service_hits",
 			Help: "Number of times that the service has been provided",
@@ -120,10 +120,10 @@ var (
 	)
 )
 
-func (e *Exporter) collectGSLBServicesState(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectGSLBServicesState(services []netscaler.GSLBServiceStats) {
 	e.gslbServicesState.Reset()
 
-	for _, service := range ns.GSLBServiceStats {
+	for _, service := range services {
 		state := 0.0
 
 		if service.State == "UP" {
@@ -134,82 +134,82 @@ func (e *Exporter) collectGSLBServicesState(ns netscaler.NSAPIResponse) {
 	}
 }
 
-func (e *Exporter) collectGSLBServicesTotalRequests(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectGSLBServicesTotalRequests(services []netscaler.GSLBServiceStats) {
 	e.gslbServicesTotalRequests.Reset()
 
-	for _, service := range ns.GSLBServiceStats {
+	for _, service := range services {
 		val, _ := strconv.ParseFloat(service.TotalRequests, 64)
 		e.gslbServicesTotalRequests.WithLabelValues(e.nsInstance, service.Name).Set(val)
 	}
 }
 
-func (e *Exporter) collectGSLBServicesTotalResponses(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectGSLBServicesTotalResponses(services []netscaler.GSLBServiceStats) {
 	e.gslbServicesTotalResponses.Reset()
 
-	for _, service := range ns.GSLBServiceStats {
+	for _, service := range services {
 		val, _ := strconv.ParseFloat(service.TotalResponses, 64)
 		e.gslbServicesTotalResponses.WithLabelValues(e.nsInstance, service.Name).Set(val)
 	}
 }
 
-func (e *Exporter) collectGSLBServicesTotalRequestBytes(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectGSLBServicesTotalRequestBytes(services []netscaler.GSLBServiceStats) {
 	e.gslbServicesTotalRequestBytes.Reset()
 
-	for _, service := range ns.GSLBServiceStats {
+	for _, service := range services {
 		val, _ := strconv.ParseFloat(service.TotalRequestBytes, 64)
 		e.gslbServicesTotalRequestBytes.WithLabelValues(e.nsInstance, service.Name).Set(val)
 	}
 }
 
-func (e *Exporter) collectGSLBServicesTotalResponseBytes(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectGSLBServicesTotalResponseBytes(services []netscaler.GSLBServiceStats) {
 	e.gslbServicesTotalResponseBytes.Reset()
 
-	for _, service := range ns.GSLBServiceStats {
+	for _, service := range services {
 		val, _ := strconv.ParseFloat(service.TotalResponseBytes, 64)
 		e.gslbServicesTotalResponseBytes.WithLabelValues(e.nsInstance, service.Name).Set(val)
 	}
 }
 
-func (e *Exporter) collectGSLBServicesCurrentClientConns(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectGSLBServicesCurrentClientConns(services []netscaler.GSLBServiceStats) {
 	e.gslbServicesCurrentClientConns.Reset()
 
-	for _, service := range ns.GSLBServiceStats {
+	for _, service := range services {
 		val, _ := strconv.ParseFloat(service.CurrentClientConnections, 64)
 		e.gslbServicesCurrentClientConns.WithLabelValues(e.nsInstance, service.Name).Set(val)
 	}
 }
 
-func (e *Exporter) collectGSLBServicesCurrentServerConns(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectGSLBServicesCurrentServerConns(services []netscaler.GSLBServiceStats) {
 	e.gslbServicesCurrentServerConns.Reset()
 
-	for _, service := range ns.GSLBServiceStats {
+	for _, service := range services {
 		val, _ := strconv.ParseFloat(service.CurrentServerConnections, 64)
 		e.gslbServicesCurrentServerConns.WithLabelValues(e.nsInstance, service.Name).Set(val)
 	}
 }
 
-func (e *Exporter) collectGSLBServicesEstablishedConnections(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectGSLBServicesEstablishedConnections(services []netscaler.GSLBServiceStats) {
 	e.gslbServicesEstablishedConnections.Reset()
 
-	for _, service := range ns.GSLBServiceStats {
+	for _, service := range services {
 		val, _ := strconv.ParseFloat(service.EstablishedConnections, 64)
 		e.gslbServicesEstablishedConnections.WithLabelValues(e.nsInstance, service.Name).Set(val)
 	}
 }
 
-func (e *Exporter) collectGSLBServicesCurrentLoad(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectGSLBServicesCurrentLoad(services []netscaler.GSLBServiceStats) {
 	e.gslbServicesCurrentLoad.Reset()
 
-	for _, service := range ns.GSLBServiceStats {
+	for _, service := range services {
 		val, _ := strconv.ParseFloat(service.CurrentLoad, 64)
 		e.gslbServicesCurrentLoad.WithLabelValues(e.nsInstance, service.Name).Set(val)
 	}
 }
 
-func (e *Exporter) collectGSLBServicesVirtualServerServiceHits(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectGSLBServicesVirtualServerServiceHits(services []netscaler.GSLBServiceStats) {
 	e.gslbServicesVirtualServerServiceHits.Reset()
 
-	for _, service := range ns.GSLBServiceStats {
+	for _, service := range services {
 		val, _ := strconv.ParseFloat(service.ServiceHits, 64)
 		e.gslbServicesVirtualServerServiceHits.WithLabelValues(e.nsInstance, service.Name).Set(val)
 	}
@@ -9,7 +9,7 @@ import (
 )
 
 var (
-	gslbVirtualServersState = prometheus.NewGaugeVec(
+	gslbVirtualServersState = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "gslb_virtual_servers_state",
 			Help: "Current state of the server",
@@ -20,7 +20,7 @@ var (
 		},
 	)
 
-	gslbVirtualServersHealth = prometheus.NewGaugeVec(
+	gslbVirtualServersHealth = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "gslb_virtual_servers_health",
 			Help: "Percentage of UP services bound to a specific virtual server",
@@ -31,7 +31,7 @@ var (
 		},
 	)
 
-	gslbVirtualServersInactiveServices = prometheus.NewGaugeVec(
+	gslbVirtualServersInactiveServices = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "gslb_virtual_servers_inactive_services",
 			Help: "Number of inactive services bound to a specific virtual server",
@@ -42,7 +42,7 @@ var (
 		},
 	)
 
-	gslbVirtualServersActiveServices = prometheus.NewGaugeVec(
+	gslbVirtualServersActiveServices = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "gslb_virtual_servers_active_services",
 			Help: "Number of active services bound to a specific virtual server",
@@ -53,7 +53,7 @@ var (
 		},
 	)
 
-	gslbVirtualServersTotalHits = prometheus.NewCounterVec(
+	gslbVirtualServersTotalHits = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "gslb_virtual_servers_total_hits",
 			Help: "Total virtual server hits",
@@ -64,7 +64,7 @@ var (
 		},
 	)
 
-	gslbVirtualServersTotalRequests = prometheus.NewCounterVec(
+	gslbVirtualServersTotalRequests = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "gslb_virtual_servers_total_requests",
 			Help: "Total virtual server requests",
@@ -75,7 +75,7 @@ var (
 		},
 	)
 
-	gslbVirtualServersTotalResponses = prometheus.NewCounterVec(
+	gslbVirtualServersTotalResponses = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "gslb_virtual_servers_total_responses",
 			Help: "Total virtual server responses",
@@ -86,7 +86,7 @@ var (
 		},
 	)
 
-	gslbVirtualServersTotalRequestBytes = prometheus.NewCounterVec(
+	gslbVirtualServersTotalRequestBytes = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "gslb_virtual_servers_total_request_bytes",
 			Help: "Total virtual server request bytes",
@@ -97,7 +97,7 @@ var (
 		},
 	)
 
-	gslbVirtualServersTotalResponseBytes = prometheus.NewCounterVec(
+	gslbVirtualServersTotalResponseBytes = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "gslb_virtual_servers_total_response_bytes",
 			Help: "Total virtual server response bytes",
@@ -108,7 +108,7 @@ var (
 		},
 	)
 
-	gslbVirtualServersCurrentClientConnections = prometheus.NewGaugeVec(
+	gslbVirtualServersCurrentClientConnections = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "gslb_virtual_servers_current_client_connections",
 			Help: "Number of current client connections on a specific virtual server",
@@ -119,7 +119,7 @@ var (
 		},
 	)
 
-	gslbVirtualServersCurrentServerConnections = prometheus.NewGaugeVec(
+	gslbVirtualServersCurrentServerConnections = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "gslb_virtual_servers_current_server_connections",
 			Help: "Number of current connections to the actual servers behind the specific virtual server.",
@@ -131,10 +131,10 @@ var (
 	)
 )
 
-func (e *Exporter) collectGSLBVirtualServerState(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectGSLBVirtualServerState(virtualServers []netscaler.GSLBVirtualServerStats) {
 	e.gslbVirtualServersState.Reset()
 
-	for _, vs := range ns.GSLBVirtualServerStats {
+	for _, vs := range virtualServers {
 		state := 0.0
 
 		if vs.State == "UP" {
@@ -145,91 +145,91 @@ func (e *Exporter) collectGSLBVirtualServerState(ns netscaler.NSAPIResponse) {
 	}
 }
 
-func (e *Exporter) collectGSLBVirtualServerHealth(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectGSLBVirtualServerHealth(virtualServers []netscaler.GSLBVirtualServerStats) {
 	e.gslbVirtualServersHealth.Reset()
 
-	for _, vs := range ns.GSLBVirtualServerStats {
+	for _, vs := range virtualServers {
 		health, _ := strconv.ParseFloat(vs.Health, 64)
 		e.gslbVirtualServersHealth.WithLabelValues(e.nsInstance, vs.Name).Set(health)
 	}
 }
 
-func (e *Exporter) collectGSLBVirtualServerInactiveServices(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectGSLBVirtualServerInactiveServices(virtualServers []netscaler.GSLBVirtualServerStats) {
 	e.gslbVirtualServersInactiveServices.Reset()
 
-	for _, vs := range ns.GSLBVirtualServerStats {
+	for _, vs := range virtualServers {
 		inactiveServices, _ := strconv.ParseFloat(vs.InactiveServices, 64)
 		e.gslbVirtualServersInactiveServices.WithLabelValues(e.nsInstance, vs.Name).Set(inactiveServices)
 	}
 }
 
-func (e *Exporter) collectGSLBVirtualServerActiveServices(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectGSLBVirtualServerActiveServices(virtualServers []netscaler.GSLBVirtualServerStats) {
 	e.gslbVirtualServersActiveServices.Reset()
 
-	for _, vs := range ns.GSLBVirtualServerStats {
+	for _, vs := range virtualServers {
 		activeServices, _ := strconv.ParseFloat(vs.ActiveServices, 64)
 		e.gslbVirtualServersActiveServices.WithLabelValues(e.nsInstance, vs.Name).Set(activeServices)
 	}
 }
 
-func (e *Exporter) collectGSLBVirtualServerTotalHits(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectGSLBVirtualServerTotalHits(virtualServers []netscaler.GSLBVirtualServerStats) {
 	e.gslbVirtualServersTotalHits.Reset()
 
-	for _, vs := range ns.GSLBVirtualServerStats {
+	for _, vs := range virtualServers {
 		totalHits, _ := strconv.ParseFloat(vs.TotalHits, 64)
 		e.gslbVirtualServersTotalHits.WithLabelValues(e.nsInstance, vs.Name).Set(totalHits)
 	}
 }
 
-func (e *Exporter) collectGSLBVirtualServerTotalRequests(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectGSLBVirtualServerTotalRequests(virtualServers []netscaler.GSLBVirtualServerStats) {
 	e.gslbVirtualServersTotalRequests.Reset()
 
-	for _, vs := range ns.GSLBVirtualServerStats {
+	for _, vs := range virtualServers {
 		totalRequests, _ := strconv.ParseFloat(vs.TotalRequests, 64)
 		e.gslbVirtualServersTotalRequests.WithLabelValues(e.nsInstance, vs.Name).Set(totalRequests)
 	}
 }
 
-func (e *Exporter) collectGSLBVirtualServerTotalResponses(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectGSLBVirtualServerTotalResponses(virtualServers []netscaler.GSLBVirtualServerStats) {
 	e.gslbVirtualServersTotalResponses.Reset()
 
-	for _, vs := range ns.GSLBVirtualServerStats {
+	for _, vs := range virtualServers {
 		totalResponses, _ := strconv.ParseFloat(vs.TotalResponses, 64)
 		e.gslbVirtualServersTotalResponses.WithLabelValues(e.nsInstance, vs.Name).Set(totalResponses)
 	}
 }
 
-func (e *Exporter) collectGSLBVirtualServerTotalRequestBytes(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectGSLBVirtualServerTotalRequestBytes(virtualServers []netscaler.GSLBVirtualServerStats) {
 	e.gslbVirtualServersTotalRequestBytes.Reset()
 
-	for _, vs := range ns.GSLBVirtualServerStats {
+	for _, vs := range virtualServers {
 		totalRequestBytes, _ := strconv.ParseFloat(vs.TotalRequestBytes, 64)
 		e.gslbVirtualServersTotalRequestBytes.WithLabelValues(e.nsInstance, vs.Name).Set(totalRequestBytes)
 	}
 }
 
-func (e *Exporter) collectGSLBVirtualServerTotalResponseBytes(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectGSLBVirtualServerTotalResponseBytes(virtualServers []netscaler.GSLBVirtualServerStats) {
 	e.gslbVirtualServersTotalResponseBytes.Reset()
 
-	for _, vs := range ns.GSLBVirtualServerStats {
+	for _, vs := range virtualServers {
 		totalResponseBytes, _ := strconv.ParseFloat(vs.TotalResponseBytes, 64)
 		e.gslbVirtualServersTotalResponseBytes.WithLabelValues(e.nsInstance, vs.Name).Set(totalResponseBytes)
 	}
 }
 
-func (e *Exporter) collectGSLBVirtualServerCurrentClientConnections(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectGSLBVirtualServerCurrentClientConnections(virtualServers []netscaler.GSLBVirtualServerStats) {
 	e.gslbVirtualServersCurrentClientConnections.Reset()
 
-	for _, vs := range ns.GSLBVirtualServerStats {
+	for _, vs := range virtualServers {
 		currentClientConnections, _ := strconv.ParseFloat(vs.CurrentClientConnections, 64)
 		e.gslbVirtualServersCurrentClientConnections.WithLabelValues(e.nsInstance, vs.Name).Set(currentClientConnections)
 	}
 }
 
-func (e *Exporter) collectGSLBVirtualServerCurrentServerConnections(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectGSLBVirtualServerCurrentServerConnections(virtualServers []netscaler.GSLBVirtualServerStats) {
 	e.gslbVirtualServersCurrentServerConnections.Reset()
 
-	for _, vs := range ns.GSLBVirtualServerStats {
+	for _, vs := range virtualServers {
 		currentServerConnections, _ := strconv.ParseFloat(vs.CurrentServerConnections, 64)
 		e.gslbVirtualServersCurrentServerConnections.WithLabelValues(e.nsInstance, vs.Name).Set(currentServerConnections)
 	}
@@ -9,8 +9,8 @@ import (
 )
 
 var (
-	interfacesRxBytes = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
+	interfacesRxBytes = newCounterVec(
+		prometheus.CounterOpts{
 			Name: "interfaces_received_bytes",
 			Help: "Number of bytes received by specific interfaces.",
 		},
@@ -21,8 +21,8 @@ var (
 		},
 	)
 
-	interfacesTxBytes = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
+	interfacesTxBytes = newCounterVec(
+		prometheus.CounterOpts{
 			Name: "interfaces_transmitted_bytes",
 			Help: "Number of bytes transmitted by specific interfaces.",
 		},
@@ -33,8 +33,8 @@ var (
 		},
 	)
 
-	interfacesRxPackets = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
+	interfacesRxPackets = newCounterVec(
+		prometheus.CounterOpts{
 			Name: "interfaces_received_packets",
 			Help: "Number of packets received by specific interfaces",
 		},
@@ -45,8 +45,8 @@ var (
 		},
 	)
 
-	interfacesTxPackets = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
+	interfacesTxPackets = newCounterVec(
+		prometheus.CounterOpts{
 			Name: "interfaces_transmitted_packets",
 			Help: "Number of packets transmitted by specific interfaces",
 		},
@@ -57,8 +57,8 @@ var (
 		},
 	)
 
-	interfacesJumboPacketsRx = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
+	interfacesJumboPacketsRx = newCounterVec(
+		prometheus.CounterOpts{
 			Name: "interfaces_jumbo_packets_received",
 			Help: "Number of bytes received by specific interfaces",
 		},
@@ -69,8 +69,8 @@ var (
 		},
 	)
 
-	interfacesJumboPacketsTx = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
+	interfacesJumboPacketsTx = newCounterVec(
+		prometheus.CounterOpts{
 			Name: "interfaces_jumbo_packets_transmitted",
 			Help: "Number of jumbo packets transmitted by specific interfaces",
 		},
@@ -81,8 +81,8 @@ var (
 		},
 	)
 
-	interfacesErrorPacketsRx = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
+	interfacesErrorPacketsRx = newCounterVec(
+		prometheus.CounterOpts{
 			Name: "interfaces_error_packets_received",
 			Help: "Number of error packets received by specific interfaces",
 		},
@@ -92,67 +92,674 @@ var (
 			"alias",
 		},
 	)
+
+	interfacesLinkState = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "interfaces_link_state",
+			Help: "Link state of the interface; 1 if the link is up",
+		},
+		[]string{
+			"ns_instance",
+			"interface",
+			"alias",
+		},
+	)
+
+	interfacesSpeed = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "interfaces_speed_mbps",
+			Help: "Actual speed of the interface in Mbps",
+		},
+		[]string{
+			"ns_instance",
+			"interface",
+			"alias",
+		},
+	)
+
+	interfacesFullDuplex = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "interfaces_full_duplex",
+			Help: "Duplex mode of the interface; 1 if full duplex",
+		},
+		[]string{
+			"ns_instance",
+			"interface",
+			"alias",
+		},
+	)
+
+	interfacesMTU = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "interfaces_mtu",
+			Help: "Actual MTU of the interface",
+		},
+		[]string{
+			"ns_instance",
+			"interface",
+			"alias",
+		},
+	)
+
+	interfacesLACPActorInSync = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "interfaces_lacp_actor_in_sync",
+			Help: "LACP actor synchronisation state; 1 if in sync",
+		},
+		[]string{
+			"ns_instance",
+			"interface",
+			"alias",
+		},
+	)
+
+	interfacesLACPActorCollecting = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "interfaces_lacp_actor_collecting",
+			Help: "LACP actor collecting state; 1 if collecting",
+		},
+		[]string{
+			"ns_instance",
+			"interface",
+			"alias",
+		},
+	)
+
+	interfacesLACPActorDistributing = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "interfaces_lacp_actor_distributing",
+			Help: "LACP actor distributing state; 1 if distributing",
+		},
+		[]string{
+			"ns_instance",
+			"interface",
+			"alias",
+		},
+	)
+
+	interfacesLACPPartnerInSync = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "interfaces_lacp_partner_in_sync",
+			Help: "LACP partner synchronisation state; 1 if in sync",
+		},
+		[]string{
+			"ns_instance",
+			"interface",
+			"alias",
+		},
+	)
+
+	interfacesLACPPartnerCollecting = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "interfaces_lacp_partner_collecting",
+			Help: "LACP partner collecting state; 1 if collecting",
+		},
+		[]string{
+			"ns_instance",
+			"interface",
+			"alias",
+		},
+	)
+
+	interfacesLACPPartnerDistributing = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "interfaces_lacp_partner_distributing",
+			Help: "LACP partner distributing state; 1 if distributing",
+		},
+		[]string{
+			"ns_instance",
+			"interface",
+			"alias",
+		},
+	)
+
+	interfacesChannel = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "interfaces_channel_member",
+			Help: "Interface is a member of the given link aggregation channel; always 1",
+		},
+		[]string{
+			"ns_instance",
+			"interface",
+			"alias",
+			"channel",
+		},
+	)
+
+	interfacesErrorPacketsTx = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "interfaces_error_packets_transmitted",
+			Help: "Number of error packets transmitted by specific interfaces",
+		},
+		[]string{
+			"ns_instance",
+			"interface",
+			"alias",
+		},
+	)
+
+	interfacesInboundDiscards = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "interfaces_inbound_discards",
+			Help: "Number of inbound packets discarded by specific interfaces",
+		},
+		[]string{
+			"ns_instance",
+			"interface",
+			"alias",
+		},
+	)
+
+	interfacesOutboundDiscards = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "interfaces_outbound_discards",
+			Help: "Number of outbound packets discarded by specific interfaces",
+		},
+		[]string{
+			"ns_instance",
+			"interface",
+			"alias",
+		},
+	)
+
+	interfacesDroppedPacketsRx = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "interfaces_dropped_packets_received",
+			Help: "Number of inbound packets dropped by specific interfaces",
+		},
+		[]string{
+			"ns_instance",
+			"interface",
+			"alias",
+		},
+	)
+
+	interfacesDroppedPacketsTx = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "interfaces_dropped_packets_transmitted",
+			Help: "Number of outbound packets dropped by specific interfaces; for example when the link is down or the bandwidth limit has been reached",
+		},
+		[]string{
+			"ns_instance",
+			"interface",
+			"alias",
+		},
+	)
+
+	interfacesCRCErrorsRx = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "interfaces_crc_errors_received",
+			Help: "Number of packets with CRC errors received by specific interfaces",
+		},
+		[]string{
+			"ns_instance",
+			"interface",
+			"alias",
+		},
+	)
+
+	interfacesLinkHangs = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "interfaces_link_hangs",
+			Help: "Number of times the NIC hung on specific interfaces",
+		},
+		[]string{
+			"ns_instance",
+			"interface",
+			"alias",
+		},
+	)
+
+	interfacesLinkReinitialisations = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "interfaces_link_reinitialisations",
+			Help: "Number of times the link has been reinitialised on specific interfaces; for example after the link went down",
+		},
+		[]string{
+			"ns_instance",
+			"interface",
+			"alias",
+		},
+	)
+
+	interfacesErrorDisables = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "interfaces_error_disables",
+			Help: "Number of times specific interfaces have been disabled because of errors",
+		},
+		[]string{
+			"ns_instance",
+			"interface",
+			"alias",
+		},
+	)
+
+	interfacesTxStalls = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "interfaces_transmit_stalls",
+			Help: "Number of times the transmit path has stalled on specific interfaces",
+		},
+		[]string{
+			"ns_instance",
+			"interface",
+			"alias",
+		},
+	)
+
+	interfacesRxStalls = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "interfaces_receive_stalls",
+			Help: "Number of times the receive path has stalled on specific interfaces",
+		},
+		[]string{
+			"ns_instance",
+			"interface",
+			"alias",
+		},
+	)
+
+	interfacesLinkDowns = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "interfaces_link_downs",
+			Help: "Number of times the link of specific interfaces went down",
+		},
+		[]string{
+			"ns_instance",
+			"interface",
+			"alias",
+		},
+	)
+
+	interfacesBandwidthLimitDrops = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "interfaces_bandwidth_limit_dropped_packets",
+			Help: "Number of packets dropped by specific interfaces because the licensed bandwidth was exceeded",
+		},
+		[]string{
+			"ns_instance",
+			"interface",
+			"alias",
+		},
+	)
+
+	interfacesRxBytesRate = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "interfaces_received_bytes_rate",
+			Help: "Rate, in bytes per second, at which bytes are currently being received by specific interfaces",
+		},
+		[]string{
+			"ns_instance",
+			"interface",
+			"alias",
+		},
+	)
+
+	interfacesTxBytesRate = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "interfaces_transmitted_bytes_rate",
+			Help: "Rate, in bytes per second, at which bytes are currently being transmitted by specific interfaces",
+		},
+		[]string{
+			"ns_instance",
+			"interface",
+			"alias",
+		},
+	)
 )
 
-func (e *Exporter) collectInterfacesRxBytes(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectInterfacesRxBytes(interfaces []netscaler.InterfaceStats) {
 	e.interfacesRxBytes.Reset()
 
-	for _, iface := range ns.InterfaceStats {
+	for _, iface := range interfaces {
 		val, _ := strconv.ParseFloat(iface.TotalReceivedBytes, 64)
 		e.interfacesRxBytes.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(val)
 	}
 }
 
-func (e *Exporter) collectInterfacesTxBytes(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectInterfacesTxBytes(interfaces []netscaler.InterfaceStats) {
 	e.interfacesTxBytes.Reset()
 
-	for _, iface := range ns.InterfaceStats {
+	for _, iface := range interfaces {
 		val, _ := strconv.ParseFloat(iface.TotalTransmitBytes, 64)
 		e.interfacesTxBytes.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(val)
 	}
 }
 
-func (e *Exporter) collectInterfacesRxPackets(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectInterfacesRxPackets(interfaces []netscaler.InterfaceStats) {
 	e.interfacesRxPackets.Reset()
 
-	for _, iface := range ns.InterfaceStats {
+	for _, iface := range interfaces {
 		val, _ := strconv.ParseFloat(iface.TotalReceivedPackets, 64)
 		e.interfacesRxPackets.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(val)
 	}
 }
 
-func (e *Exporter) collectInterfacesTxPackets(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectInterfacesTxPackets(interfaces []netscaler.InterfaceStats) {
 	e.interfacesTxPackets.Reset()
 
-	for _, iface := range ns.InterfaceStats {
+	for _, iface := range interfaces {
 		val, _ := strconv.ParseFloat(iface.TotalTransmitPackets, 64)
 		e.interfacesTxPackets.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(val)
 	}
 }
 
-func (e *Exporter) collectInterfacesJumboPacketsRx(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectInterfacesJumboPacketsRx(interfaces []netscaler.InterfaceStats) {
 	e.interfacesJumboPacketsRx.Reset()
 
-	for _, iface := range ns.InterfaceStats {
+	for _, iface := range interfaces {
 		val, _ := strconv.ParseFloat(iface.JumboPacketsReceived, 64)
 		e.interfacesJumboPacketsRx.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(val)
 	}
 }
 
-func (e *Exporter) collectInterfacesJumboPacketsTx(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectInterfacesJumboPacketsTx(interfaces []netscaler.InterfaceStats) {
 	e.interfacesJumboPacketsTx.Reset()
 
-	for _, iface := range ns.InterfaceStats {
+	for _, iface := range interfaces {
 		val, _ := strconv.ParseFloat(iface.JumboPacketsTransmitted, 64)
 		e.interfacesJumboPacketsTx.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(val)
 	}
 }
 
-func (e *Exporter) collectInterfacesErrorPacketsRx(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectInterfacesErrorPacketsRx(interfaces []netscaler.InterfaceStats) {
 	e.interfacesErrorPacketsRx.Reset()
 
-	for _, iface := range ns.InterfaceStats {
+	for _, iface := range interfaces {
 		val, _ := strconv.ParseFloat(iface.ErrorPacketsReceived, 64)
 		e.interfacesErrorPacketsRx.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(val)
 	}
 }
+
+func (e *Exporter) collectInterfacesLinkState(interfaces []netscaler.Interface) {
+	e.interfacesLinkState.Reset()
+
+	for _, iface := range interfaces {
+		state := 0.0
+
+		if iface.LinkState == "1" {
+			state = 1.0
+		}
+
+		e.interfacesLinkState.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(state)
+	}
+}
+
+func (e *Exporter) collectInterfacesSpeed(interfaces []netscaler.Interface) {
+	e.interfacesSpeed.Reset()
+
+	for _, iface := range interfaces {
+		val, _ := strconv.ParseFloat(iface.ActualSpeed, 64)
+		e.interfacesSpeed.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(val)
+	}
+}
+
+func (e *Exporter) collectInterfacesFullDuplex(interfaces []netscaler.Interface) {
+	e.interfacesFullDuplex.Reset()
+
+	for _, iface := range interfaces {
+		state := 0.0
+
+		if iface.ActualDuplex == "FULL" {
+			state = 1.0
+		}
+
+		e.interfacesFullDuplex.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(state)
+	}
+}
+
+func (e *Exporter) collectInterfacesMTU(interfaces []netscaler.Interface) {
+	e.interfacesMTU.Reset()
+
+	for _, iface := range interfaces {
+		val, _ := strconv.ParseFloat(iface.ActualMTU, 64)
+		e.interfacesMTU.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(val)
+	}
+}
+
+func (e *Exporter) collectInterfacesLACPActorInSync(interfaces []netscaler.Interface) {
+	e.interfacesLACPActorInSync.Reset()
+
+	for _, iface := range interfaces {
+		state := 0.0
+
+		if iface.LACPActorInSync == "INSYNC" {
+			state = 1.0
+		}
+
+		e.interfacesLACPActorInSync.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(state)
+	}
+}
+
+func (e *Exporter) collectInterfacesLACPActorCollecting(interfaces []netscaler.Interface) {
+	e.interfacesLACPActorCollecting.Reset()
+
+	for _, iface := range interfaces {
+		state := 0.0
+
+		if iface.LACPActorCollecting == "COLLECTING" {
+			state = 1.0
+		}
+
+		e.interfacesLACPActorCollecting.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(state)
+	}
+}
+
+func (e *Exporter) collectInterfacesLACPActorDistributing(interfaces []netscaler.Interface) {
+	e.interfacesLACPActorDistributing.Reset()
+
+	for _, iface := range interfaces {
+		state := 0.0
+
+		if iface.LACPActorDistributing == "DISTRIBUTING" {
+			state = 1.0
+		}
+
+		e.interfacesLACPActorDistributing.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(state)
+	}
+}
+
+func (e *Exporter) collectInterfacesLACPPartnerInSync(interfaces []netscaler.Interface) {
+	e.interfacesLACPPartnerInSync.Reset()
+
+	for _, iface := range interfaces {
+		state := 0.0
+
+		if iface.LACPPartnerInSync == "INSYNC" {
+			state = 1.0
+		}
+
+		e.interfacesLACPPartnerInSync.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(state)
+	}
+}
+
+func (e *Exporter) collectInterfacesLACPPartnerCollecting(interfaces []netscaler.Interface) {
+	e.interfacesLACPPartnerCollecting.Reset()
+
+	for _, iface := range interfaces {
+		state := 0.0
+
+		if iface.LACPPartnerCollecting == "COLLECTING" {
+			state = 1.0
+		}
+
+		e.interfacesLACPPartnerCollecting.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(state)
+	}
+}
+
+func (e *Exporter) collectInterfacesLACPPartnerDistributing(interfaces []netscaler.Interface) {
+	e.interfacesLACPPartnerDistributing.Reset()
+
+	for _, iface := range interfaces {
+		state := 0.0
+
+		if iface.LACPPartnerDistributing == "DISTRIBUTING" {
+			state = 1.0
+		}
+
+		e.interfacesLACPPartnerDistributing.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(state)
+	}
+}
+
+// collectInterfacesChannel exports the channel each interface is bound to.
+// Static and LACP channels both list their member interfaces; the LACP key is only used for an LACP member whose channel was not listed.
+func (e *Exporter) collectInterfacesChannel(interfaces []netscaler.Interface, channels []netscaler.Channel) {
+	e.interfacesChannel.Reset()
+
+	members := make(map[string]string)
+	for _, channel := range channels {
+		for _, member := range channel.Members {
+			members[member] = channel.ID
+		}
+	}
+
+	for _, iface := range interfaces {
+		channel, ok := members[iface.ID]
+		if !ok {
+			if iface.LACPKey == "" || iface.LACPKey == "0" {
+				continue
+			}
+
+			channel = "LA/" + iface.LACPKey.String()
+		}
+
+		// A channel carries the LACP key of its members, but is not a member of itself.
+		if channel == iface.ID {
+			continue
+		}
+
+		e.interfacesChannel.WithLabelValues(e.nsInstance, iface.ID, iface.Alias, channel).Set(1)
+	}
+}
+
+func (e *Exporter) collectInterfacesErrorPacketsTx(interfaces []netscaler.InterfaceStats) {
+	e.interfacesErrorPacketsTx.Reset()
+
+	for _, iface := range interfaces {
+		val, _ := strconv.ParseFloat(iface.ErrorPacketsTransmitted, 64)
+		e.interfacesErrorPacketsTx.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(val)
+	}
+}
+
+func (e *Exporter) collectInterfacesInboundDiscards(interfaces []netscaler.InterfaceStats) {
+	e.interfacesInboundDiscards.Reset()
+
+	for _, iface := range interfaces {
+		val, _ := strconv.ParseFloat(iface.InboundDiscards, 64)
+		e.interfacesInboundDiscards.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(val)
+	}
+}
+
+func (e *Exporter) collectInterfacesOutboundDiscards(interfaces []netscaler.InterfaceStats) {
+	e.interfacesOutboundDiscards.Reset()
+
+	for _, iface := range interfaces {
+		val, _ := strconv.ParseFloat(iface.OutboundDiscards, 64)
+		e.interfacesOutboundDiscards.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(val)
+	}
+}
+
+func (e *Exporter) collectInterfacesDroppedPacketsRx(interfaces []netscaler.InterfaceStats) {
+	e.interfacesDroppedPacketsRx.Reset()
+
+	for _, iface := range interfaces {
+		val, _ := strconv.ParseFloat(iface.DroppedPacketsReceived, 64)
+		e.interfacesDroppedPacketsRx.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(val)
+	}
+}
+
+func (e *Exporter) collectInterfacesDroppedPacketsTx(interfaces []netscaler.InterfaceStats) {
+	e.interfacesDroppedPacketsTx.Reset()
+
+	for _, iface := range interfaces {
+		val, _ := strconv.ParseFloat(iface.DroppedPacketsTransmitted, 64)
+		e.interfacesDroppedPacketsTx.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(val)
+	}
+}
+
+func (e *Exporter) collectInterfacesCRCErrorsRx(interfaces []netscaler.InterfaceStats) {
+	e.interfacesCRCErrorsRx.Reset()
+
+	for _, iface := range interfaces {
+		val, _ := strconv.ParseFloat(iface.CRCErrorsReceived, 64)
+		e.interfacesCRCErrorsRx.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(val)
+	}
+}
+
+func (e *Exporter) collectInterfacesLinkHangs(interfaces []netscaler.InterfaceStats) {
+	e.interfacesLinkHangs.Reset()
+
+	for _, iface := range interfaces {
+		val, _ := strconv.ParseFloat(iface.LinkHangs, 64)
+		e.interfacesLinkHangs.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(val)
+	}
+}
+
+func (e *Exporter) collectInterfacesLinkReinitialisations(interfaces []netscaler.InterfaceStats) {
+	e.interfacesLinkReinitialisations.Reset()
+
+	for _, iface := range interfaces {
+		val, _ := strconv.ParseFloat(iface.LinkReinitialisations, 64)
+		e.interfacesLinkReinitialisations.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(val)
+	}
+}
+
+func (e *Exporter) collectInterfacesErrorDisables(interfaces []netscaler.InterfaceStats) {
+	e.interfacesErrorDisables.Reset()
+
+	for _, iface := range interfaces {
+		val, _ := strconv.ParseFloat(iface.ErrorDisables, 64)
+		e.interfacesErrorDisables.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(val)
+	}
+}
+
+func (e *Exporter) collectInterfacesTxStalls(interfaces []netscaler.InterfaceStats) {
+	e.interfacesTxStalls.Reset()
+
+	for _, iface := range interfaces {
+		val, _ := strconv.ParseFloat(iface.TransmitStalls, 64)
+		e.interfacesTxStalls.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(val)
+	}
+}
+
+func (e *Exporter) collectInterfacesRxStalls(interfaces []netscaler.InterfaceStats) {
+	e.interfacesRxStalls.Reset()
+
+	for _, iface := range interfaces {
+		val, _ := strconv.ParseFloat(iface.ReceiveStalls, 64)
+		e.interfacesRxStalls.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(val)
+	}
+}
+
+func (e *Exporter) collectInterfacesLinkDowns(interfaces []netscaler.InterfaceStats) {
+	e.interfacesLinkDowns.Reset()
+
+	for _, iface := range interfaces {
+		val, _ := strconv.ParseFloat(iface.LinkDowns, 64)
+		e.interfacesLinkDowns.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(val)
+	}
+}
+
+func (e *Exporter) collectInterfacesBandwidthLimitDrops(interfaces []netscaler.InterfaceStats) {
+	e.interfacesBandwidthLimitDrops.Reset()
+
+	for _, iface := range interfaces {
+		val, _ := strconv.ParseFloat(iface.BandwidthLimitDrops, 64)
+		e.interfacesBandwidthLimitDrops.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(val)
+	}
+}
+
+func (e *Exporter) collectInterfacesRxBytesRate(interfaces []netscaler.InterfaceStats) {
+	e.interfacesRxBytesRate.Reset()
+
+	for _, iface := range interfaces {
+		e.interfacesRxBytesRate.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(iface.ReceivedBytesRate)
+	}
+}
+
+func (e *Exporter) collectInterfacesTxBytesRate(interfaces []netscaler.InterfaceStats) {
+	e.interfacesTxBytesRate.Reset()
+
+	for _, iface := range interfaces {
+		e.interfacesTxBytesRate.WithLabelValues(e.nsInstance, iface.ID, iface.Alias).Set(iface.TransmitBytesRate)
+	}
+}
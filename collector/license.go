@@ -0,0 +1,313 @@
+package collector
+
+import (
+	"bufio"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rokett/citrix-netscaler-exporter/netscaler"
+
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	licenseFeatureEnabled = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "license_feature_enabled",
+			Help: "Whether the feature is licensed; 1 = licensed, 0 = not licensed",
+		},
+		[]string{
+			"ns_instance",
+			"feature",
+		},
+	)
+
+	licenseEdition = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "license_edition",
+			Help: "Edition of the NetScaler license; always 1",
+		},
+		[]string{
+			"ns_instance",
+			"edition",
+		},
+	)
+
+	licenseDaysToExpiration = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "license_days_to_expiration",
+			Help: "Number of days until the NetScaler license expires",
+		},
+		[]string{
+			"ns_instance",
+			"licensing_mode",
+		},
+	)
+
+	licenseFileExpiryTimestamp = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "license_file_expiry_timestamp_seconds",
+			Help: "Unix time at which the feature in the license file expires; permanent licenses are not exported",
+		},
+		[]string{
+			"ns_instance",
+			"file",
+			"feature",
+		},
+	)
+
+	licenseFileDaysToExpiration = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "license_file_days_to_expiration",
+			Help: "Number of days until the feature in the license file expires; permanent licenses are not exported",
+		},
+		[]string{
+			"ns_instance",
+			"file",
+			"feature",
+		},
+	)
+
+	licenseCapacityBandwidth = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "license_capacity_bandwidth_mbps",
+			Help: "Licensed bandwidth allocated from the pooled capacity license server, in Mbps",
+		},
+		[]string{
+			"ns_instance",
+			"edition",
+		},
+	)
+
+	licenseCapacityVCPUs = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "license_capacity_vcpus",
+			Help: "Number of vCPUs licensed from the pooled capacity license server",
+		},
+		[]string{
+			"ns_instance",
+			"edition",
+		},
+	)
+)
+
+// licenseFileLocation is the directory on the NetScaler which holds license files.
+const licenseFileLocation = "/nsconfig/license"
+
+// licenseFileCacheTTL is how long the expiry dates read from a NetScaler's license files are reused before the files are read again.
+// License files rarely change, and reading them takes a request per file.
+const licenseFileCacheTTL = time.Hour
+
+var (
+	// Keyed by NetScaler instance.
+	licenseFileCache   = make(map[string]licenseFileCacheEntry)
+	licenseFileCacheMu sync.Mutex
+)
+
+type licenseFileCacheEntry struct {
+	expiries []licenseExpiry
+	read     time.Time
+}
+
+type licenseExpiry struct {
+	file    string
+	feature string
+	expires time.Time
+}
+
+// licenseFileExpiries parses the INCREMENT and FEATURE lines of a FlexLM license file, returning the expiry date of each licensed feature.
+// Where a feature appears more than once, the latest expiry is kept.
+// Permanent licenses have no expiry and are skipped.
+func licenseFileExpiries(file string, content string) []licenseExpiry {
+	var expiries []licenseExpiry
+
+	seen := make(map[string]int)
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+
+		if len(fields) < 5 || (fields[0] != "INCREMENT" && fields[0] != "FEATURE") {
+			continue
+		}
+
+		// FlexLM dates look like 12-may-2024; time.Parse matches month names case-insensitively.
+		expires, err := time.Parse("2-Jan-2006", fields[4])
+		if err != nil {
+			continue
+		}
+
+		feature := fields[1]
+
+		if i, ok := seen[feature]; ok {
+			if expires.After(expiries[i].expires) {
+				expiries[i].expires = expires
+			}
+			continue
+		}
+
+		seen[feature] = len(expiries)
+		expiries = append(expiries, licenseExpiry{
+			file:    file,
+			feature: feature,
+			expires: expires,
+		})
+	}
+
+	return expiries
+}
+
+// licenseFileExpiriesCached returns the expiry dates from the license files of the NetScaler, reading the files only if they were not read within licenseFileCacheTTL.
+// Failures are not cached, so the files are read again on the next scrape.
+func (e *Exporter) licenseFileExpiriesCached(nsClient *netscaler.NitroClient) []licenseExpiry {
+	licenseFileCacheMu.Lock()
+	cached, ok := licenseFileCache[e.nsInstance]
+	licenseFileCacheMu.Unlock()
+
+	if ok && time.Since(cached.read) < licenseFileCacheTTL {
+		return cached.expiries
+	}
+
+	expiries, err := e.readLicenseFileExpiries(nsClient)
+
+	licenseFileCacheMu.Lock()
+	defer licenseFileCacheMu.Unlock()
+
+	now := time.Now()
+
+	// Entries are only replaced when their target is scraped, so expired entries are removed here to forget targets which are no longer scraped.
+	for instance, entry := range licenseFileCache {
+		if now.Sub(entry.read) >= licenseFileCacheTTL {
+			delete(licenseFileCache, instance)
+		}
+	}
+
+	if err == nil {
+		licenseFileCache[e.nsInstance] = licenseFileCacheEntry{expiries: expiries, read: now}
+	}
+
+	return expiries
+}
+
+// readLicenseFileExpiries reads every license file on the NetScaler, returning the expiry dates of the licensed features.
+// An error is returned if any file could not be read, along with the expiry dates from the files which could.
+func (e *Exporter) readLicenseFileExpiries(nsClient *netscaler.NitroClient) ([]licenseExpiry, error) {
+	licenseFiles, err := netscaler.GetSystemFiles(nsClient, netscaler.Query{Args: map[string]string{"filelocation": licenseFileLocation}})
+	if err != nil {
+		e.logAPIError(err)
+		return nil, err
+	}
+
+	var expiries []licenseExpiry
+
+	for _, f := range licenseFiles {
+		if !strings.HasSuffix(f.FileName, ".lic") {
+			continue
+		}
+
+		file, err2 := netscaler.GetSystemFiles(nsClient, netscaler.Query{Args: map[string]string{"filename": f.FileName, "filelocation": licenseFileLocation}})
+		if err2 != nil {
+			e.logAPIError(err2)
+			err = err2
+			continue
+		}
+
+		if len(file) == 0 {
+			continue
+		}
+
+		content, err2 := base64.StdEncoding.DecodeString(file[0].FileContent)
+		if err2 != nil {
+			err = errors.Wrap(err2, "error decoding license file "+f.FileName)
+			level.Error(e.logger).Log("msg", err)
+			continue
+		}
+
+		expiries = append(expiries, licenseFileExpiries(f.FileName, string(content))...)
+	}
+
+	return expiries, err
+}
+
+func (e *Exporter) collectLicenseFeatureEnabled(license netscaler.NSLicense) {
+	e.licenseFeatureEnabled.Reset()
+
+	for feature, enabled := range license.Features {
+		val := 0.0
+		if enabled {
+			val = 1.0
+		}
+
+		e.licenseFeatureEnabled.WithLabelValues(e.nsInstance, feature).Set(val)
+	}
+}
+
+func (e *Exporter) collectLicenseEdition(license netscaler.NSLicense) {
+	e.licenseEdition.Reset()
+
+	if license.Edition == "" {
+		return
+	}
+
+	e.licenseEdition.WithLabelValues(e.nsInstance, license.Edition).Set(1)
+}
+
+func (e *Exporter) collectLicenseDaysToExpiration(license netscaler.NSLicense) {
+	e.licenseDaysToExpiration.Reset()
+
+	// Perpetual licenses do not report an expiry.
+	if license.DaysToExpiration == "" {
+		return
+	}
+
+	val, _ := strconv.ParseFloat(license.DaysToExpiration, 64)
+	e.licenseDaysToExpiration.WithLabelValues(e.nsInstance, license.LicensingMode).Set(val)
+}
+
+func (e *Exporter) collectLicenseFileExpiryTimestamp(expiries []licenseExpiry) {
+	e.licenseFileExpiryTimestamp.Reset()
+
+	for _, l := range expiries {
+		e.licenseFileExpiryTimestamp.WithLabelValues(e.nsInstance, l.file, l.feature).Set(float64(l.expires.Unix()))
+	}
+}
+
+func (e *Exporter) collectLicenseFileDaysToExpiration(expiries []licenseExpiry) {
+	e.licenseFileDaysToExpiration.Reset()
+
+	for _, l := range expiries {
+		e.licenseFileDaysToExpiration.WithLabelValues(e.nsInstance, l.file, l.feature).Set(time.Until(l.expires).Hours() / 24)
+	}
+}
+
+func (e *Exporter) collectLicenseCapacityBandwidth(capacity netscaler.NSCapacity) {
+	e.licenseCapacityBandwidth.Reset()
+
+	// Appliances which are not using pooled capacity licensing report no edition.
+	if capacity.Edition == "" {
+		return
+	}
+
+	val, _ := strconv.ParseFloat(capacity.ActualBandwidth, 64)
+	if capacity.Unit == "Gbps" {
+		val = val * 1000
+	}
+
+	e.licenseCapacityBandwidth.WithLabelValues(e.nsInstance, capacity.Edition).Set(val)
+}
+
+func (e *Exporter) collectLicenseCapacityVCPUs(capacity netscaler.NSCapacity) {
+	e.licenseCapacityVCPUs.Reset()
+
+	if capacity.Edition == "" {
+		return
+	}
+
+	val, _ := strconv.ParseFloat(capacity.VCPUCount, 64)
+	e.licenseCapacityVCPUs.WithLabelValues(e.nsInstance, capacity.Edition).Set(val)
+}
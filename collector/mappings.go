@@ -0,0 +1,145 @@
+package collector
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/rokett/citrix-netscaler-exporter/config"
+	"github.com/rokett/citrix-netscaler-exporter/netscaler"
+
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// mappingDesc is a metric declared in the configuration file, along with the Desc built from it.
+type mappingDesc struct {
+	metric    config.Metric
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+}
+
+// newMappingDescs builds a Desc for every metric declared in the mappings.
+// The returned slice is indexed the same as mappings.
+func newMappingDescs(mappings []config.Mapping) [][]mappingDesc {
+	descs := make([][]mappingDesc, len(mappings))
+
+	for i, m := range mappings {
+		labels := []string{"ns_instance"}
+		for _, l := range m.Labels {
+			labels = append(labels, l.Name)
+		}
+
+		for _, metric := range m.Metrics {
+			help := metric.Help
+			if help == "" {
+				help = "Value of " + metric.Field + " from Nitro " + m.Resource
+			}
+
+			valueType := prometheus.GaugeValue
+			if metric.Type == "counter" {
+				valueType = prometheus.CounterValue
+			}
+
+			descs[i] = append(descs[i], mappingDesc{
+				metric:    metric,
+				desc:      prometheus.NewDesc(metric.Name, help, labels, nil),
+				valueType: valueType,
+			})
+		}
+	}
+
+	return descs
+}
+
+func (e *Exporter) collectMappings(nsClient *netscaler.NitroClient, ch chan<- prometheus.Metric) {
+	for i, m := range e.mappings {
+		kind := strings.SplitN(m.Resource, "/", 2)
+		q := netscaler.Query{Filter: m.Filter}
+
+		var items []map[string]interface{}
+		var err error
+
+		if kind[0] == "stat" {
+			items, err = netscaler.GetStat[map[string]interface{}](nsClient, kind[1], q)
+		} else {
+			items, err = netscaler.GetConfigList[map[string]interface{}](nsClient, kind[1], q)
+		}
+		if err != nil {
+			e.logAPIError(err)
+			continue
+		}
+
+		// Items whose label fields are the same would give duplicate series, which fail the whole scrape, so only the first is exported.
+		seen := make(map[string]bool, len(items))
+		duplicates := 0
+
+		for _, item := range items {
+			labelValues := []string{e.nsInstance}
+			for _, l := range m.Labels {
+				labelValues = append(labelValues, mappingLabelValue(item[l.Field]))
+			}
+
+			key := strings.Join(labelValues, "\xff")
+			if seen[key] {
+				duplicates++
+				continue
+			}
+			seen[key] = true
+
+			for _, d := range e.mappingDescs[i] {
+				val, ok := mappingValue(item[d.metric.Field], d.metric.ValueMap)
+				if !ok {
+					level.Debug(e.logger).Log("msg", "unable to convert field to a metric value", "resource", m.Resource, "field", d.metric.Field, "value", item[d.metric.Field])
+					continue
+				}
+
+				ch <- prometheus.MustNewConstMetric(d.desc, d.valueType, val, labelValues...)
+			}
+		}
+
+		if duplicates > 0 {
+			level.Warn(e.logger).Log("msg", "mapping returned items with the same labels; only the first is exported, so add a label which tells them apart", "resource", m.Resource, "duplicates", duplicates)
+		}
+	}
+}
+
+// mappingValue converts a field from a Nitro response into a metric value.
+// Nitro returns counters as strings and rates as numbers; strings are looked up in the value map first.
+func mappingValue(v interface{}, valueMap map[string]float64) (float64, bool) {
+	switch val := v.(type) {
+	case float64:
+		return val, true
+	case bool:
+		if val {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		if mapped, ok := valueMap[val]; ok {
+			return mapped, true
+		}
+
+		f, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return 0, false
+		}
+
+		return f, true
+	}
+
+	return 0, false
+}
+
+// mappingLabelValue converts a field from a Nitro response into a label value.
+func mappingLabelValue(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	}
+
+	return ""
+}
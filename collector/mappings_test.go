@@ -0,0 +1,118 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rokett/citrix-netscaler-exporter/config"
+	"github.com/rokett/citrix-netscaler-exporter/netscaler/nitrotest"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestMappingValue(t *testing.T) {
+	valueMap := map[string]float64{"UP": 1, "DOWN": 0}
+
+	tests := []struct {
+		name   string
+		value  interface{}
+		want   float64
+		wantOK bool
+	}{
+		{name: "number", value: 4.5, want: 4.5, wantOK: true},
+		{name: "counter string", value: "1234", want: 1234, wantOK: true},
+		{name: "true", value: true, want: 1, wantOK: true},
+		{name: "false", value: false, want: 0, wantOK: true},
+		{name: "mapped", value: "UP", want: 1, wantOK: true},
+		{name: "mapped to zero", value: "DOWN", want: 0, wantOK: true},
+		{name: "not mapped", value: "OUT OF SERVICE", wantOK: false},
+		{name: "missing", value: nil, wantOK: false},
+		{name: "object", value: map[string]interface{}{"a": 1.0}, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := mappingValue(tt.value, valueMap)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("mappingValue(%v) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestMappingLabelValue(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{value: "vs_web", want: "vs_web"},
+		{value: 8080.0, want: "8080"},
+		{value: 0.5, want: "0.5"},
+		{value: true, want: "true"},
+		{value: nil, want: ""},
+		{value: []interface{}{"a"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		got := mappingLabelValue(tt.value)
+		if got != tt.want {
+			t.Errorf("mappingLabelValue(%v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestMappings(t *testing.T) {
+	srv, err := nitrotest.NewServerWithFixtures(nitrotest.Fixtures())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	// vs_web is returned twice, as if the label fields did not tell the items apart.
+	srv.SetFixture(nitrotest.Key("stat/sslvserver", "filter=type:SSL"), []byte(`{"errorcode":0,"message":"Done","sslvserver":[
+		{"vservername":"vs_web","port":443,"state":"UP","sslctxtotsessionnew":"120"},
+		{"vservername":"vs_api","port":8443,"state":"DOWN","sslctxtotsessionnew":"30"},
+		{"vservername":"vs_web","port":443,"state":"UP","sslctxtotsessionnew":"999"},
+		{"vservername":"vs_old","port":443,"state":"OUT OF SERVICE"}
+	]}`))
+
+	mappings := []config.Mapping{
+		{
+			Resource: "stat/sslvserver",
+			Filter:   map[string]string{"type": "SSL"},
+			Labels: []config.Label{
+				{Name: "virtual_server", Field: "vservername"},
+				{Name: "port", Field: "port"},
+			},
+			Metrics: []config.Metric{
+				{Name: "ssl_vserver_up", Field: "state", ValueMap: map[string]float64{"UP": 1, "DOWN": 0}},
+				{Name: "ssl_vserver_handshakes_total", Field: "sslctxtotsessionnew", Type: "counter"},
+			},
+		},
+	}
+
+	e, err := NewExporter(srv.URL, "user", "pass", false, log.NewNopLogger(), "mappings", Options{Mappings: mappings})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// gather fails the test if the duplicate items give duplicate series.
+	got := string(gather(t, e))
+
+	for _, want := range []string{
+		"# TYPE ssl_vserver_handshakes_total counter",
+		`ssl_vserver_up{ns_instance="mappings",port="443",virtual_server="vs_web"} 1`,
+		`ssl_vserver_up{ns_instance="mappings",port="8443",virtual_server="vs_api"} 0`,
+		`ssl_vserver_handshakes_total{ns_instance="mappings",port="443",virtual_server="vs_web"} 120`,
+		`ssl_vserver_handshakes_total{ns_instance="mappings",port="8443",virtual_server="vs_api"} 30`,
+	} {
+		if !strings.Contains(got, want+"\n") {
+			t.Errorf("missing %s", want)
+		}
+	}
+
+	// Values which cannot be converted are skipped.
+	if strings.Contains(got, "vs_old") {
+		t.Error("exported a metric for a value which is not in the value map")
+	}
+}
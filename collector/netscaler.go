@@ -1,8 +1,46 @@
 package collector
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"regexp"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
 
 var (
+	nsVersionInfo = prometheus.NewDesc(
+		"ns_version_info",
+		"NetScaler firmware version and hardware details; always 1",
+		[]string{
+			"ns_instance",
+			"version",
+			"build",
+			"platform",
+			"serial",
+			"host_id",
+			"hostname",
+		},
+		nil,
+	)
+
+	bootTime = prometheus.NewDesc(
+		"boot_time_seconds",
+		"Unix time at which the NetScaler was last started",
+		[]string{
+			"ns_instance",
+		},
+		nil,
+	)
+
+	uptime = prometheus.NewDesc(
+		"uptime_seconds",
+		"Number of seconds since the NetScaler was last started",
+		[]string{
+			"ns_instance",
+		},
+		nil,
+	)
+
 	modelID = prometheus.NewDesc(
 		"model_id",
 		"NetScaler model - reflects the bandwidth available; for example VPX 10 would report as 10.",
@@ -129,3 +167,22 @@ var (
 		nil,
 	)
 )
+
+// nsVersionRegex extracts the release and build from a version string such as "NetScaler NS13.1: Build 49.13.nc, Date: Jul 11 2023, 10:32:10   (64-bit)".
+var nsVersionRegex = regexp.MustCompile(`NS(\d+\.\d+): Build (\d+\.\d+)`)
+
+// parseNSVersion returns the release and build from the Nitro version string.
+// If the string is not in the expected format, the whole string is returned as the version.
+func parseNSVersion(v string) (string, string) {
+	m := nsVersionRegex.FindStringSubmatch(v)
+	if m == nil {
+		return v, ""
+	}
+
+	return m[1], m[2]
+}
+
+// parseNSStartTime parses the starttime returned by /stat/ns, which is in GMT; for example "Tue Feb 14 10:16:37 2023".
+func parseNSStartTime(t string) (time.Time, error) {
+	return time.Parse(time.ANSIC, t)
+}
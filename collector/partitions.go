@@ -0,0 +1,169 @@
+package collector
+
+import (
+	"github.com/rokett/citrix-netscaler-exporter/netscaler"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	partitionBandwidth = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "partition_bandwidth_kbps",
+			Help: "Current bandwidth used by the admin partition, in Kbps",
+		},
+		[]string{
+			"ns_instance",
+			"partition",
+		},
+	)
+
+	partitionMaxBandwidth = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "partition_max_bandwidth_kbps",
+			Help: "Bandwidth limit of the admin partition, in Kbps; 0 = no limit",
+		},
+		[]string{
+			"ns_instance",
+			"partition",
+		},
+	)
+
+	partitionConnections = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "partition_connections",
+			Help: "Current number of connections in the admin partition",
+		},
+		[]string{
+			"ns_instance",
+			"partition",
+		},
+	)
+
+	partitionMaxConnections = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "partition_max_connections",
+			Help: "Connection limit of the admin partition; 0 = no limit",
+		},
+		[]string{
+			"ns_instance",
+			"partition",
+		},
+	)
+
+	partitionMemoryUsage = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "partition_memory_usage_percent",
+			Help: "Percentage of the admin partition's memory limit in use",
+		},
+		[]string{
+			"ns_instance",
+			"partition",
+		},
+	)
+
+	partitionMaxMemory = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "partition_max_memory_mb",
+			Help: "Memory limit of the admin partition, in MB; 0 = no limit",
+		},
+		[]string{
+			"ns_instance",
+			"partition",
+		},
+	)
+)
+
+// partitionNames returns the admin partitions to collect from, in the order they should be collected.
+// If no partitions were asked for, a single empty name is returned, meaning the session's partition is not switched and metrics get a partition label of default.
+// The name all is expanded to every partition on the NetScaler, including the default partition.
+func (e *Exporter) partitionNames(nsClient *netscaler.NitroClient) []string {
+	if len(e.partitions) == 0 {
+		return []string{""}
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	for _, p := range e.partitions {
+		if p != "all" {
+			add(p)
+			continue
+		}
+
+		add("default")
+
+		partitions, err := netscaler.GetNSPartitions(nsClient, netscaler.Query{Attrs: []string{"partitionname"}})
+		if err != nil {
+			e.logAPIError(err)
+			continue
+		}
+
+		for _, np := range partitions {
+			add(np.Name)
+		}
+	}
+
+	return names
+}
+
+func (e *Exporter) collectPartitionBandwidth(partitions []netscaler.NSPartitionStats) {
+	e.partitionBandwidth.Reset()
+
+	for _, p := range partitions {
+		val, _ := p.CurrentBandwidth.Float64()
+		e.partitionBandwidth.WithLabelValues(e.nsInstance, p.Name).Set(val)
+	}
+}
+
+func (e *Exporter) collectPartitionMaxBandwidth(partitions []netscaler.NSPartitionStats) {
+	e.partitionMaxBandwidth.Reset()
+
+	for _, p := range partitions {
+		val, _ := p.MaxBandwidth.Float64()
+		e.partitionMaxBandwidth.WithLabelValues(e.nsInstance, p.Name).Set(val)
+	}
+}
+
+func (e *Exporter) collectPartitionConnections(partitions []netscaler.NSPartitionStats) {
+	e.partitionConnections.Reset()
+
+	for _, p := range partitions {
+		val, _ := p.CurrentConnections.Float64()
+		e.partitionConnections.WithLabelValues(e.nsInstance, p.Name).Set(val)
+	}
+}
+
+func (e *Exporter) collectPartitionMaxConnections(partitions []netscaler.NSPartitionStats) {
+	e.partitionMaxConnections.Reset()
+
+	for _, p := range partitions {
+		val, _ := p.MaxConnections.Float64()
+		e.partitionMaxConnections.WithLabelValues(e.nsInstance, p.Name).Set(val)
+	}
+}
+
+func (e *Exporter) collectPartitionMemoryUsage(partitions []netscaler.NSPartitionStats) {
+	e.partitionMemoryUsage.Reset()
+
+	for _, p := range partitions {
+		val, _ := p.MemoryUsagePercent.Float64()
+		e.partitionMemoryUsage.WithLabelValues(e.nsInstance, p.Name).Set(val)
+	}
+}
+
+func (e *Exporter) collectPartitionMaxMemory(partitions []netscaler.NSPartitionStats) {
+	e.partitionMaxMemory.Reset()
+
+	for _, p := range partitions {
+		val, _ := p.MaxMemory.Float64()
+		e.partitionMaxMemory.WithLabelValues(e.nsInstance, p.Name).Set(val)
+	}
+}
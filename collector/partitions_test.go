@@ -0,0 +1,82 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rokett/citrix-netscaler-exporter/netscaler/nitrotest"
+
+	"github.com/go-kit/kit/log"
+)
+
+// partitionFixtures sets a virtual server in the default partition, in traffic domain 10, and another in partition tenant1.
+func partitionFixtures(srv *nitrotest.Server) {
+	srv.SetFixture("config/nspartition", []byte(`{"errorcode":0,"message":"Done","nspartition":[{"partitionname":"default"},{"partitionname":"tenant1"}]}`))
+	srv.SetFixture("stat/lbvserver", []byte(`{"errorcode":0,"message":"Done","lbvserver":[{"name":"vs_default","state":"UP"}]}`))
+	srv.SetFixture("config/lbvserver", []byte(`{"errorcode":0,"message":"Done","lbvserver":[{"name":"vs_default","td":10}]}`))
+	srv.SetFixture(nitrotest.PartitionKey("tenant1", "stat/lbvserver"), []byte(`{"errorcode":0,"message":"Done","lbvserver":[{"name":"vs_tenant","state":"UP"}]}`))
+}
+
+func TestPartitions(t *testing.T) {
+	srv := nitrotest.NewServer()
+	defer srv.Close()
+
+	partitionFixtures(srv)
+
+	e, err := NewExporter(srv.URL, "user", "pass", false, log.NewNopLogger(), "partitions", Options{TrafficDomains: true, Partitions: []string{"all"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for scrape := 1; scrape <= 2; scrape++ {
+		got := string(gather(t, e))
+
+		for _, want := range []string{
+			`virtual_servers_state{ns_instance="partitions",partition="default",td="10",virtual_server="vs_default"} 1`,
+			`virtual_servers_state{ns_instance="partitions",partition="tenant1",td="0",virtual_server="vs_tenant"} 1`,
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("scrape %d is missing %s", scrape, want)
+			}
+		}
+
+		// Traffic domains are only looked up in the default partition.
+		key := nitrotest.Key("config/lbvserver", "attrs=name,td,ipv46,port&pagesize=1000&pageno=1")
+		if n := srv.Requests(key); n != scrape {
+			t.Errorf("after scrape %d, Requests(%q) = %d, want %d", scrape, key, n, scrape)
+		}
+
+		// The session is switched into tenant1, and back to default before it logs out.
+		key = nitrotest.Key("config/nspartition", "action=Switch")
+		if n := srv.Requests(key); n != 3*scrape {
+			t.Errorf("after scrape %d, Requests(%q) = %d, want %d", scrape, key, n, 3*scrape)
+		}
+	}
+}
+
+func TestPartitionsNotCollected(t *testing.T) {
+	srv := nitrotest.NewServer()
+	defer srv.Close()
+
+	partitionFixtures(srv)
+
+	e, err := NewExporter(srv.URL, "user", "pass", false, log.NewNopLogger(), "no-partitions", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := string(gather(t, e))
+
+	want := `virtual_servers_state{ns_instance="no-partitions",partition="default",td="",virtual_server="vs_default"} 1`
+	if !strings.Contains(got, want) {
+		t.Errorf("metrics are missing %s", want)
+	}
+
+	if strings.Contains(got, "vs_tenant") || strings.Contains(got, "partition_connections") {
+		t.Error("metrics include partition tenant1, which was not asked for")
+	}
+
+	if n := srv.Requests(nitrotest.Key("config/nspartition", "action=Switch")); n != 0 {
+		t.Errorf("switched partition %d times, want 0", n)
+	}
+}
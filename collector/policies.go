@@ -0,0 +1,188 @@
+package collector
+
+import (
+	"strconv"
+
+	"github.com/rokett/citrix-netscaler-exporter/netscaler"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	responderPoliciesHits = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "responder_policies_hits",
+			Help: "Number of hits on the responder policy",
+		},
+		[]string{
+			"ns_instance",
+			"policy",
+		},
+	)
+
+	responderPoliciesUndefinedHits = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "responder_policies_undefined_hits",
+			Help: "Number of undefined hits on the responder policy",
+		},
+		[]string{
+			"ns_instance",
+			"policy",
+		},
+	)
+
+	rewritePoliciesHits = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "rewrite_policies_hits",
+			Help: "Number of hits on the rewrite policy",
+		},
+		[]string{
+			"ns_instance",
+			"policy",
+		},
+	)
+
+	rewritePoliciesUndefinedHits = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "rewrite_policies_undefined_hits",
+			Help: "Number of undefined hits on the rewrite policy",
+		},
+		[]string{
+			"ns_instance",
+			"policy",
+		},
+	)
+
+	csPoliciesHits = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "cs_policies_hits",
+			Help: "Number of hits on the Content Switching policy",
+		},
+		[]string{
+			"ns_instance",
+			"policy",
+		},
+	)
+
+	authenticationPoliciesHits = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "authentication_policies_hits",
+			Help: "Number of hits on the authentication policy",
+		},
+		[]string{
+			"ns_instance",
+			"policy",
+		},
+	)
+
+	authenticationPoliciesUndefinedHits = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "authentication_policies_undefined_hits",
+			Help: "Number of undefined hits on the authentication policy",
+		},
+		[]string{
+			"ns_instance",
+			"policy",
+		},
+	)
+)
+
+// policyIncluded reports whether the policy should be exported, based on the optional policy name filter.
+func (e *Exporter) policyIncluded(name string) bool {
+	if e.policyFilter == nil {
+		return true
+	}
+
+	return e.policyFilter.MatchString(name)
+}
+
+func (e *Exporter) collectResponderPoliciesHits(policies []netscaler.ResponderPolicyStats) {
+	e.responderPoliciesHits.Reset()
+
+	for _, p := range policies {
+		if !e.policyIncluded(p.Name) {
+			continue
+		}
+
+		hits, _ := strconv.ParseFloat(p.Hits, 64)
+		e.responderPoliciesHits.WithLabelValues(e.nsInstance, p.Name).Set(hits)
+	}
+}
+
+func (e *Exporter) collectResponderPoliciesUndefinedHits(policies []netscaler.ResponderPolicyStats) {
+	e.responderPoliciesUndefinedHits.Reset()
+
+	for _, p := range policies {
+		if !e.policyIncluded(p.Name) {
+			continue
+		}
+
+		undefinedHits, _ := strconv.ParseFloat(p.UndefinedHits, 64)
+		e.responderPoliciesUndefinedHits.WithLabelValues(e.nsInstance, p.Name).Set(undefinedHits)
+	}
+}
+
+func (e *Exporter) collectRewritePoliciesHits(policies []netscaler.RewritePolicyStats) {
+	e.rewritePoliciesHits.Reset()
+
+	for _, p := range policies {
+		if !e.policyIncluded(p.Name) {
+			continue
+		}
+
+		hits, _ := strconv.ParseFloat(p.Hits, 64)
+		e.rewritePoliciesHits.WithLabelValues(e.nsInstance, p.Name).Set(hits)
+	}
+}
+
+func (e *Exporter) collectRewritePoliciesUndefinedHits(policies []netscaler.RewritePolicyStats) {
+	e.rewritePoliciesUndefinedHits.Reset()
+
+	for _, p := range policies {
+		if !e.policyIncluded(p.Name) {
+			continue
+		}
+
+		undefinedHits, _ := strconv.ParseFloat(p.UndefinedHits, 64)
+		e.rewritePoliciesUndefinedHits.WithLabelValues(e.nsInstance, p.Name).Set(undefinedHits)
+	}
+}
+
+func (e *Exporter) collectCSPoliciesHits(policies []netscaler.CSPolicyStats) {
+	e.csPoliciesHits.Reset()
+
+	for _, p := range policies {
+		if !e.policyIncluded(p.Name) {
+			continue
+		}
+
+		hits, _ := strconv.ParseFloat(p.Hits, 64)
+		e.csPoliciesHits.WithLabelValues(e.nsInstance, p.Name).Set(hits)
+	}
+}
+
+func (e *Exporter) collectAuthenticationPoliciesHits(policies []netscaler.AuthenticationPolicyStats) {
+	e.authenticationPoliciesHits.Reset()
+
+	for _, p := range policies {
+		if !e.policyIncluded(p.Name) {
+			continue
+		}
+
+		hits, _ := strconv.ParseFloat(p.Hits, 64)
+		e.authenticationPoliciesHits.WithLabelValues(e.nsInstance, p.Name).Set(hits)
+	}
+}
+
+func (e *Exporter) collectAuthenticationPoliciesUndefinedHits(policies []netscaler.AuthenticationPolicyStats) {
+	e.authenticationPoliciesUndefinedHits.Reset()
+
+	for _, p := range policies {
+		if !e.policyIncluded(p.Name) {
+			continue
+		}
+
+		undefinedHits, _ := strconv.ParseFloat(p.UndefinedHits, 64)
+		e.authenticationPoliciesUndefinedHits.WithLabelValues(e.nsInstance, p.Name).Set(undefinedHits)
+	}
+}
@@ -0,0 +1,396 @@
+package collector
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/rokett/citrix-netscaler-exporter/netscaler"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	sdxVPXUp = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "sdx_vpx_up",
+			Help: "Whether the Management Service reports the VPX instance as up; 1 = Up, 0 = anything else",
+		},
+		[]string{
+			"ns_instance",
+			"vpx",
+		},
+	)
+
+	sdxVPXInfo = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "sdx_vpx_info",
+			Help: "Details of each VPX instance on the SDX appliance; always 1",
+		},
+		[]string{
+			"ns_instance",
+			"vpx",
+			"ip_address",
+			"hostname",
+			"vm_state",
+			"instance_state",
+			"ha_master_state",
+			"version",
+		},
+	)
+
+	sdxVPXCores = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "sdx_vpx_cpu_cores",
+			Help: "Number of CPU cores assigned to the VPX instance",
+		},
+		[]string{
+			"ns_instance",
+			"vpx",
+		},
+	)
+
+	sdxVPXSSLCores = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "sdx_vpx_ssl_cores",
+			Help: "Number of SSL cores assigned to the VPX instance",
+		},
+		[]string{
+			"ns_instance",
+			"vpx",
+		},
+	)
+
+	sdxVPXMemory = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "sdx_vpx_memory_mb",
+			Help: "Memory assigned to the VPX instance, in MB",
+		},
+		[]string{
+			"ns_instance",
+			"vpx",
+		},
+	)
+
+	sdxVPXThroughput = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "sdx_vpx_throughput_mbps",
+			Help: "Throughput assigned to the VPX instance, in Mbps",
+		},
+		[]string{
+			"ns_instance",
+			"vpx",
+		},
+	)
+
+	sdxVPXPPS = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "sdx_vpx_packets_per_second",
+			Help: "Packets per second assigned to the VPX instance",
+		},
+		[]string{
+			"ns_instance",
+			"vpx",
+		},
+	)
+
+	sdxVPXCPUUsage = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "sdx_vpx_cpu_usage_percent",
+			Help: "CPU usage of the VPX instance, as reported by the Management Service",
+		},
+		[]string{
+			"ns_instance",
+			"vpx",
+		},
+	)
+
+	sdxVPXMemoryUsage = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "sdx_vpx_memory_usage_percent",
+			Help: "Memory usage of the VPX instance, as reported by the Management Service",
+		},
+		[]string{
+			"ns_instance",
+			"vpx",
+		},
+	)
+
+	sdxInterfaceUp = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "sdx_interface_up",
+			Help: "Whether the physical interface of the SDX appliance is up; 1 = Up, 0 = anything else",
+		},
+		[]string{
+			"ns_instance",
+			"port",
+		},
+	)
+
+	sdxHardwareHealth = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "sdx_hardware_health",
+			Help: "Health of each hardware component of the SDX appliance, such as disks, fans and power supplies; 1 = OK, 0 = anything else",
+		},
+		[]string{
+			"ns_instance",
+			"component",
+			"name",
+		},
+	)
+
+	// sdxHealthResources are the Management Service health resources exported by sdx_hardware_health; the component label is the resource name without the xen_health_ prefix.
+	sdxHealthResources = []string{
+		"xen_health_disk",
+		"xen_health_sr",
+		"xen_health_fan_speed",
+		"xen_health_temp",
+		"xen_health_voltage",
+		"xen_health_power_supply",
+	}
+)
+
+// SDXExporter collects from the Nitro API of an SDX Management Service, rather than from a NetScaler.
+// It exports the state and assigned resources of each VPX instance, and the health of the appliance's hardware.
+type SDXExporter struct {
+	target
+	vpxUp          *prometheus.GaugeVec
+	vpxInfo        *prometheus.GaugeVec
+	vpxCores       *prometheus.GaugeVec
+	vpxSSLCores    *prometheus.GaugeVec
+	vpxMemory      *prometheus.GaugeVec
+	vpxThroughput  *prometheus.GaugeVec
+	vpxPPS         *prometheus.GaugeVec
+	vpxCPUUsage    *prometheus.GaugeVec
+	vpxMemoryUsage *prometheus.GaugeVec
+	interfaceUp    *prometheus.GaugeVec
+	hardwareHealth *prometheus.GaugeVec
+	username       string
+	password       string
+	url            string
+	ignoreCert     bool
+	proxyInstance  string
+}
+
+// NewSDXExporter initialises the exporter for an SDX Management Service
+func NewSDXExporter(url string, username string, password string, ignoreCert bool, proxyInstance string, logger log.Logger, nsInstance string) (*SDXExporter, error) {
+	if url == "" {
+		return nil, errors.New("no Url Specified")
+	}
+
+	if username == "" {
+		return nil, errors.New("no Username Specified")
+	}
+
+	if password == "" {
+		return nil, errors.New("no Password Specified")
+	}
+
+	return &SDXExporter{
+		vpxUp:          sdxVPXUp(),
+		vpxInfo:        sdxVPXInfo(),
+		vpxCores:       sdxVPXCores(),
+		vpxSSLCores:    sdxVPXSSLCores(),
+		vpxMemory:      sdxVPXMemory(),
+		vpxThroughput:  sdxVPXThroughput(),
+		vpxPPS:         sdxVPXPPS(),
+		vpxCPUUsage:    sdxVPXCPUUsage(),
+		vpxMemoryUsage: sdxVPXMemoryUsage(),
+		interfaceUp:    sdxInterfaceUp(),
+		hardwareHealth: sdxHardwareHealth(),
+		username:       username,
+		password:       password,
+		url:            url,
+		ignoreCert:     ignoreCert,
+		proxyInstance:  proxyInstance,
+		target: target{
+			logger:     logger,
+			nsInstance: nsInstance,
+		},
+	}, nil
+}
+
+// Describe implements Collector
+func (e *SDXExporter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- up
+	ch <- loginFailures
+	ch <- lastErrorInfo
+	ch <- apiErrors
+
+	e.vpxUp.Describe(ch)
+	e.vpxInfo.Describe(ch)
+	e.vpxCores.Describe(ch)
+	e.vpxSSLCores.Describe(ch)
+	e.vpxMemory.Describe(ch)
+	e.vpxThroughput.Describe(ch)
+	e.vpxPPS.Describe(ch)
+	e.vpxCPUUsage.Describe(ch)
+	e.vpxMemoryUsage.Describe(ch)
+	e.interfaceUp.Describe(ch)
+	e.hardwareHealth.Describe(ch)
+}
+
+// Collect is initiated by the Prometheus handler and gathers the metrics
+func (e *SDXExporter) Collect(ch chan<- prometheus.Metric) {
+	nsClient, err := netscaler.NewNitroClient(e.url, e.username, e.password, e.ignoreCert)
+	if err != nil {
+		level.Error(e.logger).Log("msg", err)
+		e.recordConnectError(err)
+		e.collectTargetStatus(ch, false)
+		return
+	}
+	defer nsClient.CloseIdleConnection()
+
+	if e.proxyInstance != "" {
+		nsClient.ProxyThroughADM(e.proxyInstance)
+	}
+
+	err = netscaler.Connect(nsClient)
+	if err != nil {
+		level.Error(e.logger).Log("msg", err)
+		e.recordConnectError(err)
+		e.collectTargetStatus(ch, false)
+		return
+	}
+
+	e.collectTargetStatus(ch, true)
+
+	instances, err := netscaler.GetSDXInstances(nsClient, netscaler.Query{})
+	if err != nil {
+		e.logAPIError(err)
+	}
+
+	interfaces, err := netscaler.GetSDXInterfaces(nsClient, netscaler.Query{})
+	if err != nil {
+		e.logAPIError(err)
+	}
+
+	e.collectVPXUp(instances)
+	e.vpxUp.Collect(ch)
+
+	e.collectVPXInfo(instances)
+	e.vpxInfo.Collect(ch)
+
+	e.collectVPXResource(e.vpxCores, instances, func(i netscaler.SDXInstance) string { return i.Cores.String() })
+	e.vpxCores.Collect(ch)
+
+	e.collectVPXResource(e.vpxSSLCores, instances, func(i netscaler.SDXInstance) string { return i.SSLCores.String() })
+	e.vpxSSLCores.Collect(ch)
+
+	e.collectVPXResource(e.vpxMemory, instances, func(i netscaler.SDXInstance) string { return i.Memory.String() })
+	e.vpxMemory.Collect(ch)
+
+	e.collectVPXResource(e.vpxThroughput, instances, func(i netscaler.SDXInstance) string { return i.Throughput.String() })
+	e.vpxThroughput.Collect(ch)
+
+	e.collectVPXResource(e.vpxPPS, instances, func(i netscaler.SDXInstance) string { return i.PPS.String() })
+	e.vpxPPS.Collect(ch)
+
+	e.collectVPXResource(e.vpxCPUUsage, instances, func(i netscaler.SDXInstance) string { return i.CPUUsage.String() })
+	e.vpxCPUUsage.Collect(ch)
+
+	e.collectVPXResource(e.vpxMemoryUsage, instances, func(i netscaler.SDXInstance) string { return i.MemoryUsage.String() })
+	e.vpxMemoryUsage.Collect(ch)
+
+	e.collectInterfaceUp(interfaces)
+	e.interfaceUp.Collect(ch)
+
+	e.hardwareHealth.Reset()
+	for _, resource := range sdxHealthResources {
+		components, err := netscaler.GetConfigList[map[string]interface{}](nsClient, resource, netscaler.Query{})
+		if err != nil {
+			e.logAPIError(err)
+			continue
+		}
+
+		e.collectHardwareHealth(strings.TrimPrefix(resource, "xen_health_"), components)
+	}
+	e.hardwareHealth.Collect(ch)
+
+	e.collectAPIErrors(ch)
+
+	err = netscaler.Disconnect(nsClient)
+	if err != nil {
+		level.Error(e.logger).Log("msg", err)
+		return
+	}
+}
+
+func (e *SDXExporter) collectVPXUp(instances []netscaler.SDXInstance) {
+	e.vpxUp.Reset()
+
+	for _, i := range instances {
+		state := 0.0
+		if strings.EqualFold(i.InstanceState, "Up") {
+			state = 1.0
+		}
+
+		e.vpxUp.WithLabelValues(e.nsInstance, i.Name).Set(state)
+	}
+}
+
+func (e *SDXExporter) collectVPXInfo(instances []netscaler.SDXInstance) {
+	e.vpxInfo.Reset()
+
+	for _, i := range instances {
+		e.vpxInfo.WithLabelValues(e.nsInstance, i.Name, i.IPAddress, i.Hostname, i.VMState, i.InstanceState, i.HAMasterState, i.NetScalerVersion).Set(1)
+	}
+}
+
+// collectVPXResource sets the metric for each VPX instance to the value returned by field.  Instances for which the Management Service does not return the value are skipped.
+func (e *SDXExporter) collectVPXResource(metric *prometheus.GaugeVec, instances []netscaler.SDXInstance, field func(netscaler.SDXInstance) string) {
+	metric.Reset()
+
+	for _, i := range instances {
+		val, err := strconv.ParseFloat(field(i), 64)
+		if err != nil {
+			continue
+		}
+
+		metric.WithLabelValues(e.nsInstance, i.Name).Set(val)
+	}
+}
+
+func (e *SDXExporter) collectInterfaceUp(interfaces []netscaler.SDXInterface) {
+	e.interfaceUp.Reset()
+
+	for _, i := range interfaces {
+		state := 0.0
+		if strings.EqualFold(i.State, "Up") {
+			state = 1.0
+		}
+
+		e.interfaceUp.WithLabelValues(e.nsInstance, i.Port).Set(state)
+	}
+}
+
+// collectHardwareHealth sets the health of each component returned by a Management Service health resource.
+// The health resources differ in which fields they name the component and report its status with, so the first of the known fields present is used.
+func (e *SDXExporter) collectHardwareHealth(component string, items []map[string]interface{}) {
+	for n, item := range items {
+		name := ""
+		for _, field := range []string{"name", "device_name", "sensor_name", "port"} {
+			if name = mappingLabelValue(item[field]); name != "" {
+				break
+			}
+		}
+		if name == "" {
+			name = strconv.Itoa(n)
+		}
+
+		status := mappingLabelValue(item["status"])
+		if status == "" {
+			status = mappingLabelValue(item["state"])
+		}
+
+		health := 0.0
+		switch strings.ToLower(status) {
+		case "ok", "up", "normal", "healthy", "good":
+			health = 1.0
+		}
+
+		e.hardwareHealth.WithLabelValues(e.nsInstance, component, name).Set(health)
+	}
+}
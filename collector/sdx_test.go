@@ -0,0 +1,46 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rokett/citrix-netscaler-exporter/netscaler/nitrotest"
+
+	"github.com/go-kit/kit/log"
+)
+
+// TestSDXGolden runs the SDX exporter against the Management Service fixtures in testdata/sdx, and compares the metrics with sdx.golden.
+// The fixtures return numbers as strings for one instance and as numbers for another, as different Management Service releases do.
+func TestSDXGolden(t *testing.T) {
+	srv, err := nitrotest.NewServerWithFixtures(os.DirFS(filepath.Join("testdata", "sdx")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	e, err := NewSDXExporter(srv.URL, "user", "pass", false, "", log.NewNopLogger(), "golden-sdx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := gather(t, e)
+
+	for _, want := range []string{
+		`sdx_vpx_up{ns_instance="golden-sdx",vpx="vpx03"} 0`,
+		`sdx_vpx_cpu_cores{ns_instance="golden-sdx",vpx="vpx02"} 4`,
+		`sdx_hardware_health{component="disk",name="sdb",ns_instance="golden-sdx"} 0`,
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("metrics are missing %s", want)
+		}
+	}
+
+	// The Management Service does not return the usage or packets per second of vpx03, which is halted, so they are left out rather than exported as 0.
+	if strings.Contains(string(got), `sdx_vpx_packets_per_second{ns_instance="golden-sdx",vpx="vpx03"}`) {
+		t.Error("metrics include packets per second for vpx03, which the Management Service did not return")
+	}
+
+	compareGolden(t, "sdx", got)
+}
@@ -0,0 +1,36 @@
+package collector
+
+import (
+	"github.com/rokett/citrix-netscaler-exporter/netscaler"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	serversState = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "server_state",
+			Help: "Current state of the server; 1 = ENABLED, 0 = otherwise",
+		},
+		[]string{
+			"ns_instance",
+			"partition",
+			"td",
+			"server",
+		},
+	)
+)
+
+func (e *Exporter) collectServersState(servers []netscaler.Server) {
+	e.serversState.Reset()
+
+	for _, s := range servers {
+		state := 0.0
+
+		if s.State == "ENABLED" {
+			state = 1.0
+		}
+
+		e.serversState.WithLabelValues(e.nsInstance, e.partition, e.serverTrafficDomain(s), s.Name).Set(state)
+	}
+}
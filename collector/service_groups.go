@@ -2,163 +2,193 @@ package collector
 
 import (
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/rokett/citrix-netscaler-exporter/netscaler"
 
+	"github.com/go-kit/kit/log/level"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+// configPageSize is the number of resources fetched per request when listing config which can run to thousands of entries, such as service groups.
+const configPageSize = 1000
+
 var (
-	serviceGroupsState = prometheus.NewGaugeVec(
+	serviceGroupsState = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "servicegroup_state",
 			Help: "Current state of the server",
 		},
 		[]string{
 			"ns_instance",
+			"partition",
+			"td",
 			"servicegroup",
 			"member",
 			"port",
 		},
 	)
 
-	serviceGroupsAvgTTFB = prometheus.NewGaugeVec(
+	serviceGroupsAvgTTFB = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "servicegroup_average_time_to_first_byte",
 			Help: "Average TTFB between the NetScaler appliance and the server.",
 		},
 		[]string{
 			"ns_instance",
+			"partition",
+			"td",
 			"servicegroup",
 			"member",
 			"port",
 		},
 	)
 
-	serviceGroupsTotalRequests = prometheus.NewCounterVec(
+	serviceGroupsTotalRequests = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "servicegroup_total_requests",
 			Help: "Total number of requests received on this service",
 		},
 		[]string{
 			"ns_instance",
+			"partition",
+			"td",
 			"servicegroup",
 			"member",
 			"port",
 		},
 	)
 
-	serviceGroupsTotalResponses = prometheus.NewCounterVec(
+	serviceGroupsTotalResponses = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "servicegroup_total_responses",
 			Help: "Number of responses received on this service.",
 		},
 		[]string{
 			"ns_instance",
+			"partition",
+			"td",
 			"servicegroup",
 			"member",
 			"port",
 		},
 	)
 
-	serviceGroupsTotalRequestBytes = prometheus.NewCounterVec(
+	serviceGroupsTotalRequestBytes = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "servicegroup_total_request_bytes",
 			Help: "Total number of request bytes received on this service",
 		},
 		[]string{
 			"ns_instance",
+			"partition",
+			"td",
 			"servicegroup",
 			"member",
 			"port",
 		},
 	)
 
-	serviceGroupsTotalResponseBytes = prometheus.NewCounterVec(
+	serviceGroupsTotalResponseBytes = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "servicegroup_total_response_bytes",
 			Help: "Number of response bytes received by this service",
 		},
 		[]string{
 			"ns_instance",
+			"partition",
+			"td",
 			"servicegroup",
 			"member",
 			"port",
 		},
 	)
 
-	serviceGroupsCurrentClientConnections = prometheus.NewGaugeVec(
+	serviceGroupsCurrentClientConnections = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "servicegroup_current_client_connections",
 			Help: "Number of current client connections.",
 		},
 		[]string{
 			"ns_instance",
+			"partition",
+			"td",
 			"servicegroup",
 			"member",
 			"port",
 		},
 	)
 
-	serviceGroupsSurgeCount = prometheus.NewGaugeVec(
+	serviceGroupsSurgeCount = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "servicegroup_surge_count",
 			Help: "Number of requests in the surge queue.",
 		},
 		[]string{
 			"ns_instance",
+			"partition",
+			"td",
 			"servicegroup",
 			"member",
 			"port",
 		},
 	)
 
-	serviceGroupsCurrentServerConnections = prometheus.NewGaugeVec(
+	serviceGroupsCurrentServerConnections = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "servicegroup_current_server_connections",
 			Help: "Number of current connections to the actual servers behind the virtual server.",
 		},
 		[]string{
 			"ns_instance",
+			"partition",
+			"td",
 			"servicegroup",
 			"member",
 			"port",
 		},
 	)
 
-	serviceGroupsServerEstablishedConnections = prometheus.NewGaugeVec(
+	serviceGroupsServerEstablishedConnections = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "servicegroup_server_established_connections",
 			Help: "Number of server connections in ESTABLISHED state.",
 		},
 		[]string{
 			"ns_instance",
+			"partition",
+			"td",
 			"servicegroup",
 			"member",
 			"port",
 		},
 	)
 
-	serviceGroupsCurrentReusePool = prometheus.NewGaugeVec(
+	serviceGroupsCurrentReusePool = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "servicegroup_current_reuse_pool",
 			Help: "Number of requests in the idle queue/reuse pool.",
 		},
 		[]string{
 			"ns_instance",
+			"partition",
+			"td",
 			"servicegroup",
 			"member",
 			"port",
 		},
 	)
 
-	serviceGroupsMaxClients = prometheus.NewGaugeVec(
+	serviceGroupsMaxClients = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "servicegroup_max_clients",
 			Help: "Maximum open connections allowed on this service.",
 		},
 		[]string{
 			"ns_instance",
+			"partition",
+			"td",
 			"servicegroup",
 			"member",
 			"port",
@@ -177,7 +207,7 @@ func (e *Exporter) collectServiceGroupsState(sg netscaler.ServiceGroupMemberStat
 
 	port := strconv.Itoa(sg.PrimaryPort)
 
-	e.serviceGroupsState.WithLabelValues(e.nsInstance, sgName, servername, port).Set(state)
+	e.serviceGroupsState.WithLabelValues(e.nsInstance, e.partition, sg.TD, sgName, servername, port).Set(state)
 }
 
 func (e *Exporter) collectServiceGroupsAvgTTFB(sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
@@ -186,7 +216,7 @@ func (e *Exporter) collectServiceGroupsAvgTTFB(sg netscaler.ServiceGroupMemberSt
 	port := strconv.Itoa(sg.PrimaryPort)
 
 	val, _ := strconv.ParseFloat(sg.AvgTimeToFirstByte, 64)
-	e.serviceGroupsAvgTTFB.WithLabelValues(e.nsInstance, sgName, servername, port).Set(val)
+	e.serviceGroupsAvgTTFB.WithLabelValues(e.nsInstance, e.partition, sg.TD, sgName, servername, port).Set(val)
 }
 
 func (e *Exporter) collectServiceGroupsTotalRequests(sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
@@ -195,7 +225,7 @@ func (e *Exporter) collectServiceGroupsTotalRequests(sg netscaler.ServiceGroupMe
 	port := strconv.Itoa(sg.PrimaryPort)
 
 	val, _ := strconv.ParseFloat(sg.TotalRequests, 64)
-	e.serviceGroupsTotalRequests.WithLabelValues(e.nsInstance, sgName, servername, port).Set(val)
+	e.serviceGroupsTotalRequests.WithLabelValues(e.nsInstance, e.partition, sg.TD, sgName, servername, port).Set(val)
 }
 
 func (e *Exporter) collectServiceGroupsTotalResponses(sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
@@ -204,7 +234,7 @@ func (e *Exporter) collectServiceGroupsTotalResponses(sg netscaler.ServiceGroupM
 	port := strconv.Itoa(sg.PrimaryPort)
 
 	val, _ := strconv.ParseFloat(sg.TotalResponses, 64)
-	e.serviceGroupsTotalResponses.WithLabelValues(e.nsInstance, sgName, servername, port).Set(val)
+	e.serviceGroupsTotalResponses.WithLabelValues(e.nsInstance, e.partition, sg.TD, sgName, servername, port).Set(val)
 }
 
 func (e *Exporter) collectServiceGroupsTotalRequestBytes(sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
@@ -213,7 +243,7 @@ func (e *Exporter) collectServiceGroupsTotalRequestBytes(sg netscaler.ServiceGro
 	port := strconv.Itoa(sg.PrimaryPort)
 
 	val, _ := strconv.ParseFloat(sg.TotalRequestBytes, 64)
-	e.serviceGroupsTotalRequestBytes.WithLabelValues(e.nsInstance, sgName, servername, port).Set(val)
+	e.serviceGroupsTotalRequestBytes.WithLabelValues(e.nsInstance, e.partition, sg.TD, sgName, servername, port).Set(val)
 }
 
 func (e *Exporter) collectServiceGroupsTotalResponseBytes(sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
@@ -222,7 +252,7 @@ func (e *Exporter) collectServiceGroupsTotalResponseBytes(sg netscaler.ServiceGr
 	port := strconv.Itoa(sg.PrimaryPort)
 
 	val, _ := strconv.ParseFloat(sg.TotalResponseBytes, 64)
-	e.serviceGroupsTotalResponseBytes.WithLabelValues(e.nsInstance, sgName, servername, port).Set(val)
+	e.serviceGroupsTotalResponseBytes.WithLabelValues(e.nsInstance, e.partition, sg.TD, sgName, servername, port).Set(val)
 }
 
 func (e *Exporter) collectServiceGroupsCurrentClientConnections(sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
@@ -231,7 +261,7 @@ func (e *Exporter) collectServiceGroupsCurrentClientConnections(sg netscaler.Ser
 	port := strconv.Itoa(sg.PrimaryPort)
 
 	val, _ := strconv.ParseFloat(sg.CurrentClientConnections, 64)
-	e.serviceGroupsCurrentClientConnections.WithLabelValues(e.nsInstance, sgName, servername, port).Set(val)
+	e.serviceGroupsCurrentClientConnections.WithLabelValues(e.nsInstance, e.partition, sg.TD, sgName, servername, port).Set(val)
 }
 
 func (e *Exporter) collectServiceGroupsSurgeCount(sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
@@ -240,7 +270,7 @@ func (e *Exporter) collectServiceGroupsSurgeCount(sg netscaler.ServiceGroupMembe
 	port := strconv.Itoa(sg.PrimaryPort)
 
 	val, _ := strconv.ParseFloat(sg.SurgeCount, 64)
-	e.serviceGroupsSurgeCount.WithLabelValues(e.nsInstance, sgName, servername, port).Set(val)
+	e.serviceGroupsSurgeCount.WithLabelValues(e.nsInstance, e.partition, sg.TD, sgName, servername, port).Set(val)
 }
 
 func (e *Exporter) collectServiceGroupsCurrentServerConnections(sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
@@ -249,7 +279,7 @@ func (e *Exporter) collectServiceGroupsCurrentServerConnections(sg netscaler.Ser
 	port := strconv.Itoa(sg.PrimaryPort)
 
 	val, _ := strconv.ParseFloat(sg.CurrentServerConnections, 64)
-	e.serviceGroupsCurrentServerConnections.WithLabelValues(e.nsInstance, sgName, servername, port).Set(val)
+	e.serviceGroupsCurrentServerConnections.WithLabelValues(e.nsInstance, e.partition, sg.TD, sgName, servername, port).Set(val)
 }
 
 func (e *Exporter) collectServiceGroupsServerEstablishedConnections(sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
@@ -258,7 +288,7 @@ func (e *Exporter) collectServiceGroupsServerEstablishedConnections(sg netscaler
 	port := strconv.Itoa(sg.PrimaryPort)
 
 	val, _ := strconv.ParseFloat(sg.ServerEstablishedConnections, 64)
-	e.serviceGroupsServerEstablishedConnections.WithLabelValues(e.nsInstance, sgName, servername, port).Set(val)
+	e.serviceGroupsServerEstablishedConnections.WithLabelValues(e.nsInstance, e.partition, sg.TD, sgName, servername, port).Set(val)
 }
 
 func (e *Exporter) collectServiceGroupsCurrentReusePool(sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
@@ -267,7 +297,7 @@ func (e *Exporter) collectServiceGroupsCurrentReusePool(sg netscaler.ServiceGrou
 	port := strconv.Itoa(sg.PrimaryPort)
 
 	val, _ := strconv.ParseFloat(sg.CurrentReusePool, 64)
-	e.serviceGroupsCurrentReusePool.WithLabelValues(e.nsInstance, sgName, servername, port).Set(val)
+	e.serviceGroupsCurrentReusePool.WithLabelValues(e.nsInstance, e.partition, sg.TD, sgName, servername, port).Set(val)
 }
 
 func (e *Exporter) collectServiceGroupsMaxClients(sg netscaler.ServiceGroupMemberStats, sgName string, servername string) {
@@ -276,5 +306,93 @@ func (e *Exporter) collectServiceGroupsMaxClients(sg netscaler.ServiceGroupMembe
 	port := strconv.Itoa(sg.PrimaryPort)
 
 	val, _ := strconv.ParseFloat(sg.MaxClients, 64)
-	e.serviceGroupsMaxClients.WithLabelValues(e.nsInstance, sgName, servername, port).Set(val)
+	e.serviceGroupsMaxClients.WithLabelValues(e.nsInstance, e.partition, sg.TD, sgName, servername, port).Set(val)
+}
+
+type serviceGroupMember struct {
+	stats        netscaler.ServiceGroupMemberStats
+	serviceGroup string
+	server       string
+}
+
+// serviceGroupBulkRetryAfter is how long the exporter waits before trying bulk service group member stats again, after the NetScaler reported that they do not exist.
+const serviceGroupBulkRetryAfter = time.Hour
+
+// serviceGroupMembers returns the stats of every service group member.
+// With bulk retrieval enabled, all members are fetched in one request rather than one request per service group.
+// If the request fails it falls back to one request per service group.  Firmware which rejects the bulk request, whether it reports that bulk stats do not exist
+// or that the service group name is missing, is remembered so that the bulk request is not retried every scrape; an error which may be temporary,
+// such as a timeout or server error, falls back for this scrape only.
+func (e *Exporter) serviceGroupMembers(nsClient *netscaler.NitroClient) []serviceGroupMember {
+	if e.serviceGroupBulk && !e.serviceGroupBulkUnsupported() {
+		stats, err := netscaler.GetAllServiceGroupMemberStats(nsClient)
+		if err == nil {
+			var members []serviceGroupMember
+
+			for _, s := range stats {
+				members = append(members, newServiceGroupMember(s, ""))
+			}
+
+			return members
+		}
+
+		if !netscaler.IsTemporary(err) {
+			level.Warn(e.logger).Log("msg", "bulk service group member stats are not supported; falling back to one request per service group", "retry_after", serviceGroupBulkRetryAfter, "err", err)
+			e.setServiceGroupBulkUnsupported()
+		} else {
+			e.logAPIError(err)
+		}
+	}
+
+	servicegroups, err := netscaler.GetServiceGroups(nsClient, netscaler.Query{Attrs: []string{"servicegroupname"}, PageSize: configPageSize})
+	if err != nil {
+		e.logAPIError(err)
+	}
+
+	var members []serviceGroupMember
+
+	// A service group name can be configured once in each traffic domain, and the config API lists each of them, but one request by name returns all of them.
+	requested := make(map[string]bool)
+
+	for _, sg := range servicegroups {
+		if requested[sg.Name] {
+			continue
+		}
+		requested[sg.Name] = true
+
+		stats, err := netscaler.GetServiceGroupMemberStats(nsClient, sg.Name)
+		if err != nil {
+			e.logAPIError(err)
+			continue
+		}
+
+		for _, group := range stats {
+			for _, s := range group.ServiceGroupMembers {
+				members = append(members, newServiceGroupMember(s, sg.Name))
+			}
+		}
+	}
+
+	return members
+}
+
+// newServiceGroupMember works out the service group and server names for the member.
+// Nitro returns the servicegroupname of a member as <service group>?<server>?<port>.
+func newServiceGroupMember(s netscaler.ServiceGroupMemberStats, serviceGroup string) serviceGroupMember {
+	parts := strings.Split(s.ServiceGroupName, "?")
+
+	if serviceGroup == "" {
+		serviceGroup = parts[0]
+	}
+
+	server := s.ServerName
+	if len(parts) > 1 {
+		server = parts[1]
+	}
+
+	return serviceGroupMember{
+		stats:        s,
+		serviceGroup: serviceGroup,
+		server:       server,
+	}
 }
@@ -0,0 +1,213 @@
+package collector
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rokett/citrix-netscaler-exporter/netscaler"
+	"github.com/rokett/citrix-netscaler-exporter/netscaler/nitrotest"
+
+	"github.com/go-kit/kit/log"
+)
+
+// serviceGroupFixtures sets fixtures for the given number of service groups, each with two members, for both per service group and bulk retrieval.
+func serviceGroupFixtures(srv *nitrotest.Server, groups int) {
+	var names, all []string
+
+	for i := 1; i <= groups; i++ {
+		sg := fmt.Sprintf("sg%d", i)
+		names = append(names, `{"servicegroupname":"`+sg+`"}`)
+
+		var members []string
+		for j := 1; j <= 2; j++ {
+			members = append(members, fmt.Sprintf(`{"servicegroupname":"%s?server%d?80","servername":"server%d","primaryipaddress":"192.0.2.%d","primaryport":80,"state":"UP","totalrequests":"%d"}`, sg, j, j, j, i*10+j))
+		}
+
+		srv.SetFixture("stat/servicegroup/"+sg+"?statbindings=yes", []byte(`{"errorcode":0,"message":"Done","servicegroup":[{"servicegroupname":"`+sg+`","servicegroupmember":[`+strings.Join(members, ",")+`]}]}`))
+		all = append(all, members...)
+	}
+
+	srv.SetFixture("config/servicegroup", []byte(`{"errorcode":0,"message":"Done","servicegroup":[`+strings.Join(names, ",")+`]}`))
+	srv.SetFixture("stat/servicegroupmember", []byte(`{"errorcode":0,"message":"Done","servicegroupmember":[`+strings.Join(all, ",")+`]}`))
+}
+
+// newServiceGroupTestExporter returns an exporter for the server, and a client logged in to it.
+// Each test uses its own instance name, as what is remembered about a target is shared between exporters for the same instance.
+func newServiceGroupTestExporter(tb testing.TB, srv *nitrotest.Server, instance string, bulk bool) (*Exporter, *netscaler.NitroClient) {
+	tb.Helper()
+
+	forgetTargetStatus(instance)
+
+	e, err := NewExporter(srv.URL, "user", "pass", false, log.NewNopLogger(), instance, Options{ServiceGroupBulk: bulk})
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	c, err := netscaler.NewNitroClient(srv.URL, "user", "pass", false)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	err = netscaler.Connect(c)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return e, c
+}
+
+func TestServiceGroupMembersBulk(t *testing.T) {
+	srv := nitrotest.NewServer()
+	defer srv.Close()
+
+	serviceGroupFixtures(srv, 2)
+
+	e, c := newServiceGroupTestExporter(t, srv, "sg-bulk", true)
+
+	members := e.serviceGroupMembers(c)
+
+	if len(members) != 4 {
+		t.Fatalf("got %d members, want 4", len(members))
+	}
+
+	if m := members[2]; m.serviceGroup != "sg2" || m.server != "server1" || m.stats.TotalRequests != "21" {
+		t.Errorf("member 2 = %+v, want server1 of sg2", m)
+	}
+
+	if n := srv.Requests("stat/servicegroupmember"); n != 1 {
+		t.Errorf("sent %d bulk requests, want 1", n)
+	}
+
+	if n := srv.Requests(nitrotest.Key("config/servicegroup", "attrs=servicegroupname&pagesize=1000&pageno=1")); n != 0 {
+		t.Errorf("listed service groups %d times, want 0", n)
+	}
+}
+
+func TestServiceGroupMembersBulkNotFound(t *testing.T) {
+	srv := nitrotest.NewServer()
+	defer srv.Close()
+
+	serviceGroupFixtures(srv, 2)
+	srv.SetError("stat/servicegroupmember", http.StatusNotFound, netscaler.ErrorCodeNoSuchResource, "Resource type does not exist")
+
+	e, c := newServiceGroupTestExporter(t, srv, "sg-bulk-not-found", true)
+
+	if members := e.serviceGroupMembers(c); len(members) != 4 {
+		t.Fatalf("got %d members, want 4 from one request per service group", len(members))
+	}
+
+	// The bulk request is not retried while it is known not to be supported.
+	e.serviceGroupMembers(c)
+
+	if n := srv.Requests("stat/servicegroupmember"); n != 1 {
+		t.Errorf("sent %d bulk requests, want 1", n)
+	}
+
+	if n := srv.Requests("stat/servicegroup/sg1?statbindings=yes"); n != 2 {
+		t.Errorf("sent %d requests for sg1, want 2", n)
+	}
+
+	// It is tried again once the retry interval has passed.
+	targetStatusesMu.Lock()
+	e.targetStatus().serviceGroupBulkUnsupported = time.Now().Add(-serviceGroupBulkRetryAfter)
+	targetStatusesMu.Unlock()
+
+	e.serviceGroupMembers(c)
+
+	if n := srv.Requests("stat/servicegroupmember"); n != 2 {
+		t.Errorf("sent %d bulk requests, want 2", n)
+	}
+}
+
+func TestServiceGroupMembersBulkMissingArgument(t *testing.T) {
+	srv := nitrotest.NewServer()
+	defer srv.Close()
+
+	// Firmware which only returns service group members for a named service group rejects the bulk request as missing an argument.
+	serviceGroupFixtures(srv, 2)
+	srv.SetError("stat/servicegroupmember", http.StatusBadRequest, 1092, "Required argument missing [servicegroupname]")
+
+	e, c := newServiceGroupTestExporter(t, srv, "sg-bulk-missing-argument", true)
+
+	if members := e.serviceGroupMembers(c); len(members) != 4 {
+		t.Fatalf("got %d members, want 4 from one request per service group", len(members))
+	}
+
+	e.serviceGroupMembers(c)
+
+	if n := srv.Requests("stat/servicegroupmember"); n != 1 {
+		t.Errorf("sent %d bulk requests, want 1", n)
+	}
+
+	// The rejection is logged as a warning rather than counted as an API error.
+	targetStatusesMu.Lock()
+	apiErrors := len(e.targetStatus().apiErrors)
+	targetStatusesMu.Unlock()
+
+	if apiErrors != 0 {
+		t.Errorf("counted API errors for %d codes, want none", apiErrors)
+	}
+}
+
+func TestServiceGroupMembersBulkOtherError(t *testing.T) {
+	srv := nitrotest.NewServer()
+	defer srv.Close()
+
+	serviceGroupFixtures(srv, 2)
+	srv.SetError("stat/servicegroupmember", http.StatusInternalServerError, 1, "Internal error")
+
+	e, c := newServiceGroupTestExporter(t, srv, "sg-bulk-other-error", true)
+
+	if members := e.serviceGroupMembers(c); len(members) != 4 {
+		t.Fatalf("got %d members, want 4 from one request per service group", len(members))
+	}
+
+	// A server error may be temporary, so the bulk request is tried again.
+	e.serviceGroupMembers(c)
+
+	if n := srv.Requests("stat/servicegroupmember"); n != 2 {
+		t.Errorf("sent %d bulk requests, want 2", n)
+	}
+
+	if n := srv.Requests(nitrotest.Key("config/servicegroup", "attrs=servicegroupname&pagesize=1000&pageno=1")); n != 2 {
+		t.Errorf("listed service groups %d times, want 2", n)
+	}
+}
+
+// BenchmarkServiceGroupMembers compares fetching the members of many service groups in bulk against one request per service group.
+// Each response is delayed, as the round trip to a NetScaler dominates the time taken.
+func BenchmarkServiceGroupMembers(b *testing.B) {
+	const groups = 200
+
+	for _, bulk := range []bool{true, false} {
+		name := "per_service_group"
+		if bulk {
+			name = "bulk"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			srv := nitrotest.NewServer()
+			defer srv.Close()
+
+			serviceGroupFixtures(srv, groups)
+
+			e, c := newServiceGroupTestExporter(b, srv, "sg-benchmark-"+name, bulk)
+
+			srv.SetLatency(time.Millisecond)
+			start := srv.TotalRequests()
+
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				if members := e.serviceGroupMembers(c); len(members) != groups*2 {
+					b.Fatalf("got %d members, want %d", len(members), groups*2)
+				}
+			}
+
+			b.ReportMetric(float64(srv.TotalRequests()-start)/float64(b.N), "requests/op")
+		})
+	}
+}
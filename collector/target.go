@@ -0,0 +1,220 @@
+package collector
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rokett/citrix-netscaler-exporter/netscaler"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	up = prometheus.NewDesc(
+		"citrix_netscaler_up",
+		"Whether the exporter could log in to the NetScaler; 1 = up, 0 = down",
+		[]string{
+			"ns_instance",
+		},
+		nil,
+	)
+
+	loginFailures = prometheus.NewDesc(
+		"citrix_netscaler_login_failures_total",
+		"Number of times the NetScaler rejected the exporter's login since the exporter started",
+		[]string{
+			"ns_instance",
+		},
+		nil,
+	)
+
+	lastErrorInfo = prometheus.NewDesc(
+		"citrix_netscaler_last_error_info",
+		"Most recent error returned when connecting to the NetScaler; always 1.  The code label is the Nitro errorcode, or empty if the NetScaler could not be reached",
+		[]string{
+			"ns_instance",
+			"reason",
+			"status_code",
+			"code",
+		},
+		nil,
+	)
+
+	apiErrors = prometheus.NewDesc(
+		"citrix_netscaler_api_errors_total",
+		"Number of failed Nitro API requests since the exporter started, by Nitro errorcode.  The code label is empty for errors which did not come from the NetScaler, such as timeouts",
+		[]string{
+			"ns_instance",
+			"code",
+		},
+		nil,
+	)
+
+	// The exporter is created for each scrape, so anything which must survive between scrapes is kept here.
+	// Keyed by NetScaler instance.
+	targetStatuses   = make(map[string]*targetStatus)
+	targetStatusesMu sync.Mutex
+)
+
+// targetStatusStaleAfter is how long the status of a NetScaler instance is remembered after it was last scraped.
+// The instance comes from the target parameter, which the caller chooses, so statuses are forgotten to stop mistyped or retired targets being held forever.
+const targetStatusStaleAfter = time.Hour
+
+// target identifies the NetScaler which an exporter collects from, and reports on the exporter's connection to it.
+// It is shared by the exporters for each module.
+type target struct {
+	logger     log.Logger
+	nsInstance string
+}
+
+type targetStatus struct {
+	loginFailures  float64
+	lastError      bool
+	lastReason     string
+	lastStatusCode string
+	lastCode       string
+	apiErrors      map[string]float64
+	scraped        time.Time
+
+	// serviceGroupBulkUnsupported is when the NetScaler last rejected the request for bulk service group member stats; zero if it has not.
+	serviceGroupBulkUnsupported time.Time
+}
+
+// recordConnectError remembers the error for the target.  Only logins which the NetScaler rejected count as login failures;
+// if the NetScaler could not be reached at all there was no login attempt to fail.
+// The error message is only logged, by the caller, as it can contain anything and would create a new series for every different message.
+func (t *target) recordConnectError(err error) {
+	targetStatusesMu.Lock()
+	defer targetStatusesMu.Unlock()
+
+	status := t.targetStatus()
+	status.lastError = true
+	status.lastStatusCode = ""
+	status.lastCode = ""
+
+	nitroErr, ok := errors.Cause(err).(*netscaler.NitroError)
+	if !ok {
+		status.lastReason = "unreachable"
+		return
+	}
+
+	status.lastStatusCode = strconv.Itoa(nitroErr.StatusCode)
+	if nitroErr.Code != 0 {
+		status.lastCode = strconv.FormatInt(nitroErr.Code, 10)
+	}
+
+	switch {
+	case netscaler.IsLoginFailure(err):
+		status.lastReason = "login_failure"
+		status.loginFailures++
+	case netscaler.IsSessionExpired(err):
+		status.lastReason = "session_expired"
+	case netscaler.IsPermissionDenied(err):
+		status.lastReason = "permission_denied"
+	default:
+		status.lastReason = "nitro_error"
+	}
+}
+
+// logAPIError logs an error returned by a Nitro API request, and counts it by error code.
+// Permission errors are expected where the Command Policy deliberately does not allow a module, so they are logged as warnings and the module is skipped.
+func (t *target) logAPIError(err error) {
+	targetStatusesMu.Lock()
+	code := ""
+	if c := netscaler.ErrorCode(err); c != 0 {
+		code = strconv.FormatInt(c, 10)
+	}
+	t.targetStatus().apiErrors[code]++
+	targetStatusesMu.Unlock()
+
+	if netscaler.IsPermissionDenied(err) {
+		level.Warn(t.logger).Log("msg", "not permitted to read from the NetScaler; check the Command Policy", "err", err)
+		return
+	}
+
+	level.Error(t.logger).Log("msg", err)
+}
+
+func (t *target) collectAPIErrors(ch chan<- prometheus.Metric) {
+	targetStatusesMu.Lock()
+	defer targetStatusesMu.Unlock()
+
+	for code, count := range t.targetStatus().apiErrors {
+		ch <- prometheus.MustNewConstMetric(
+			apiErrors, prometheus.CounterValue, count, t.nsInstance, code,
+		)
+	}
+}
+
+// targetStatus returns the status for the target's NetScaler instance, creating it if needed.
+// targetStatusesMu must be held by the caller.
+func (t *target) targetStatus() *targetStatus {
+	status, ok := targetStatuses[t.nsInstance]
+	if !ok {
+		status = &targetStatus{
+			apiErrors: make(map[string]float64),
+			scraped:   time.Now(),
+		}
+		targetStatuses[t.nsInstance] = status
+	}
+
+	return status
+}
+
+func (t *target) collectTargetStatus(ch chan<- prometheus.Metric, isUp bool) {
+	targetStatusesMu.Lock()
+	defer targetStatusesMu.Unlock()
+
+	now := time.Now()
+
+	// Statuses are only updated when their target is scraped, so stale statuses are removed here to forget targets which are no longer scraped.
+	for instance, status := range targetStatuses {
+		if instance != t.nsInstance && now.Sub(status.scraped) > targetStatusStaleAfter {
+			delete(targetStatuses, instance)
+		}
+	}
+
+	status := t.targetStatus()
+	status.scraped = now
+
+	val := 0.0
+	if isUp {
+		val = 1.0
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		up, prometheus.GaugeValue, val, t.nsInstance,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		loginFailures, prometheus.CounterValue, status.loginFailures, t.nsInstance,
+	)
+
+	if status.lastError {
+		ch <- prometheus.MustNewConstMetric(
+			lastErrorInfo, prometheus.GaugeValue, 1, t.nsInstance, status.lastReason, status.lastStatusCode, status.lastCode,
+		)
+	}
+}
+
+// serviceGroupBulkUnsupported reports whether the NetScaler rejected the request for bulk service group member stats within the last serviceGroupBulkRetryAfter.
+// After that the bulk request is tried again, in case the firmware has been upgraded.
+func (e *Exporter) serviceGroupBulkUnsupported() bool {
+	targetStatusesMu.Lock()
+	defer targetStatusesMu.Unlock()
+
+	unsupported := e.targetStatus().serviceGroupBulkUnsupported
+
+	return !unsupported.IsZero() && time.Since(unsupported) < serviceGroupBulkRetryAfter
+}
+
+func (e *Exporter) setServiceGroupBulkUnsupported() {
+	targetStatusesMu.Lock()
+	defer targetStatusesMu.Unlock()
+
+	e.targetStatus().serviceGroupBulkUnsupported = time.Now()
+}
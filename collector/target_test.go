@@ -0,0 +1,168 @@
+package collector
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rokett/citrix-netscaler-exporter/netscaler/nitrotest"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// forgetTargetStatus drops the status kept for the instance, so that a test starts afresh when it is run more than once.
+func forgetTargetStatus(instance string) {
+	targetStatusesMu.Lock()
+	defer targetStatusesMu.Unlock()
+
+	delete(targetStatuses, instance)
+}
+
+// scrapeTarget serves the exporter's metrics as the /netscaler endpoint does, returning the HTTP status and body.
+func scrapeTarget(t *testing.T, url string, password string, instance string) (int, string) {
+	t.Helper()
+
+	e, err := NewExporter(url, "user", password, false, log.NewNopLogger(), instance, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(e)
+
+	rec := httptest.NewRecorder()
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(rec, httptest.NewRequest("GET", "/netscaler", nil))
+
+	return rec.Code, rec.Body.String()
+}
+
+func TestTargetStatus(t *testing.T) {
+	srv, err := nitrotest.NewServerWithFixtures(nitrotest.Fixtures())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	srv.Username = "user"
+	srv.Password = "pass"
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	tests := []struct {
+		name     string
+		url      string
+		password string
+		want     []string
+		notWant  []string
+	}{
+		{
+			name:     "up",
+			url:      srv.URL,
+			password: "pass",
+			want: []string{
+				`citrix_netscaler_up{ns_instance="status-up"} 1`,
+				`citrix_netscaler_login_failures_total{ns_instance="status-up"} 0`,
+			},
+			notWant: []string{
+				"citrix_netscaler_last_error_info",
+			},
+		},
+		{
+			// Scraped twice, so that the rejected logins are counted.
+			name:     "login-failure",
+			url:      srv.URL,
+			password: "wrong",
+			want: []string{
+				`citrix_netscaler_up{ns_instance="status-login-failure"} 0`,
+				`citrix_netscaler_login_failures_total{ns_instance="status-login-failure"} 2`,
+				`citrix_netscaler_last_error_info{code="354",ns_instance="status-login-failure",reason="login_failure",status_code="401"} 1`,
+			},
+		},
+		{
+			// A NetScaler which cannot be reached did not reject a login.
+			name:     "unreachable",
+			url:      closed.URL,
+			password: "pass",
+			want: []string{
+				`citrix_netscaler_up{ns_instance="status-unreachable"} 0`,
+				`citrix_netscaler_login_failures_total{ns_instance="status-unreachable"} 0`,
+				`citrix_netscaler_last_error_info{code="",ns_instance="status-unreachable",reason="unreachable",status_code=""} 1`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forgetTargetStatus("status-" + tt.name)
+
+			var code int
+			var body string
+
+			for i := 0; i < 2; i++ {
+				code, body = scrapeTarget(t, tt.url, tt.password, "status-"+tt.name)
+			}
+
+			// A target which is down is reported by the up metric; the scrape itself succeeds.
+			if code != http.StatusOK {
+				t.Errorf("scrape returned HTTP %d, want 200", code)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(body, want+"\n") {
+					t.Errorf("missing %s", want)
+				}
+			}
+
+			for _, notWant := range tt.notWant {
+				if strings.Contains(body, notWant) {
+					t.Errorf("unexpected %s", notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestTargetStatusForgotten(t *testing.T) {
+	srv, err := nitrotest.NewServerWithFixtures(nitrotest.Fixtures())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	srv.Username = "user"
+	srv.Password = "pass"
+
+	scrapeTarget(t, srv.URL, "wrong", "status-stale")
+	scrapeTarget(t, srv.URL, "wrong", "status-recent")
+
+	targetStatusesMu.Lock()
+	targetStatuses["status-stale"].scraped = time.Now().Add(-targetStatusStaleAfter - time.Minute)
+	targetStatusesMu.Unlock()
+
+	scrapeTarget(t, srv.URL, "pass", "status-other")
+
+	targetStatusesMu.Lock()
+	_, stale := targetStatuses["status-stale"]
+	_, recent := targetStatuses["status-recent"]
+	targetStatusesMu.Unlock()
+
+	if stale {
+		t.Error("status of a target not scraped within targetStatusStaleAfter was kept")
+	}
+
+	if !recent {
+		t.Error("status of a recently scraped target was forgotten")
+	}
+
+	// The target starts again from nothing if it is scraped after being forgotten.
+	_, body := scrapeTarget(t, srv.URL, "wrong", "status-stale")
+
+	if want := `citrix_netscaler_login_failures_total{ns_instance="status-stale"} 1`; !strings.Contains(body, want+"\n") {
+		t.Errorf("missing %s", want)
+	}
+}
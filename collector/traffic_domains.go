@@ -0,0 +1,327 @@
+package collector
+
+import (
+	"strconv"
+
+	"github.com/rokett/citrix-netscaler-exporter/netscaler"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	trafficDomainInfo = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "traffic_domain_info",
+			Help: "Traffic domains configured on the NetScaler; always 1",
+		},
+		[]string{
+			"ns_instance",
+			"td",
+			"alias",
+			"state",
+		},
+	)
+
+	trafficDomainReceivedPackets = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "traffic_domain_received_packets",
+			Help: "Total number of packets received in the traffic domain",
+		},
+		[]string{
+			"ns_instance",
+			"td",
+		},
+	)
+
+	trafficDomainReceivedPacketsRate = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "traffic_domain_received_packets_rate",
+			Help: "Rate at which packets are received in the traffic domain, per second",
+		},
+		[]string{
+			"ns_instance",
+			"td",
+		},
+	)
+
+	trafficDomainTransmitPackets = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "traffic_domain_transmit_packets",
+			Help: "Total number of packets transmitted from the traffic domain",
+		},
+		[]string{
+			"ns_instance",
+			"td",
+		},
+	)
+
+	trafficDomainTransmitPacketsRate = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "traffic_domain_transmit_packets_rate",
+			Help: "Rate at which packets are transmitted from the traffic domain, per second",
+		},
+		[]string{
+			"ns_instance",
+			"td",
+		},
+	)
+
+	trafficDomainDroppedPackets = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "traffic_domain_dropped_packets",
+			Help: "Total number of inbound packets dropped in the traffic domain",
+		},
+		[]string{
+			"ns_instance",
+			"td",
+		},
+	)
+
+	trafficDomainDroppedPacketsRate = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "traffic_domain_dropped_packets_rate",
+			Help: "Rate at which inbound packets are dropped in the traffic domain, per second",
+		},
+		[]string{
+			"ns_instance",
+			"td",
+		},
+	)
+
+	// entityVirtualServers, entityServices and entityServiceGroups are the config resources which traffic domains are read from, and the attributes which identify each entity.
+	entityVirtualServers = trafficDomainResource{resource: "lbvserver", name: "name", ip: "ipv46", port: "port"}
+	entityServices       = trafficDomainResource{resource: "service", name: "name", ip: "ipaddress", port: "port"}
+	entityServiceGroups  = trafficDomainResource{resource: "servicegroup", name: "servicegroupname"}
+)
+
+// trafficDomainResource is a config resource which has a td attribute, with the attributes holding each entity's name, IP address and port.
+type trafficDomainResource struct {
+	resource string
+	name     string
+	ip       string
+	port     string
+}
+
+type trafficDomainEntity struct {
+	td   string
+	ip   string
+	port string
+}
+
+// trafficDomainIndex holds the traffic domain, IP address and port of each configured entity, keyed by name.
+// A name can be configured once in each traffic domain, so a name can have more than one entity.
+type trafficDomainIndex map[string][]trafficDomainEntity
+
+// entityTrafficDomains returns the traffic domains of the entities of a config resource.
+func (e *Exporter) entityTrafficDomains(nsClient *netscaler.NitroClient, r trafficDomainResource) trafficDomainIndex {
+	attrs := []string{r.name, "td"}
+	for _, attr := range []string{r.ip, r.port} {
+		if attr != "" {
+			attrs = append(attrs, attr)
+		}
+	}
+
+	items, err := netscaler.GetConfigList[map[string]interface{}](nsClient, r.resource, netscaler.Query{Attrs: attrs, PageSize: configPageSize})
+	if err != nil {
+		e.logAPIError(err)
+		return nil
+	}
+
+	index := make(trafficDomainIndex)
+
+	for _, item := range items {
+		name := mappingLabelValue(item[r.name])
+		index[name] = append(index[name], trafficDomainEntity{
+			td:   defaultTrafficDomain(mappingLabelValue(item["td"])),
+			ip:   mappingLabelValue(item[r.ip]),
+			port: mappingLabelValue(item[r.port]),
+		})
+	}
+
+	return index
+}
+
+// serverTrafficDomains returns the traffic domain of each server.
+func serverTrafficDomains(servers []netscaler.Server) trafficDomainIndex {
+	index := make(trafficDomainIndex)
+
+	for _, s := range servers {
+		index[s.Name] = append(index[s.Name], trafficDomainEntity{
+			td: defaultTrafficDomain(s.TD.String()),
+			ip: s.IPAddress,
+		})
+	}
+
+	return index
+}
+
+// defaultTrafficDomain returns td, or 0 when it is empty; entities in the default traffic domain are returned without a td attribute.
+func defaultTrafficDomain(td string) string {
+	if td == "" {
+		return "0"
+	}
+
+	return td
+}
+
+// lookup returns the traffic domain of the entity with the given name, IP address and port; an empty port matches any port.
+// A name configured in a single traffic domain needs no more than the name.  Otherwise the IP address and port pick out the entity,
+// and an empty string is returned if they do not match entities in exactly one traffic domain.
+func (index trafficDomainIndex) lookup(name string, ip string, port string) string {
+	entities := index[name]
+
+	if td := onlyTrafficDomain(entities); td != "" {
+		return td
+	}
+
+	var matched []trafficDomainEntity
+
+	for _, entity := range entities {
+		if entity.ip == ip && (port == "" || entity.port == port) {
+			matched = append(matched, entity)
+		}
+	}
+
+	return onlyTrafficDomain(matched)
+}
+
+// onlyTrafficDomain returns the traffic domain of the entities if they are all in the same one, or an empty string otherwise.
+func onlyTrafficDomain(entities []trafficDomainEntity) string {
+	if len(entities) == 0 {
+		return ""
+	}
+
+	for _, entity := range entities[1:] {
+		if entity.td != entities[0].td {
+			return ""
+		}
+	}
+
+	return entities[0].td
+}
+
+// serverTrafficDomain returns the td label of a server.  Unlike the stat API, the server config includes the traffic domain, so it needs no lookup.
+func (e *Exporter) serverTrafficDomain(s netscaler.Server) string {
+	switch {
+	case !e.trafficDomains:
+		return ""
+	case e.partition != "default":
+		return "0"
+	}
+
+	return defaultTrafficDomain(s.TD.String())
+}
+
+// setTrafficDomains fills in the traffic domain of each virtual server, service and service group member, which the stat API does not return.
+// Entities are matched to their config by name, and by IP address and port when the name is configured in more than one traffic domain.
+func (e *Exporter) setTrafficDomains(nsClient *netscaler.NitroClient, virtualServers []netscaler.VirtualServerStats, services []netscaler.ServiceStats, members []serviceGroupMember, servers []netscaler.Server) {
+	index := e.entityTrafficDomains(nsClient, entityVirtualServers)
+	for i := range virtualServers {
+		vs := &virtualServers[i]
+		vs.TD = index.lookup(vs.Name, vs.PrimaryIPAddress, strconv.Itoa(vs.PrimaryPort))
+	}
+
+	index = e.entityTrafficDomains(nsClient, entityServices)
+	for i := range services {
+		service := &services[i]
+		service.TD = index.lookup(service.Name, service.PrimaryIPAddress, strconv.Itoa(service.PrimaryPort))
+	}
+
+	if len(members) == 0 {
+		return
+	}
+
+	// Every member of a service group is in the service group's traffic domain, which is also the traffic domain of the member's server.
+	// The server is used when the service group's name is configured in more than one traffic domain.
+	index = e.entityTrafficDomains(nsClient, entityServiceGroups)
+	serverIndex := serverTrafficDomains(servers)
+
+	for i := range members {
+		m := &members[i]
+
+		m.stats.TD = index.lookup(m.serviceGroup, "", "")
+		if m.stats.TD == "" {
+			m.stats.TD = serverIndex.lookup(m.server, m.stats.PrimaryIPAddress, "")
+		}
+	}
+}
+
+// setDefaultTrafficDomain puts every virtual server, service and service group member in the default traffic domain.
+func setDefaultTrafficDomain(virtualServers []netscaler.VirtualServerStats, services []netscaler.ServiceStats, members []serviceGroupMember) {
+	for i := range virtualServers {
+		virtualServers[i].TD = "0"
+	}
+
+	for i := range services {
+		services[i].TD = "0"
+	}
+
+	for i := range members {
+		members[i].stats.TD = "0"
+	}
+}
+
+func (e *Exporter) collectTrafficDomainInfo(trafficDomains []netscaler.TrafficDomain) {
+	e.trafficDomainInfo.Reset()
+
+	// The default traffic domain always exists, but is not returned by the config API.
+	e.trafficDomainInfo.WithLabelValues(e.nsInstance, "0", "", "ENABLED").Set(1)
+
+	for _, td := range trafficDomains {
+		e.trafficDomainInfo.WithLabelValues(e.nsInstance, td.TD.String(), td.AliasName, td.State).Set(1)
+	}
+}
+
+func (e *Exporter) collectTrafficDomainReceivedPackets(stats []netscaler.TrafficDomainStats) {
+	e.trafficDomainReceivedPackets.Reset()
+
+	for _, s := range stats {
+		val, _ := s.TotalReceivedPackets.Float64()
+		e.trafficDomainReceivedPackets.WithLabelValues(e.nsInstance, defaultTrafficDomain(s.TD.String())).Set(val)
+	}
+}
+
+func (e *Exporter) collectTrafficDomainReceivedPacketsRate(stats []netscaler.TrafficDomainStats) {
+	e.trafficDomainReceivedPacketsRate.Reset()
+
+	for _, s := range stats {
+		val, _ := s.ReceivedPacketsRate.Float64()
+		e.trafficDomainReceivedPacketsRate.WithLabelValues(e.nsInstance, defaultTrafficDomain(s.TD.String())).Set(val)
+	}
+}
+
+func (e *Exporter) collectTrafficDomainTransmitPackets(stats []netscaler.TrafficDomainStats) {
+	e.trafficDomainTransmitPackets.Reset()
+
+	for _, s := range stats {
+		val, _ := s.TotalTransmitPackets.Float64()
+		e.trafficDomainTransmitPackets.WithLabelValues(e.nsInstance, defaultTrafficDomain(s.TD.String())).Set(val)
+	}
+}
+
+func (e *Exporter) collectTrafficDomainTransmitPacketsRate(stats []netscaler.TrafficDomainStats) {
+	e.trafficDomainTransmitPacketsRate.Reset()
+
+	for _, s := range stats {
+		val, _ := s.TransmitPacketsRate.Float64()
+		e.trafficDomainTransmitPacketsRate.WithLabelValues(e.nsInstance, defaultTrafficDomain(s.TD.String())).Set(val)
+	}
+}
+
+func (e *Exporter) collectTrafficDomainDroppedPackets(stats []netscaler.TrafficDomainStats) {
+	e.trafficDomainDroppedPackets.Reset()
+
+	for _, s := range stats {
+		val, _ := s.TotalDroppedPackets.Float64()
+		e.trafficDomainDroppedPackets.WithLabelValues(e.nsInstance, defaultTrafficDomain(s.TD.String())).Set(val)
+	}
+}
+
+func (e *Exporter) collectTrafficDomainDroppedPacketsRate(stats []netscaler.TrafficDomainStats) {
+	e.trafficDomainDroppedPacketsRate.Reset()
+
+	for _, s := range stats {
+		val, _ := s.DroppedPacketsRate.Float64()
+		e.trafficDomainDroppedPacketsRate.WithLabelValues(e.nsInstance, defaultTrafficDomain(s.TD.String())).Set(val)
+	}
+}
@@ -0,0 +1,91 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rokett/citrix-netscaler-exporter/netscaler/nitrotest"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestTrafficDomainIndexLookup(t *testing.T) {
+	index := trafficDomainIndex{
+		"vs_web": {{td: "0", ip: "192.0.2.1", port: "443"}},
+		"vs_api": {{td: "11", ip: "192.0.2.2", port: "443"}, {td: "10", ip: "192.0.2.2", port: "8443"}, {td: "12", ip: "192.0.2.3", port: "443"}},
+		"vs_dup": {{td: "10", ip: "192.0.2.4", port: "443"}, {td: "11", ip: "192.0.2.4", port: "443"}},
+	}
+
+	tests := []struct {
+		name string
+		ip   string
+		port string
+		want string
+	}{
+		{name: "vs_web", ip: "198.51.100.1", port: "80", want: "0"},
+		{name: "vs_api", ip: "192.0.2.2", port: "8443", want: "10"},
+		{name: "vs_api", ip: "192.0.2.2", port: "443", want: "11"},
+		{name: "vs_api", ip: "192.0.2.3", port: "", want: "12"},
+		// The IP address matches entities in two traffic domains when any port matches.
+		{name: "vs_api", ip: "192.0.2.2", port: "", want: ""},
+		{name: "vs_dup", ip: "192.0.2.4", port: "443", want: ""},
+		{name: "vs_unknown", ip: "192.0.2.1", port: "443", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := index.lookup(tt.name, tt.ip, tt.port); got != tt.want {
+			t.Errorf("lookup(%q, %q, %q) = %q, want %q", tt.name, tt.ip, tt.port, got, tt.want)
+		}
+	}
+}
+
+// trafficDomainFixtures sets a virtual server and a service group with the same names in traffic domains 10 and 11,
+// with the config listing them in a different order to the stats.
+func trafficDomainFixtures(srv *nitrotest.Server) {
+	srv.SetFixture("config/nstrafficdomain", []byte(`{"errorcode":0,"message":"Done","nstrafficdomain":[{"td":10,"aliasname":"blue","state":"ENABLED"},{"td":11,"aliasname":"green","state":"ENABLED"}]}`))
+	srv.SetFixture("stat/nstrafficdomain", []byte(`{"errorcode":0,"message":"Done","nstrafficdomain":[{"td":10,"nstdtotrxpkts":1200,"nstdrxpktsrate":4.5,"nstdtottxpkts":1100,"nstdtxpktsrate":4,"nstdtotdroppedpkts":3,"nstddroppedpktsrate":0}]}`))
+	srv.SetFixture("stat/lbvserver", []byte(`{"errorcode":0,"message":"Done","lbvserver":[{"name":"vs_api","primaryipaddress":"192.0.2.10","primaryport":443,"state":"UP"},{"name":"vs_api","primaryipaddress":"192.0.2.11","primaryport":443,"state":"DOWN"}]}`))
+	srv.SetFixture("config/lbvserver", []byte(`{"errorcode":0,"message":"Done","lbvserver":[{"name":"vs_api","ipv46":"192.0.2.11","port":443,"td":11},{"name":"vs_api","ipv46":"192.0.2.10","port":443,"td":10}]}`))
+	srv.SetFixture("config/servicegroup", []byte(`{"errorcode":0,"message":"Done","servicegroup":[{"servicegroupname":"sg_api","td":10},{"servicegroupname":"sg_api","td":11}]}`))
+	srv.SetFixture("stat/servicegroup/sg_api?statbindings=yes", []byte(`{"errorcode":0,"message":"Done","servicegroup":[{"servicegroupname":"sg_api","servicegroupmember":[`+
+		`{"servicegroupname":"sg_api?api10?8080","primaryipaddress":"192.0.2.20","primaryport":8080,"state":"UP"},`+
+		`{"servicegroupname":"sg_api?api11?8080","primaryipaddress":"192.0.2.21","primaryport":8080,"state":"UP"}]}]}`))
+	srv.SetFixture("config/server", []byte(`{"errorcode":0,"message":"Done","server":[{"name":"api10","ipaddress":"192.0.2.20","state":"ENABLED","td":10},{"name":"api11","ipaddress":"192.0.2.21","state":"DISABLED","td":11}]}`))
+}
+
+func TestTrafficDomains(t *testing.T) {
+	srv := nitrotest.NewServer()
+	defer srv.Close()
+
+	trafficDomainFixtures(srv)
+
+	e, err := NewExporter(srv.URL, "user", "pass", false, log.NewNopLogger(), "traffic-domains", Options{TrafficDomains: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := string(gather(t, e))
+
+	for _, want := range []string{
+		`virtual_servers_state{ns_instance="traffic-domains",partition="default",td="10",virtual_server="vs_api"} 1`,
+		`virtual_servers_state{ns_instance="traffic-domains",partition="default",td="11",virtual_server="vs_api"} 0`,
+		`servicegroup_state{member="api10",ns_instance="traffic-domains",partition="default",port="8080",servicegroup="sg_api",td="10"} 1`,
+		`servicegroup_state{member="api11",ns_instance="traffic-domains",partition="default",port="8080",servicegroup="sg_api",td="11"} 1`,
+		`server_state{ns_instance="traffic-domains",partition="default",server="api10",td="10"} 1`,
+		`server_state{ns_instance="traffic-domains",partition="default",server="api11",td="11"} 0`,
+		`traffic_domain_info{alias="green",ns_instance="traffic-domains",state="ENABLED",td="11"} 1`,
+		`traffic_domain_received_packets{ns_instance="traffic-domains",td="10"} 1200`,
+		`traffic_domain_received_packets_rate{ns_instance="traffic-domains",td="10"} 4.5`,
+		`traffic_domain_transmit_packets{ns_instance="traffic-domains",td="10"} 1100`,
+		`traffic_domain_dropped_packets{ns_instance="traffic-domains",td="10"} 3`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("metrics are missing %s", want)
+		}
+	}
+
+	// The service group is listed once for each traffic domain, but its members are only requested once.
+	if n := srv.Requests("stat/servicegroup/sg_api?statbindings=yes"); n != 1 {
+		t.Errorf("sent %d requests for sg_api, want 1", n)
+	}
+}
@@ -9,247 +9,271 @@ import (
 )
 
 var (
-	virtualServersState = prometheus.NewGaugeVec(
+	virtualServersState = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "virtual_servers_state",
 			Help: "Current state of the server",
 		},
 		[]string{
 			"ns_instance",
+			"partition",
+			"td",
 			"virtual_server",
 		},
 	)
 
-	virtualServersWaitingRequests = prometheus.NewGaugeVec(
+	virtualServersWaitingRequests = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "virtual_servers_waiting_requests",
 			Help: "Number of requests waiting on a specific virtual server",
 		},
 		[]string{
 			"ns_instance",
+			"partition",
+			"td",
 			"virtual_server",
 		},
 	)
 
-	virtualServersHealth = prometheus.NewGaugeVec(
+	virtualServersHealth = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "virtual_servers_health",
 			Help: "Percentage of UP services bound to a specific virtual server",
 		},
 		[]string{
 			"ns_instance",
+			"partition",
+			"td",
 			"virtual_server",
 		},
 	)
 
-	virtualServersInactiveServices = prometheus.NewGaugeVec(
+	virtualServersInactiveServices = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "virtual_servers_inactive_services",
 			Help: "Number of inactive services bound to a specific virtual server",
 		},
 		[]string{
 			"ns_instance",
+			"partition",
+			"td",
 			"virtual_server",
 		},
 	)
 
-	virtualServersActiveServices = prometheus.NewGaugeVec(
+	virtualServersActiveServices = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "virtual_servers_active_services",
 			Help: "Number of active services bound to a specific virtual server",
 		},
 		[]string{
 			"ns_instance",
+			"partition",
+			"td",
 			"virtual_server",
 		},
 	)
 
-	virtualServersTotalHits = prometheus.NewCounterVec(
+	virtualServersTotalHits = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "virtual_servers_total_hits",
 			Help: "Total virtual server hits",
 		},
 		[]string{
 			"ns_instance",
+			"partition",
+			"td",
 			"virtual_server",
 		},
 	)
 
-	virtualServersTotalRequests = prometheus.NewCounterVec(
+	virtualServersTotalRequests = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "virtual_servers_total_requests",
 			Help: "Total virtual server requests",
 		},
 		[]string{
 			"ns_instance",
+			"partition",
+			"td",
 			"virtual_server",
 		},
 	)
 
-	virtualServersTotalResponses = prometheus.NewCounterVec(
+	virtualServersTotalResponses = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "virtual_servers_total_responses",
 			Help: "Total virtual server responses",
 		},
 		[]string{
 			"ns_instance",
+			"partition",
+			"td",
 			"virtual_server",
 		},
 	)
 
-	virtualServersTotalRequestBytes = prometheus.NewCounterVec(
+	virtualServersTotalRequestBytes = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "virtual_servers_total_request_bytes",
 			Help: "Total virtual server request bytes",
 		},
 		[]string{
 			"ns_instance",
+			"partition",
+			"td",
 			"virtual_server",
 		},
 	)
-	virtualServersTotalResponseBytes = prometheus.NewCounterVec(
+	virtualServersTotalResponseBytes = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "virtual_servers_total_response_bytes",
 			Help: "Total virtual server response bytes",
 		},
 		[]string{
 			"ns_instance",
+			"partition",
+			"td",
 			"virtual_server",
 		},
 	)
 
-	virtualServersCurrentClientConnections = prometheus.NewGaugeVec(
+	virtualServersCurrentClientConnections = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "virtual_servers_current_client_connections",
 			Help: "Number of current client connections on a specific virtual server",
 		},
 		[]string{
 			"ns_instance",
+			"partition",
+			"td",
 			"virtual_server",
 		},
 	)
 
-	virtualServersCurrentServerConnections = prometheus.NewGaugeVec(
+	virtualServersCurrentServerConnections = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "virtual_servers_current_server_connections",
 			Help: "Number of current connections to the actual servers behind the specific virtual server.",
 		},
 		[]string{
 			"ns_instance",
+			"partition",
+			"td",
 			"virtual_server",
 		},
 	)
 )
 
-func (e *Exporter) collectVirtualServerState(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectVirtualServerState(virtualServers []netscaler.VirtualServerStats) {
 	e.virtualServersState.Reset()
 
-	for _, vs := range ns.VirtualServerStats {
+	for _, vs := range virtualServers {
 		state := 0.0
 
 		if vs.State == "UP" {
 			state = 1.0
 		}
 
-		e.virtualServersState.WithLabelValues(e.nsInstance, vs.Name).Set(state)
+		e.virtualServersState.WithLabelValues(e.nsInstance, e.partition, vs.TD, vs.Name).Set(state)
 	}
 }
 
-func (e *Exporter) collectVirtualServerWaitingRequests(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectVirtualServerWaitingRequests(virtualServers []netscaler.VirtualServerStats) {
 	e.virtualServersWaitingRequests.Reset()
 
-	for _, vs := range ns.VirtualServerStats {
+	for _, vs := range virtualServers {
 		waitingRequests, _ := strconv.ParseFloat(vs.WaitingRequests, 64)
-		e.virtualServersWaitingRequests.WithLabelValues(e.nsInstance, vs.Name).Set(waitingRequests)
+		e.virtualServersWaitingRequests.WithLabelValues(e.nsInstance, e.partition, vs.TD, vs.Name).Set(waitingRequests)
 	}
 }
 
-func (e *Exporter) collectVirtualServerHealth(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectVirtualServerHealth(virtualServers []netscaler.VirtualServerStats) {
 	e.virtualServersHealth.Reset()
 
-	for _, vs := range ns.VirtualServerStats {
+	for _, vs := range virtualServers {
 		health, _ := strconv.ParseFloat(vs.Health, 64)
-		e.virtualServersHealth.WithLabelValues(e.nsInstance, vs.Name).Set(health)
+		e.virtualServersHealth.WithLabelValues(e.nsInstance, e.partition, vs.TD, vs.Name).Set(health)
 	}
 }
 
-func (e *Exporter) collectVirtualServerInactiveServices(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectVirtualServerInactiveServices(virtualServers []netscaler.VirtualServerStats) {
 	e.virtualServersInactiveServices.Reset()
 
-	for _, vs := range ns.VirtualServerStats {
+	for _, vs := range virtualServers {
 		inactiveServices, _ := strconv.ParseFloat(vs.InactiveServices, 64)
-		e.virtualServersInactiveServices.WithLabelValues(e.nsInstance, vs.Name).Set(inactiveServices)
+		e.virtualServersInactiveServices.WithLabelValues(e.nsInstance, e.partition, vs.TD, vs.Name).Set(inactiveServices)
 	}
 }
 
-func (e *Exporter) collectVirtualServerActiveServices(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectVirtualServerActiveServices(virtualServers []netscaler.VirtualServerStats) {
 	e.virtualServersActiveServices.Reset()
 
-	for _, vs := range ns.VirtualServerStats {
+	for _, vs := range virtualServers {
 		activeServices, _ := strconv.ParseFloat(vs.ActiveServices, 64)
-		e.virtualServersActiveServices.WithLabelValues(e.nsInstance, vs.Name).Set(activeServices)
+		e.virtualServersActiveServices.WithLabelValues(e.nsInstance, e.partition, vs.TD, vs.Name).Set(activeServices)
 	}
 }
 
-func (e *Exporter) collectVirtualServerTotalHits(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectVirtualServerTotalHits(virtualServers []netscaler.VirtualServerStats) {
 	e.virtualServersTotalHits.Reset()
 
-	for _, vs := range ns.VirtualServerStats {
+	for _, vs := range virtualServers {
 		totalHits, _ := strconv.ParseFloat(vs.TotalHits, 64)
-		e.virtualServersTotalHits.WithLabelValues(e.nsInstance, vs.Name).Set(totalHits)
+		e.virtualServersTotalHits.WithLabelValues(e.nsInstance, e.partition, vs.TD, vs.Name).Set(totalHits)
 	}
 }
 
-func (e *Exporter) collectVirtualServerTotalRequests(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectVirtualServerTotalRequests(virtualServers []netscaler.VirtualServerStats) {
 	e.virtualServersTotalRequests.Reset()
 
-	for _, vs := range ns.VirtualServerStats {
+	for _, vs := range virtualServers {
 		totalRequests, _ := strconv.ParseFloat(vs.TotalRequests, 64)
-		e.virtualServersTotalRequests.WithLabelValues(e.nsInstance, vs.Name).Set(totalRequests)
+		e.virtualServersTotalRequests.WithLabelValues(e.nsInstance, e.partition, vs.TD, vs.Name).Set(totalRequests)
 	}
 }
 
-func (e *Exporter) collectVirtualServerTotalResponses(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectVirtualServerTotalResponses(virtualServers []netscaler.VirtualServerStats) {
 	e.virtualServersTotalResponses.Reset()
 
-	for _, vs := range ns.VirtualServerStats {
+	for _, vs := range virtualServers {
 		totalResponses, _ := strconv.ParseFloat(vs.TotalResponses, 64)
-		e.virtualServersTotalResponses.WithLabelValues(e.nsInstance, vs.Name).Set(totalResponses)
+		e.virtualServersTotalResponses.WithLabelValues(e.nsInstance, e.partition, vs.TD, vs.Name).Set(totalResponses)
 	}
 }
 
-func (e *Exporter) collectVirtualServerTotalRequestBytes(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectVirtualServerTotalRequestBytes(virtualServers []netscaler.VirtualServerStats) {
 	e.virtualServersTotalRequestBytes.Reset()
 
-	for _, vs := range ns.VirtualServerStats {
+	for _, vs := range virtualServers {
 		totalRequestBytes, _ := strconv.ParseFloat(vs.TotalRequestBytes, 64)
-		e.virtualServersTotalRequestBytes.WithLabelValues(e.nsInstance, vs.Name).Set(totalRequestBytes)
+		e.virtualServersTotalRequestBytes.WithLabelValues(e.nsInstance, e.partition, vs.TD, vs.Name).Set(totalRequestBytes)
 	}
 }
 
-func (e *Exporter) collectVirtualServerTotalResponseBytes(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectVirtualServerTotalResponseBytes(virtualServers []netscaler.VirtualServerStats) {
 	e.virtualServersTotalResponseBytes.Reset()
 
-	for _, vs := range ns.VirtualServerStats {
+	for _, vs := range virtualServers {
 		totalResponseBytes, _ := strconv.ParseFloat(vs.TotalResponseBytes, 64)
-		e.virtualServersTotalResponseBytes.WithLabelValues(e.nsInstance, vs.Name).Set(totalResponseBytes)
+		e.virtualServersTotalResponseBytes.WithLabelValues(e.nsInstance, e.partition, vs.TD, vs.Name).Set(totalResponseBytes)
 	}
 }
 
-func (e *Exporter) collectVirtualServerCurrentClientConnections(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectVirtualServerCurrentClientConnections(virtualServers []netscaler.VirtualServerStats) {
 	e.virtualServersCurrentClientConnections.Reset()
 
-	for _, vs := range ns.VirtualServerStats {
+	for _, vs := range virtualServers {
 		currentClientConnections, _ := strconv.ParseFloat(vs.CurrentClientConnections, 64)
-		e.virtualServersCurrentClientConnections.WithLabelValues(e.nsInstance, vs.Name).Set(currentClientConnections)
+		e.virtualServersCurrentClientConnections.WithLabelValues(e.nsInstance, e.partition, vs.TD, vs.Name).Set(currentClientConnections)
 	}
 }
 
-func (e *Exporter) collectVirtualServerCurrentServerConnections(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectVirtualServerCurrentServerConnections(virtualServers []netscaler.VirtualServerStats) {
 	e.virtualServersCurrentServerConnections.Reset()
 
-	for _, vs := range ns.VirtualServerStats {
+	for _, vs := range virtualServers {
 		currentServerConnections, _ := strconv.ParseFloat(vs.CurrentServerConnections, 64)
-		e.virtualServersCurrentServerConnections.WithLabelValues(e.nsInstance, vs.Name).Set(currentServerConnections)
+		e.virtualServersCurrentServerConnections.WithLabelValues(e.nsInstance, e.partition, vs.TD, vs.Name).Set(currentServerConnections)
 	}
 }
@@ -0,0 +1,35 @@
+package collector
+
+import (
+	"strconv"
+
+	"github.com/rokett/citrix-netscaler-exporter/netscaler"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	vlansInterfaceBinding = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "vlans_interface_binding",
+			Help: "Interface bound to the VLAN; always 1",
+		},
+		[]string{
+			"ns_instance",
+			"vlan",
+			"interface",
+			"tagged",
+		},
+	)
+)
+
+func (e *Exporter) collectVLANsInterfaceBinding(bindings []netscaler.VLANInterfaceBinding) {
+	e.vlansInterfaceBinding.Reset()
+
+	for _, binding := range bindings {
+		vlan := binding.ID.String()
+		tagged := strconv.FormatBool(binding.Tagged)
+
+		e.vlansInterfaceBinding.WithLabelValues(e.nsInstance, vlan, binding.Interface, tagged).Set(1)
+	}
+}
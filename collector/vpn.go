@@ -0,0 +1,133 @@
+package collector
+
+import (
+	"strconv"
+
+	"github.com/rokett/citrix-netscaler-exporter/netscaler"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	vpnLoginFailures = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "vpn_login_failures",
+			Help: "Number of VPN logins refused, by reason",
+		},
+		[]string{
+			"ns_instance",
+			"reason",
+		},
+	)
+
+	vpnClientSecurityCheckRequests = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "vpn_client_security_check_requests",
+			Help: "Number of client security (EPA) check requests received",
+		},
+		[]string{
+			"ns_instance",
+		},
+	)
+
+	vpnClientSecurityCheckSuccesses = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "vpn_client_security_check_successes",
+			Help: "Number of client security (EPA) checks which passed",
+		},
+		[]string{
+			"ns_instance",
+		},
+	)
+
+	vpnSTAConnectionSuccesses = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "vpn_sta_connection_successes",
+			Help: "Number of successful connections to the Secure Ticket Authority",
+		},
+		[]string{
+			"ns_instance",
+		},
+	)
+
+	vpnSTAConnectionFailures = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "vpn_sta_connection_failures",
+			Help: "Number of failed connections to the Secure Ticket Authority",
+		},
+		[]string{
+			"ns_instance",
+		},
+	)
+
+	vpnSTATicketValidationsNotStarted = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "vpn_sta_ticket_validations_not_started",
+			Help: "Number of STA ticket validations which could not be started, by ticket type",
+		},
+		[]string{
+			"ns_instance",
+			"ticket_type",
+		},
+	)
+)
+
+// collectVPNLoginFailures exports the logins refused for each reason which the NetScaler counts.
+// Authentication failures come from the AAA stats, as Gateway logins are authenticated by AAA; the rest come from the VPN stats.
+func (e *Exporter) collectVPNLoginFailures(vpn netscaler.VPNStats, aaa netscaler.AAAStats) {
+	e.vpnLoginFailures.Reset()
+
+	reasons := []struct {
+		reason string
+		value  string
+	}{
+		{"authentication", aaa.AuthFail},
+		{"authentication_non_http", aaa.AuthNonHTTPFail},
+		{"vpn_license", vpn.LicenseFailures},
+		{"ica_license", vpn.ICALicenseFailures},
+		{"intranet_ip", vpn.IntranetIPFailures},
+	}
+
+	for _, r := range reasons {
+		val, _ := strconv.ParseFloat(r.value, 64)
+		e.vpnLoginFailures.WithLabelValues(e.nsInstance, r.reason).Set(val)
+	}
+}
+
+func (e *Exporter) collectVPNClientSecurityCheckRequests(stats netscaler.VPNStats) {
+	e.vpnClientSecurityCheckRequests.Reset()
+
+	val, _ := strconv.ParseFloat(stats.ClientSecurityCheckRequests, 64)
+	e.vpnClientSecurityCheckRequests.WithLabelValues(e.nsInstance).Set(val)
+}
+
+func (e *Exporter) collectVPNClientSecurityCheckSuccesses(stats netscaler.VPNStats) {
+	e.vpnClientSecurityCheckSuccesses.Reset()
+
+	val, _ := strconv.ParseFloat(stats.ClientSecurityCheckSuccesses, 64)
+	e.vpnClientSecurityCheckSuccesses.WithLabelValues(e.nsInstance).Set(val)
+}
+
+func (e *Exporter) collectVPNSTAConnectionSuccesses(stats netscaler.VPNStats) {
+	e.vpnSTAConnectionSuccesses.Reset()
+
+	val, _ := strconv.ParseFloat(stats.STAConnectionSuccesses, 64)
+	e.vpnSTAConnectionSuccesses.WithLabelValues(e.nsInstance).Set(val)
+}
+
+func (e *Exporter) collectVPNSTAConnectionFailures(stats netscaler.VPNStats) {
+	e.vpnSTAConnectionFailures.Reset()
+
+	val, _ := strconv.ParseFloat(stats.STAConnectionFailures, 64)
+	e.vpnSTAConnectionFailures.WithLabelValues(e.nsInstance).Set(val)
+}
+
+func (e *Exporter) collectVPNSTATicketValidationsNotStarted(stats netscaler.VPNStats) {
+	e.vpnSTATicketValidationsNotStarted.Reset()
+
+	pTicket, _ := strconv.ParseFloat(stats.PTicketValidationsNotStarted, 64)
+	e.vpnSTATicketValidationsNotStarted.WithLabelValues(e.nsInstance, "p_ticket").Set(pTicket)
+
+	rTicket, _ := strconv.ParseFloat(stats.RTicketValidationsNotStarted, 64)
+	e.vpnSTATicketValidationsNotStarted.WithLabelValues(e.nsInstance, "r_ticket").Set(rTicket)
+}
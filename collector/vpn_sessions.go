@@ -0,0 +1,218 @@
+package collector
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rokett/citrix-netscaler-exporter/netscaler"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	vpnSessionInfo = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "vpn_session_info",
+			Help: "Active NetScaler Gateway session; always 1",
+		},
+		[]string{
+			"ns_instance",
+			"session_type",
+			"user",
+			"client_ip",
+			"vserver",
+		},
+	)
+
+	vpnSessionDuration = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "vpn_session_duration_seconds",
+			Help: "Number of seconds since the exporter first saw the NetScaler Gateway session; Nitro does not report when a session started, so this restarts from 0 when the exporter restarts",
+		},
+		[]string{
+			"ns_instance",
+			"session_type",
+			"user",
+			"client_ip",
+			"vserver",
+		},
+	)
+
+	vpnSessionsNotExported = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "vpn_sessions_not_exported",
+			Help: "Number of NetScaler Gateway sessions which were not exported because the maximum number of session series was reached",
+		},
+		[]string{
+			"ns_instance",
+		},
+	)
+
+	// Nitro does not return when a session started, so we remember when each session was first seen.
+	// Keyed by NetScaler instance.
+	vpnSessionsFirstSeen   = make(map[string]vpnSessionsSeen)
+	vpnSessionsFirstSeenMu sync.Mutex
+)
+
+// vpnSessionsStaleAfter is how long the sessions of a NetScaler instance are remembered after it was last scraped, so that targets which are no longer scraped do not hold on to their sessions forever.
+const vpnSessionsStaleAfter = time.Hour
+
+// vpnSessionsSeen holds when each session of a NetScaler instance was first seen, keyed by session, and when the instance was last scraped.
+type vpnSessionsSeen struct {
+	firstSeen map[string]time.Time
+	scraped   time.Time
+}
+
+type vpnSession struct {
+	key         string
+	sessionType string
+	user        string
+	clientIP    string
+	vserver     string
+	firstSeen   time.Time
+}
+
+// getVPNSessions fetches the AAA sessions and ICA connections, along with the total number of both.
+// A busy Gateway can have many thousands of sessions, so only the first page of each, up to the maximum number of session series, is fetched; the rest are only counted.
+func (e *Exporter) getVPNSessions(nsClient *netscaler.NitroClient) ([]netscaler.AAASession, []netscaler.VPNICAConnection, int) {
+	var aaaSessions []netscaler.AAASession
+	var icaConnections []netscaler.VPNICAConnection
+
+	if e.vpnSessionsMaxSeries > 0 {
+		q := netscaler.Query{PageSize: e.vpnSessionsMaxSeries, PageNo: 1}
+
+		var err error
+
+		aaaSessions, err = netscaler.GetAAASessions(nsClient, q)
+		if err != nil {
+			e.logAPIError(err)
+		}
+
+		icaConnections, err = netscaler.GetVPNICAConnections(nsClient, q)
+		if err != nil {
+			e.logAPIError(err)
+		}
+	}
+
+	total := 0
+
+	for _, c := range []struct {
+		resource string
+		fetched  int
+	}{
+		{"aaasession", len(aaaSessions)},
+		{"vpnicaconnection", len(icaConnections)},
+	} {
+		count, err := netscaler.GetConfigCount(nsClient, c.resource, netscaler.Query{})
+		if err != nil {
+			e.logAPIError(err)
+			count = c.fetched
+		}
+
+		total += count
+	}
+
+	return aaaSessions, icaConnections, total
+}
+
+// vpnSessionList combines AAA sessions and ICA connections into a single list, capped at the maximum number of session series.
+// The number of sessions which were not exported, out of the total number of sessions, is also returned.
+//
+// The vserver label is the name of the Gateway virtual server the session is connected to.
+// AAA sessions report the IP address and port they connected to, which is matched against the Gateway virtual servers.
+// ICA connections do not report the virtual server, so they take it from the AAA session of the same user and client IP; it is empty if there is no such session, such as for an ICA connection made without logging in to the Gateway.
+func (e *Exporter) vpnSessionList(aaaSessions []netscaler.AAASession, icaConnections []netscaler.VPNICAConnection, total int, vpnVirtualServers []netscaler.VPNVirtualServerStats) ([]vpnSession, int) {
+	var sessions []vpnSession
+
+	vservers := make(map[string]string, len(vpnVirtualServers))
+	for _, vs := range vpnVirtualServers {
+		vservers[vs.PrimaryIPAddress+":"+vs.PrimaryPort.String()] = vs.Name
+	}
+
+	// Keyed by user and client IP.
+	userVservers := make(map[string]string)
+
+	for _, s := range aaaSessions {
+		vserver := vservers[s.IPAddress+":"+s.Port.String()]
+		if vserver != "" {
+			userVservers[s.Username+"/"+s.PublicIP] = vserver
+		}
+
+		sessions = append(sessions, vpnSession{
+			key:         "aaa/" + s.Username + "/" + s.PublicIP + ":" + s.PublicPort.String(),
+			sessionType: "aaa",
+			user:        s.Username,
+			clientIP:    s.PublicIP,
+			vserver:     vserver,
+		})
+	}
+
+	for _, c := range icaConnections {
+		sessions = append(sessions, vpnSession{
+			key:         "ica/" + c.Username + "/" + c.SourceIP + ":" + c.SourcePort.String(),
+			sessionType: "ica",
+			user:        c.Username,
+			clientIP:    c.SourceIP,
+			vserver:     userVservers[c.Username+"/"+c.SourceIP],
+		})
+	}
+
+	vpnSessionsFirstSeenMu.Lock()
+	defer vpnSessionsFirstSeenMu.Unlock()
+
+	previous := vpnSessionsFirstSeen[e.nsInstance].firstSeen
+	current := make(map[string]time.Time, len(sessions))
+	now := time.Now()
+
+	for i, s := range sessions {
+		firstSeen, ok := previous[s.key]
+		if !ok {
+			firstSeen = now
+		}
+
+		current[s.key] = firstSeen
+		sessions[i].firstSeen = firstSeen
+	}
+
+	// Replacing the map, rather than updating it, drops sessions which have ended.
+	vpnSessionsFirstSeen[e.nsInstance] = vpnSessionsSeen{firstSeen: current, scraped: now}
+
+	for instance, seen := range vpnSessionsFirstSeen {
+		if now.Sub(seen.scraped) > vpnSessionsStaleAfter {
+			delete(vpnSessionsFirstSeen, instance)
+		}
+	}
+
+	if len(sessions) > e.vpnSessionsMaxSeries {
+		sessions = sessions[:e.vpnSessionsMaxSeries]
+	}
+
+	notExported := total - len(sessions)
+	if notExported < 0 {
+		notExported = 0
+	}
+
+	return sessions, notExported
+}
+
+func (e *Exporter) collectVPNSessionInfo(sessions []vpnSession) {
+	e.vpnSessionInfo.Reset()
+
+	for _, s := range sessions {
+		e.vpnSessionInfo.WithLabelValues(e.nsInstance, s.sessionType, s.user, s.clientIP, s.vserver).Set(1)
+	}
+}
+
+func (e *Exporter) collectVPNSessionDuration(sessions []vpnSession) {
+	e.vpnSessionDuration.Reset()
+
+	for _, s := range sessions {
+		e.vpnSessionDuration.WithLabelValues(e.nsInstance, s.sessionType, s.user, s.clientIP, s.vserver).Set(time.Since(s.firstSeen).Seconds())
+	}
+}
+
+func (e *Exporter) collectVPNSessionsNotExported(notExported int) {
+	e.vpnSessionsNotExported.Reset()
+
+	e.vpnSessionsNotExported.WithLabelValues(e.nsInstance).Set(float64(notExported))
+}
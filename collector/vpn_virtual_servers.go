@@ -9,7 +9,7 @@ import (
 )
 
 var (
-	vpnVirtualServersTotalRequests = prometheus.NewCounterVec(
+	vpnVirtualServersTotalRequests = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "vpn_virtual_servers_total_requests",
 			Help: "Total VPN virtual server requests",
@@ -20,7 +20,7 @@ var (
 		},
 	)
 
-	vpnVirtualServersTotalResponses = prometheus.NewCounterVec(
+	vpnVirtualServersTotalResponses = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "vpn_virtual_servers_total_responses",
 			Help: "Total VPN virtual server responses",
@@ -31,7 +31,7 @@ var (
 		},
 	)
 
-	vpnVirtualServersTotalRequestBytes = prometheus.NewCounterVec(
+	vpnVirtualServersTotalRequestBytes = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "vpn_virtual_servers_total_request_bytes",
 			Help: "Total VPN virtual server request bytes",
@@ -41,7 +41,7 @@ var (
 			"vpn_virtual_server",
 		},
 	)
-	vpnVirtualServersTotalResponseBytes = prometheus.NewCounterVec(
+	vpnVirtualServersTotalResponseBytes = newCounterVec(
 		prometheus.CounterOpts{
 			Name: "vpn_virtual_servers_total_response_bytes",
 			Help: "Total VPN virtual server response bytes",
@@ -52,7 +52,7 @@ var (
 		},
 	)
 
-	vpnVirtualServersState = prometheus.NewGaugeVec(
+	vpnVirtualServersState = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "vpn_virtual_servers_state",
 			Help: "Current state of the VPN virtual server",
@@ -62,48 +62,70 @@ var (
 			"vpn_virtual_server",
 		},
 	)
+
+	vpnVirtualServersCurrentUsers = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "vpn_virtual_servers_current_users",
+			Help: "Number of users currently logged in to the VPN virtual server",
+		},
+		[]string{
+			"ns_instance",
+			"vpn_virtual_server",
+		},
+	)
+
+	vpnVirtualServersCurrentSSLVPNUsers = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "vpn_virtual_servers_current_ssl_vpn_users",
+			Help: "Number of users currently connected to the VPN virtual server with a full SSL VPN session",
+		},
+		[]string{
+			"ns_instance",
+			"vpn_virtual_server",
+		},
+	)
 )
 
-func (e *Exporter) collectVPNVirtualServerTotalRequests(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectVPNVirtualServerTotalRequests(virtualServers []netscaler.VPNVirtualServerStats) {
 	e.vpnVirtualServersTotalRequests.Reset()
 
-	for _, vs := range ns.VPNVirtualServerStats {
+	for _, vs := range virtualServers {
 		totalRequests, _ := strconv.ParseFloat(vs.TotalRequests, 64)
 		e.vpnVirtualServersTotalRequests.WithLabelValues(e.nsInstance, vs.Name).Set(totalRequests)
 	}
 }
 
-func (e *Exporter) collectVPNVirtualServerTotalResponses(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectVPNVirtualServerTotalResponses(virtualServers []netscaler.VPNVirtualServerStats) {
 	e.vpnVirtualServersTotalResponses.Reset()
 
-	for _, vs := range ns.VPNVirtualServerStats {
+	for _, vs := range virtualServers {
 		totalResponses, _ := strconv.ParseFloat(vs.TotalResponses, 64)
 		e.vpnVirtualServersTotalResponses.WithLabelValues(e.nsInstance, vs.Name).Set(totalResponses)
 	}
 }
 
-func (e *Exporter) collectVPNVirtualServerTotalRequestBytes(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectVPNVirtualServerTotalRequestBytes(virtualServers []netscaler.VPNVirtualServerStats) {
 	e.vpnVirtualServersTotalRequestBytes.Reset()
 
-	for _, vs := range ns.VPNVirtualServerStats {
+	for _, vs := range virtualServers {
 		totalRequestBytes, _ := strconv.ParseFloat(vs.TotalRequestBytes, 64)
 		e.vpnVirtualServersTotalRequestBytes.WithLabelValues(e.nsInstance, vs.Name).Set(totalRequestBytes)
 	}
 }
 
-func (e *Exporter) collectVPNVirtualServerTotalResponseBytes(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectVPNVirtualServerTotalResponseBytes(virtualServers []netscaler.VPNVirtualServerStats) {
 	e.vpnVirtualServersTotalResponseBytes.Reset()
 
-	for _, vs := range ns.VPNVirtualServerStats {
+	for _, vs := range virtualServers {
 		totalResponseBytes, _ := strconv.ParseFloat(vs.TotalResponseBytes, 64)
 		e.vpnVirtualServersTotalResponseBytes.WithLabelValues(e.nsInstance, vs.Name).Set(totalResponseBytes)
 	}
 }
 
-func (e *Exporter) collectVPNVirtualServerState(ns netscaler.NSAPIResponse) {
+func (e *Exporter) collectVPNVirtualServerState(virtualServers []netscaler.VPNVirtualServerStats) {
 	e.vpnVirtualServersState.Reset()
 
-	for _, vs := range ns.VPNVirtualServerStats {
+	for _, vs := range virtualServers {
 		state := 0.0
 
 		if vs.State == "UP" {
@@ -113,3 +135,21 @@ func (e *Exporter) collectVPNVirtualServerState(ns netscaler.NSAPIResponse) {
 		e.vpnVirtualServersState.WithLabelValues(e.nsInstance, vs.Name).Set(state)
 	}
 }
+
+func (e *Exporter) collectVPNVirtualServerCurrentUsers(virtualServers []netscaler.VPNVirtualServerStats) {
+	e.vpnVirtualServersCurrentUsers.Reset()
+
+	for _, vs := range virtualServers {
+		currentUsers, _ := strconv.ParseFloat(vs.CurrentUsers, 64)
+		e.vpnVirtualServersCurrentUsers.WithLabelValues(e.nsInstance, vs.Name).Set(currentUsers)
+	}
+}
+
+func (e *Exporter) collectVPNVirtualServerCurrentSSLVPNUsers(virtualServers []netscaler.VPNVirtualServerStats) {
+	e.vpnVirtualServersCurrentSSLVPNUsers.Reset()
+
+	for _, vs := range virtualServers {
+		currentSSLVPNUsers, _ := strconv.ParseFloat(vs.CurrentSSLVPNUsers, 64)
+		e.vpnVirtualServersCurrentSSLVPNUsers.WithLabelValues(e.nsInstance, vs.Name).Set(currentSSLVPNUsers)
+	}
+}
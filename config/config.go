@@ -0,0 +1,240 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Config represents the exporter configuration file
+type Config struct {
+	Targets  []Target  `yaml:"targets"`
+	ADM      []ADM     `yaml:"adm"`
+	Mappings []Mapping `yaml:"mappings"`
+}
+
+// Target is a NetScaler which is polled in the background; scrapes are served from the result of the latest poll rather than querying the NetScaler.
+type Target struct {
+	// URL is the target, as it is passed in the target parameter.
+	URL        string `yaml:"url"`
+	IgnoreCert bool   `yaml:"ignore_cert"`
+	// Interval is the time between polls; defaults to 1m.
+	Interval time.Duration `yaml:"interval"`
+	// MaxStaleness is how long the result of a poll is served for, if later polls do not complete; defaults to three intervals.
+	MaxStaleness time.Duration `yaml:"max_staleness"`
+	// Partitions are the admin partitions to collect from; all collects from every partition.  Defaults to none, which collects from the user's default partition without switching.
+	Partitions []string `yaml:"partitions"`
+	// Labels are added to the target by service discovery; for example site or environment.
+	Labels map[string]string `yaml:"labels"`
+	// Module is the kind of appliance the target is; either adc, for a NetScaler, or sdx, for the Management Service of an SDX appliance.  Defaults to adc.
+	Module string `yaml:"module"`
+}
+
+// Modules which a target can be collected with.
+const (
+	ModuleADC = "adc"
+	ModuleSDX = "sdx"
+)
+
+// ADM is a NetScaler ADM whose managed instances are polled in the background, with every request proxied through ADM.
+// URL and IgnoreCert apply to the connection to ADM; the other settings apply to each instance.
+type ADM struct {
+	Target `yaml:",inline"`
+	// Types limits the instances polled to those of the given types; for example nsvpx or nsmpx.  Defaults to every instance.
+	Types []string `yaml:"types"`
+}
+
+const defaultPollInterval = time.Minute
+
+// Mapping declares a Nitro resource, and how the fields it returns are exported as metrics.
+type Mapping struct {
+	// Resource is the Nitro endpoint to query; either stat/<type> or config/<type>.
+	Resource string `yaml:"resource"`
+	// Filter limits the resources returned to those whose fields match the given values.
+	Filter  map[string]string `yaml:"filter"`
+	Labels  []Label           `yaml:"labels"`
+	Metrics []Metric          `yaml:"metrics"`
+}
+
+// Label maps a field of the Nitro resource to a metric label.
+type Label struct {
+	Name  string `yaml:"name"`
+	Field string `yaml:"field"`
+}
+
+// Metric maps a field of the Nitro resource to a metric.
+type Metric struct {
+	Name  string `yaml:"name"`
+	Help  string `yaml:"help"`
+	Field string `yaml:"field"`
+	// Type is either gauge or counter; defaults to gauge.
+	Type string `yaml:"type"`
+	// ValueMap converts string values, such as UP or DOWN, to numbers.
+	ValueMap map[string]float64 `yaml:"value_map"`
+}
+
+// singletonResources are the Nitro resources which return a single item, so mappings of them need no labels to tell the items apart.
+var singletonResources = map[string]bool{
+	"stat/ns":           true,
+	"stat/system":       true,
+	"stat/aaa":          true,
+	"stat/vpn":          true,
+	"stat/ssl":          true,
+	"stat/protocolhttp": true,
+	"stat/protocoltcp":  true,
+	"stat/protocolip":   true,
+	"stat/protocoludp":  true,
+	"config/nscapacity": true,
+	"config/nshardware": true,
+	"config/nshostname": true,
+	"config/nslicense":  true,
+	"config/nsversion":  true,
+}
+
+var (
+	metricNameRegex = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelNameRegex  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// Load reads and validates the configuration file at path
+func Load(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading config file")
+	}
+
+	var cfg = new(Config)
+
+	err = yaml.UnmarshalStrict(b, cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing config file")
+	}
+
+	err = cfg.validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid config file")
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) validate() error {
+	urls := make(map[string]bool)
+
+	for i := range c.Targets {
+		err := c.Targets[i].validate(fmt.Sprintf("target %d", i), urls)
+		if err != nil {
+			return err
+		}
+	}
+
+	admURLs := make(map[string]bool)
+
+	for i := range c.ADM {
+		err := c.ADM[i].validate(fmt.Sprintf("adm %d", i), admURLs)
+		if err != nil {
+			return err
+		}
+	}
+
+	names := make(map[string]bool)
+
+	for i, m := range c.Mappings {
+		if !strings.HasPrefix(m.Resource, "stat/") && !strings.HasPrefix(m.Resource, "config/") {
+			return errors.Errorf("mapping %d: resource %q must start with stat/ or config/", i, m.Resource)
+		}
+
+		if len(m.Metrics) == 0 {
+			return errors.Errorf("mapping %d (%s): no metrics defined", i, m.Resource)
+		}
+
+		// Without labels, every item a resource returns would give the same series.
+		if len(m.Labels) == 0 && !singletonResources[m.Resource] {
+			return errors.Errorf("mapping %d (%s): no labels defined; a label which tells the items of the resource apart is needed", i, m.Resource)
+		}
+
+		labels := map[string]bool{"ns_instance": true}
+
+		for _, l := range m.Labels {
+			if !labelNameRegex.MatchString(l.Name) || strings.HasPrefix(l.Name, "__") {
+				return errors.Errorf("mapping %d (%s): invalid label name %q", i, m.Resource, l.Name)
+			}
+
+			if labels[l.Name] {
+				return errors.Errorf("mapping %d (%s): duplicate label name %q", i, m.Resource, l.Name)
+			}
+			labels[l.Name] = true
+
+			if l.Field == "" {
+				return errors.Errorf("mapping %d (%s): label %q has no field", i, m.Resource, l.Name)
+			}
+		}
+
+		for _, metric := range m.Metrics {
+			if !metricNameRegex.MatchString(metric.Name) {
+				return errors.Errorf("mapping %d (%s): invalid metric name %q", i, m.Resource, metric.Name)
+			}
+
+			if names[metric.Name] {
+				return errors.Errorf("mapping %d (%s): metric %q is defined more than once", i, m.Resource, metric.Name)
+			}
+			names[metric.Name] = true
+
+			if metric.Field == "" {
+				return errors.Errorf("mapping %d (%s): metric %q has no field", i, m.Resource, metric.Name)
+			}
+
+			if metric.Type != "" && metric.Type != "gauge" && metric.Type != "counter" {
+				return errors.Errorf("mapping %d (%s): metric %q has type %q; must be gauge or counter", i, m.Resource, metric.Name, metric.Type)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validate checks the target, and sets the defaults for any settings which are not given.
+// name identifies the target in errors, and urls holds the URLs of the targets already validated, to catch duplicates.
+func (t *Target) validate(name string, urls map[string]bool) error {
+	if t.URL == "" {
+		return errors.Errorf("%s: no url defined", name)
+	}
+
+	if urls[t.URL] {
+		return errors.Errorf("%s: %s is defined more than once", name, t.URL)
+	}
+	urls[t.URL] = true
+
+	if t.Interval < 0 || t.MaxStaleness < 0 {
+		return errors.Errorf("%s (%s): interval and max_staleness must not be negative", name, t.URL)
+	}
+
+	if t.Interval == 0 {
+		t.Interval = defaultPollInterval
+	}
+
+	if t.MaxStaleness == 0 {
+		t.MaxStaleness = 3 * t.Interval
+	}
+
+	if t.MaxStaleness < t.Interval {
+		return errors.Errorf("%s (%s): max_staleness must be at least the interval", name, t.URL)
+	}
+
+	if t.Module != "" && t.Module != ModuleADC && t.Module != ModuleSDX {
+		return errors.Errorf("%s (%s): module %q must be %s or %s", name, t.URL, t.Module, ModuleADC, ModuleSDX)
+	}
+
+	for label := range t.Labels {
+		if !labelNameRegex.MatchString(label) || strings.HasPrefix(label, "__") {
+			return errors.Errorf("%s (%s): invalid label name %q", name, t.URL, label)
+		}
+	}
+
+	return nil
+}
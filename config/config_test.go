@@ -0,0 +1,233 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfig writes a configuration file into a temporary directory, returning its path.
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+
+	err := os.WriteFile(path, []byte(content), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeConfig(t, `
+targets:
+  - url: https://ns01
+    labels:
+      site: dmz
+  - url: https://ns02
+    interval: 30s
+    partitions: [all]
+adm:
+  - url: https://adm01
+    types: [nsvpx]
+mappings:
+  - resource: stat/lbvserver
+    labels:
+      - name: virtual_server
+        field: name
+    metrics:
+      - name: lb_vserver_up
+        field: state
+        value_map:
+          UP: 1
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cfg.Targets) != 2 || len(cfg.ADM) != 1 || len(cfg.Mappings) != 1 {
+		t.Fatalf("Load() = %+v", cfg)
+	}
+
+	// Defaults are set for settings which are not given.
+	if cfg.Targets[0].Interval != time.Minute || cfg.Targets[0].MaxStaleness != 3*time.Minute {
+		t.Errorf("target 0 interval %s and max_staleness %s, want 1m0s and 3m0s", cfg.Targets[0].Interval, cfg.Targets[0].MaxStaleness)
+	}
+
+	if cfg.Targets[1].Interval != 30*time.Second || cfg.Targets[1].MaxStaleness != 90*time.Second {
+		t.Errorf("target 1 interval %s and max_staleness %s, want 30s and 1m30s", cfg.Targets[1].Interval, cfg.Targets[1].MaxStaleness)
+	}
+
+	if cfg.ADM[0].Interval != time.Minute || cfg.ADM[0].Types[0] != "nsvpx" {
+		t.Errorf("adm 0 = %+v", cfg.ADM[0])
+	}
+
+	if cfg.Mappings[0].Metrics[0].ValueMap["UP"] != 1 {
+		t.Errorf("mapping 0 = %+v", cfg.Mappings[0])
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "unknown field",
+			content: "targets:\n  - url: https://ns01\n    intervl: 30s\n",
+			want:    "error parsing config file",
+		},
+		{
+			name:    "invalid",
+			content: "targets:\n  - url: https://ns01\n    module: vpx\n",
+			want:    "invalid config file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	_, err := Load(filepath.Join(t.TempDir(), "missing.yml"))
+	if err == nil || !strings.Contains(err.Error(), "error reading config file") {
+		t.Errorf("Load() of a missing file error = %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	label := []Label{{Name: "virtual_server", Field: "name"}}
+	metric := []Metric{{Name: "lb_vserver_health", Field: "vslbhealth"}}
+
+	tests := []struct {
+		name string
+		cfg  Config
+		// want is part of the error expected; empty if the config is valid.
+		want string
+	}{
+		{
+			name: "valid",
+			cfg: Config{
+				Targets:  []Target{{URL: "https://ns01", Module: ModuleSDX, Labels: map[string]string{"site": "dmz"}}},
+				ADM:      []ADM{{Target: Target{URL: "https://adm01"}}},
+				Mappings: []Mapping{{Resource: "stat/lbvserver", Labels: label, Metrics: metric}},
+			},
+		},
+		{
+			name: "target without url",
+			cfg:  Config{Targets: []Target{{}}},
+			want: "target 0: no url defined",
+		},
+		{
+			name: "duplicate target",
+			cfg:  Config{Targets: []Target{{URL: "https://ns01"}, {URL: "https://ns01"}}},
+			want: "target 1: https://ns01 is defined more than once",
+		},
+		{
+			name: "negative interval",
+			cfg:  Config{Targets: []Target{{URL: "https://ns01", Interval: -time.Second}}},
+			want: "must not be negative",
+		},
+		{
+			name: "max_staleness below interval",
+			cfg:  Config{Targets: []Target{{URL: "https://ns01", Interval: time.Minute, MaxStaleness: time.Second}}},
+			want: "max_staleness must be at least the interval",
+		},
+		{
+			name: "unknown module",
+			cfg:  Config{Targets: []Target{{URL: "https://ns01", Module: "vpx"}}},
+			want: `module "vpx" must be adc or sdx`,
+		},
+		{
+			name: "invalid target label",
+			cfg:  Config{Targets: []Target{{URL: "https://ns01", Labels: map[string]string{"__site": "dmz"}}}},
+			want: `invalid label name "__site"`,
+		},
+		{
+			name: "duplicate adm",
+			cfg:  Config{ADM: []ADM{{Target: Target{URL: "https://adm01"}}, {Target: Target{URL: "https://adm01"}}}},
+			want: "adm 1: https://adm01 is defined more than once",
+		},
+		{
+			name: "mapping resource",
+			cfg:  Config{Mappings: []Mapping{{Resource: "lbvserver", Labels: label, Metrics: metric}}},
+			want: "must start with stat/ or config/",
+		},
+		{
+			name: "mapping without metrics",
+			cfg:  Config{Mappings: []Mapping{{Resource: "stat/lbvserver", Labels: label}}},
+			want: "no metrics defined",
+		},
+		{
+			name: "mapping without labels",
+			cfg:  Config{Mappings: []Mapping{{Resource: "stat/lbvserver", Metrics: metric}}},
+			want: "no labels defined",
+		},
+		{
+			name: "mapping of a single item without labels",
+			cfg:  Config{Mappings: []Mapping{{Resource: "stat/ns", Metrics: []Metric{{Name: "ns_cpu", Field: "cpuusagepcnt"}}}}},
+		},
+		{
+			name: "invalid label name",
+			cfg:  Config{Mappings: []Mapping{{Resource: "stat/lbvserver", Labels: []Label{{Name: "virtual-server", Field: "name"}}, Metrics: metric}}},
+			want: `invalid label name "virtual-server"`,
+		},
+		{
+			name: "label clashes with ns_instance",
+			cfg:  Config{Mappings: []Mapping{{Resource: "stat/lbvserver", Labels: []Label{{Name: "ns_instance", Field: "name"}}, Metrics: metric}}},
+			want: `duplicate label name "ns_instance"`,
+		},
+		{
+			name: "label without field",
+			cfg:  Config{Mappings: []Mapping{{Resource: "stat/lbvserver", Labels: []Label{{Name: "virtual_server"}}, Metrics: metric}}},
+			want: `label "virtual_server" has no field`,
+		},
+		{
+			name: "invalid metric name",
+			cfg:  Config{Mappings: []Mapping{{Resource: "stat/lbvserver", Labels: label, Metrics: []Metric{{Name: "lb-health", Field: "vslbhealth"}}}}},
+			want: `invalid metric name "lb-health"`,
+		},
+		{
+			name: "metric in two mappings",
+			cfg: Config{Mappings: []Mapping{
+				{Resource: "stat/lbvserver", Labels: label, Metrics: metric},
+				{Resource: "stat/csvserver", Labels: label, Metrics: metric},
+			}},
+			want: `mapping 1 (stat/csvserver): metric "lb_vserver_health" is defined more than once`,
+		},
+		{
+			name: "metric without field",
+			cfg:  Config{Mappings: []Mapping{{Resource: "stat/lbvserver", Labels: label, Metrics: []Metric{{Name: "lb_vserver_health"}}}}},
+			want: `metric "lb_vserver_health" has no field`,
+		},
+		{
+			name: "metric type",
+			cfg:  Config{Mappings: []Mapping{{Resource: "stat/lbvserver", Labels: label, Metrics: []Metric{{Name: "lb_vserver_health", Field: "vslbhealth", Type: "histogram"}}}}},
+			want: `has type "histogram"; must be gauge or counter`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.validate()
+
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("validate() error = %v, want none", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("validate() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rokett/citrix-netscaler-exporter/config"
+	"github.com/rokett/citrix-netscaler-exporter/netscaler"
+)
+
+var (
+	// reportedNSIPs holds the NSIP which each target in the configuration file reported when its peers were last discovered.
+	// Keyed by target URL.
+	reportedNSIPs   = make(map[string]string)
+	reportedNSIPsMu sync.Mutex
+
+	// lookupHost resolves the hostnames of targets; tests replace it.
+	lookupHost = net.LookupHost
+)
+
+// discoverPeers finds the other nodes of the HA pair or cluster which the target belongs to.
+// It returns the URL of each peer, a label identifying the pair or cluster; either ha_pair, made from the NSIPs of both nodes, or cluster_id, and the NSIP of the target itself.
+// A NetScaler which is standalone has no peers and no labels.  Peers which are targets in the configuration file are left out, as they are polled anyway.
+func discoverPeers(t config.Target) ([]string, map[string]string, string, error) {
+	nsURL, user, pass, err := nitroEndpoint(t.URL)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	nsClient, err := netscaler.NewNitroClient(nsURL, user, pass, t.IgnoreCert)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	defer nsClient.CloseIdleConnection()
+
+	err = netscaler.Connect(nsClient)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	defer netscaler.Disconnect(nsClient)
+
+	clusterNodes, err := netscaler.GetClusterNodes(nsClient, netscaler.Query{})
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	if len(clusterNodes) > 0 {
+		cluster, err := netscaler.GetClusterInstance(nsClient, netscaler.Query{})
+		if err != nil {
+			return nil, nil, "", err
+		}
+
+		// The target may be the NSIP of one of the nodes, or the cluster IP, in which case the node which answers is the one being polled.
+		nsConfig, err := netscaler.GetNSConfig(nsClient, netscaler.Query{Attrs: []string{"ipaddress"}})
+		if err != nil {
+			return nil, nil, "", err
+		}
+
+		var peers []string
+		for _, n := range clusterNodes {
+			if sameIP(n.IPAddress, nsConfig.IPAddress) || configuredTarget(n.IPAddress) != "" {
+				continue
+			}
+
+			peers = append(peers, peerURL(t.URL, n.IPAddress))
+		}
+
+		return peers, map[string]string{"cluster_id": cluster.CLID.String()}, nsConfig.IPAddress, nil
+	}
+
+	haNodes, err := netscaler.GetHANodes(nsClient, netscaler.Query{})
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	var nsip string
+	var peers []string
+	var nsips []string
+
+	for _, n := range haNodes {
+		nsips = append(nsips, n.IPAddress)
+
+		// Node 0 is always the NetScaler which was queried.
+		if n.ID.String() == "0" {
+			nsip = n.IPAddress
+			continue
+		}
+
+		if configuredTarget(n.IPAddress) == "" {
+			peers = append(peers, peerURL(t.URL, n.IPAddress))
+		}
+	}
+
+	if len(haNodes) < 2 {
+		return nil, nil, nsip, nil
+	}
+
+	// Sorting means both nodes get the same label, whichever of them was configured.
+	sort.Strings(nsips)
+
+	return peers, map[string]string{"ha_pair": strings.Join(nsips, "-")}, nsip, nil
+}
+
+// setReportedNSIP remembers the NSIP which the target in the configuration file reported, so that its peers do not poll it again by that address.
+func setReportedNSIP(target string, nsip string) {
+	reportedNSIPsMu.Lock()
+	defer reportedNSIPsMu.Unlock()
+
+	reportedNSIPs[target] = nsip
+}
+
+// configuredTarget returns the URL of the target in the configuration file which is the NetScaler with the given IP address, or an empty string if there is none.
+// A target matches if it is addressed by the IP address, if it reported the IP address as its NSIP, or if its hostname resolves to the IP address;
+// so that a NetScaler configured by hostname is not polled a second time at the address which its peers or NetScaler ADM report for it.
+func configuredTarget(ip string) string {
+	reportedNSIPsMu.Lock()
+	nsips := make(map[string]string, len(reportedNSIPs))
+	for target, nsip := range reportedNSIPs {
+		nsips[target] = nsip
+	}
+	reportedNSIPsMu.Unlock()
+
+	for _, t := range cfg.Targets {
+		if sameIP(targetHost(t.URL), ip) || sameIP(nsips[t.URL], ip) {
+			return t.URL
+		}
+	}
+
+	// Hostnames are only resolved if no target matched, as resolving can be slow.
+	for _, t := range cfg.Targets {
+		host := targetHost(t.URL)
+		if host == "" || net.ParseIP(host) != nil {
+			continue
+		}
+
+		addrs, err := lookupHost(host)
+		if err != nil {
+			continue
+		}
+
+		for _, addr := range addrs {
+			if sameIP(addr, ip) {
+				return t.URL
+			}
+		}
+	}
+
+	return ""
+}
+
+// sameIP reports whether a and b are the same IP address, however they are written.
+func sameIP(a string, b string) bool {
+	ipA := net.ParseIP(a)
+
+	return ipA != nil && ipA.Equal(net.ParseIP(b))
+}
+
+// peerURL returns the URL for a peer of the target, using the same scheme and port as the target.
+func peerURL(target string, ip string) string {
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		u = &url.URL{Scheme: "https"}
+	}
+
+	host := ip
+	if port := u.Port(); port != "" {
+		host = net.JoinHostPort(ip, port)
+	} else if strings.Contains(ip, ":") {
+		host = "[" + ip + "]"
+	}
+
+	return (&url.URL{Scheme: u.Scheme, Host: host}).String()
+}
+
+// targetHost returns the hostname or IP address of the target, without the port.
+func targetHost(target string) string {
+	u, err := url.Parse(target)
+	if err != nil {
+		return ""
+	}
+
+	return u.Hostname()
+}
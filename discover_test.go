@@ -0,0 +1,259 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rokett/citrix-netscaler-exporter/config"
+	"github.com/rokett/citrix-netscaler-exporter/netscaler/nitrotest"
+
+	"github.com/go-kit/kit/log"
+)
+
+// stubLookupHost resolves the given hostnames, and no others, for the rest of the test.
+func stubLookupHost(t *testing.T, hosts map[string][]string) {
+	t.Helper()
+
+	lookup := lookupHost
+	lookupHost = func(host string) ([]string, error) {
+		addrs, ok := hosts[host]
+		if !ok {
+			return nil, errors.New("no such host")
+		}
+
+		return addrs, nil
+	}
+
+	t.Cleanup(func() {
+		lookupHost = lookup
+	})
+}
+
+func TestPeerURL(t *testing.T) {
+	tests := []struct {
+		target string
+		ip     string
+		want   string
+	}{
+		{"https://ns01.domain.tld", "10.0.0.2", "https://10.0.0.2"},
+		{"http://ns01.domain.tld:8080", "10.0.0.2", "http://10.0.0.2:8080"},
+		{"https://10.0.0.1/", "10.0.0.2", "https://10.0.0.2"},
+		{"https://ns01.domain.tld", "2001:db8::2", "https://[2001:db8::2]"},
+		{"https://[2001:db8::1]:8443", "2001:db8::2", "https://[2001:db8::2]:8443"},
+		// A target which is not a URL, such as a recording name.
+		{"ns01", "10.0.0.2", "https://10.0.0.2"},
+	}
+
+	for _, tt := range tests {
+		got := peerURL(tt.target, tt.ip)
+		if got != tt.want {
+			t.Errorf("peerURL(%q, %q) = %q, want %q", tt.target, tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestDiscoverPeers(t *testing.T) {
+	user, pass, c := *username, *password, cfg
+	*username, *password = "user", "pass"
+
+	t.Cleanup(func() {
+		*username, *password, cfg = user, pass, c
+
+		reportedNSIPsMu.Lock()
+		reportedNSIPs = make(map[string]string)
+		reportedNSIPsMu.Unlock()
+	})
+
+	haPair := `{"errorcode":0,"message":"Done","hanode":[{"id":0,"ipaddress":"10.0.0.1"},{"id":1,"ipaddress":"10.0.0.2"}]}`
+	cluster := map[string]string{
+		"config/clusternode":     `{"errorcode":0,"message":"Done","clusternode":[{"nodeid":0,"ipaddress":"10.0.1.1"},{"nodeid":1,"ipaddress":"10.0.1.2"},{"nodeid":2,"ipaddress":"10.0.1.3"}]}`,
+		"config/clusterinstance": `{"errorcode":0,"message":"Done","clusterinstance":[{"clid":1}]}`,
+		// Queried through the cluster IP, the node which answers reports its own NSIP.
+		nitrotest.Key("config/nsconfig", "attrs=ipaddress"): `{"errorcode":0,"message":"Done","nsconfig":{"ipaddress":"10.0.1.1"}}`,
+	}
+
+	tests := []struct {
+		name     string
+		fixtures map[string]string
+		// targets are configured alongside the target being discovered from.
+		targets  []string
+		hosts    map[string][]string
+		reported map[string]string
+		// peers are the IP addresses of the peers expected.
+		peers  []string
+		labels map[string]string
+		nsip   string
+	}{
+		{
+			name:     "standalone",
+			fixtures: map[string]string{"config/hanode": `{"errorcode":0,"message":"Done","hanode":[{"id":0,"ipaddress":"10.0.0.1"}]}`},
+			nsip:     "10.0.0.1",
+		},
+		{
+			name:     "ha pair",
+			fixtures: map[string]string{"config/hanode": haPair},
+			peers:    []string{"10.0.0.2"},
+			labels:   map[string]string{"ha_pair": "10.0.0.1-10.0.0.2"},
+			nsip:     "10.0.0.1",
+		},
+		{
+			name:     "ha peer configured by its NSIP",
+			fixtures: map[string]string{"config/hanode": haPair},
+			targets:  []string{"https://10.0.0.2"},
+			labels:   map[string]string{"ha_pair": "10.0.0.1-10.0.0.2"},
+			nsip:     "10.0.0.1",
+		},
+		{
+			name:     "ha peer configured by a hostname which resolves to its NSIP",
+			fixtures: map[string]string{"config/hanode": haPair},
+			targets:  []string{"https://ns02.domain.tld"},
+			hosts:    map[string][]string{"ns02.domain.tld": {"10.0.0.2"}},
+			labels:   map[string]string{"ha_pair": "10.0.0.1-10.0.0.2"},
+			nsip:     "10.0.0.1",
+		},
+		{
+			name:     "ha peer configured by a hostname which reported its NSIP",
+			fixtures: map[string]string{"config/hanode": haPair},
+			targets:  []string{"https://ns02-mgmt.domain.tld"},
+			reported: map[string]string{"https://ns02-mgmt.domain.tld": "10.0.0.2"},
+			labels:   map[string]string{"ha_pair": "10.0.0.1-10.0.0.2"},
+			nsip:     "10.0.0.1",
+		},
+		{
+			name:     "cluster",
+			fixtures: cluster,
+			peers:    []string{"10.0.1.2", "10.0.1.3"},
+			labels:   map[string]string{"cluster_id": "1"},
+			nsip:     "10.0.1.1",
+		},
+		{
+			name:     "cluster node configured by hostname",
+			fixtures: cluster,
+			targets:  []string{"https://node3.domain.tld"},
+			hosts:    map[string][]string{"node3.domain.tld": {"10.0.1.3"}},
+			peers:    []string{"10.0.1.2"},
+			labels:   map[string]string{"cluster_id": "1"},
+			nsip:     "10.0.1.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := nitrotest.NewServer()
+			defer srv.Close()
+
+			for key, fixture := range tt.fixtures {
+				srv.SetFixture(key, []byte(fixture))
+			}
+
+			target := config.Target{URL: srv.URL}
+
+			cfg = &config.Config{Targets: []config.Target{target}}
+			for _, u := range tt.targets {
+				cfg.Targets = append(cfg.Targets, config.Target{URL: u})
+			}
+
+			stubLookupHost(t, tt.hosts)
+
+			reportedNSIPsMu.Lock()
+			reportedNSIPs = make(map[string]string)
+			for u, nsip := range tt.reported {
+				reportedNSIPs[u] = nsip
+			}
+			reportedNSIPsMu.Unlock()
+
+			peers, labels, nsip, err := discoverPeers(target)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var want []string
+			for _, ip := range tt.peers {
+				want = append(want, peerURL(srv.URL, ip))
+			}
+
+			if !reflect.DeepEqual(peers, want) {
+				t.Errorf("peers = %v, want %v", peers, want)
+			}
+
+			if !reflect.DeepEqual(labels, tt.labels) {
+				t.Errorf("labels = %v, want %v", labels, tt.labels)
+			}
+
+			if nsip != tt.nsip {
+				t.Errorf("nsip = %q, want %q", nsip, tt.nsip)
+			}
+		})
+	}
+}
+
+func TestSetDiscovered(t *testing.T) {
+	logger = log.NewNopLogger()
+
+	// Discovered targets are not polled; only which targets are polled is checked.
+	var started []string
+	start := startPolling
+	startPolling = func(pt *polledTarget) {
+		started = append(started, pt.URL)
+	}
+
+	defer func() {
+		startPolling = start
+	}()
+
+	configured := config.Target{URL: "https://ns01.domain.tld", Interval: time.Hour, MaxStaleness: 3 * time.Hour, Labels: map[string]string{"site": "dmz"}}
+	adm := config.Target{URL: "https://adm01.domain.tld", Interval: time.Hour, MaxStaleness: 3 * time.Hour}
+	urls := []string{"https://10.0.0.2", "https://10.0.0.3", "https://10.0.2.1"}
+
+	p := newPoller([]config.Target{configured}, nil)
+
+	haLabels := map[string]string{"ha_pair": "10.0.0.1-10.0.0.2"}
+
+	p.setDiscovered(configured, []string{urls[0], urls[1], configured.URL}, haLabels)
+	p.setDiscovered(adm, []string{urls[2]}, map[string]string{"adm": adm.URL})
+
+	if n := len(p.list()); n != 4 {
+		t.Fatalf("polling %d targets, want 4", n)
+	}
+
+	if !reflect.DeepEqual(started, urls) {
+		t.Errorf("started polling %v, want %v", started, urls)
+	}
+
+	peer := p.target(urls[0])
+	if peer.discoveredBy != configured.URL || peer.Interval != time.Hour || peer.Labels["site"] != "dmz" {
+		t.Errorf("discovered target %+v does not have the settings of the target it was discovered from", peer.Target)
+	}
+
+	// A discovered target which is also configured keeps its own settings, but gets the labels of its pair.
+	if pt := p.target(configured.URL); pt.discoveredBy != "" || !reflect.DeepEqual(pt.sdLabels(), map[string]string{"site": "dmz", "ha_pair": "10.0.0.1-10.0.0.2"}) {
+		t.Errorf("configured target discovered by %q with labels %v", pt.discoveredBy, pt.sdLabels())
+	}
+
+	removed := p.target(urls[1])
+
+	// A target which is no longer found is no longer polled; targets found by other sources, and configured targets, are kept.
+	p.setDiscovered(configured, []string{urls[0]}, haLabels)
+
+	if p.target(urls[1]) != nil {
+		t.Error("still polling a target which is no longer found")
+	}
+
+	select {
+	case <-removed.stop:
+	default:
+		t.Error("polling of the target which is no longer found was not stopped")
+	}
+
+	for _, u := range []string{urls[0], urls[2], configured.URL} {
+		if p.target(u) == nil {
+			t.Errorf("no longer polling %s", u)
+		}
+	}
+
+	if len(started) != len(urls) {
+		t.Errorf("started polling %v again", started[len(urls):])
+	}
+}
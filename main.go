@@ -5,33 +5,66 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 
 	"github.com/rokett/citrix-netscaler-exporter/collector"
+	"github.com/rokett/citrix-netscaler-exporter/config"
 )
 
 var (
-	app        = "Citrix-NetScaler-Exporter"
-	version    string
-	build      string
-	username   = flag.String("username", "", "Username with which to connect to the NetScaler API")
-	password   = flag.String("password", "", "Password with which to connect to the NetScaler API")
-	bindPort   = flag.Int("bind_port", 9280, "Port to bind the exporter endpoint to")
-	versionFlg = flag.Bool("version", false, "Display application version")
-	debugFlg   = flag.Bool("debug", false, "Enable debug logging?")
-	logger     log.Logger
-
-	nsInstance string
+	app                  = "Citrix-NetScaler-Exporter"
+	version              string
+	build                string
+	username             = flag.String("username", "", "Username with which to connect to the NetScaler API")
+	password             = flag.String("password", "", "Password with which to connect to the NetScaler API")
+	admUsername          = flag.String("adm_username", "", "Username with which to connect to the NetScaler ADM API, for instances managed by ADM; defaults to username")
+	admPassword          = flag.String("adm_password", "", "Password with which to connect to the NetScaler ADM API; defaults to password")
+	bindPort             = flag.Int("bind_port", 9280, "Port to bind the exporter endpoint to")
+	versionFlg           = flag.Bool("version", false, "Display application version")
+	debugFlg             = flag.Bool("debug", false, "Enable debug logging?")
+	policyFlt            = flag.String("policy_filter", "", "Regular expression; only policies with a matching name will have their hit counters exported")
+	vpnSessions          = flag.Bool("vpn_sessions", false, "Export per-user NetScaler Gateway sessions?  This can produce a large number of series")
+	vpnSessionsMaxSeries = flag.Int("vpn_sessions_max_series", 500, "Maximum number of NetScaler Gateway sessions to export per target")
+	serviceGroupBulk     = flag.Bool("servicegroup_bulk", false, "Retrieve all service group members in one request, rather than one request per service group?  Falls back automatically on firmware which does not support it")
+	trafficDomains       = flag.Bool("traffic_domains", true, "Label virtual servers, services and service groups with their traffic domain, and collect per traffic domain stats?")
+	minScrapeInterval    = flag.Duration("min_scrape_interval", 0, "Minimum time between collections from the same target; scrapes within this time of the last collection are served its metrics.  Scrapes which arrive while a collection is in progress always share it")
+	discoveryInterval    = flag.Duration("discovery_interval", 5*time.Minute, "Time between checks of each target in the configuration file for HA and cluster peers to poll; 0 disables discovery")
+	replayDir            = flag.String("replay", "", "Serve metrics from the recordings in this directory, rather than from live NetScalers; the target parameter selects the recording")
+	otlpEndpoint         = flag.String("otlp_endpoint", "", "URL of an OTLP receiver, such as the OpenTelemetry Collector, to push the metrics from every poll of the targets in the configuration file to")
+	otlpProtocol         = flag.String("otlp_protocol", "http/protobuf", "Protocol with which to push metrics to the OTLP receiver; grpc or http/protobuf")
+	otlpHeaders          = flag.String("otlp_headers", "", "Comma separated name=value headers to send to the OTLP receiver; for example to authenticate")
+	remoteWriteURL       = flag.String("remote_write_url", "", "URL of a Prometheus remote_write endpoint to push the metrics from every poll of the targets in the configuration file to")
+	remoteWriteUsername  = flag.String("remote_write_username", "", "Username with which to authenticate with the remote_write endpoint, using basic auth")
+	remoteWritePassword  = flag.String("remote_write_password", "", "Password with which to authenticate with the remote_write endpoint, using basic auth")
+	remoteWriteToken     = flag.String("remote_write_bearer_token", "", "Bearer token with which to authenticate with the remote_write endpoint")
+	remoteWriteWALDir    = flag.String("remote_write_wal_dir", "remote_write_wal", "Directory in which to keep metrics until the remote_write endpoint accepts them, so that they survive an outage or restart")
+	remoteWriteWALMaxMB  = flag.Int("remote_write_wal_max_mb", 512, "Maximum size of the remote_write WAL in MB; once reached, the oldest metrics are dropped")
+	remoteWriteBackoff   = flag.Duration("remote_write_max_backoff", 5*time.Minute, "Maximum time between retries of a failed push to the remote_write endpoint")
+	configFile           = flag.String("config", "", "Path to a YAML configuration file declaring additional Nitro metrics and targets to poll in the background")
+	logger               log.Logger
+
+	policyFilter *regexp.Regexp
+
+	cfg = new(config.Config)
+
+	targetPoller *poller
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "record" {
+		os.Exit(record(os.Args[2:]))
+	}
+
 	flag.Parse()
 
 	if *versionFlg {
@@ -39,7 +72,7 @@ func main() {
 		os.Exit(0)
 	}
 
-	if *username == "" || *password == "" {
+	if *replayDir == "" && (*username == "" || *password == "") && (*admUsername == "" || *admPassword == "") {
 		flag.PrintDefaults()
 		os.Exit(1)
 	}
@@ -47,6 +80,86 @@ func main() {
 	logger = log.NewLogfmtLogger(os.Stdout)
 	logger = log.With(logger, "ts", log.DefaultTimestampUTC, "caller", log.DefaultCaller, "app", app, "bind_port", *bindPort, "version", "v"+version, "build", build)
 
+	if *vpnSessionsMaxSeries < 0 {
+		level.Error(logger).Log("msg", "vpn_sessions_max_series must not be negative")
+		os.Exit(1)
+	}
+
+	if *policyFlt != "" {
+		var err error
+
+		policyFilter, err = regexp.Compile(*policyFlt)
+		if err != nil {
+			level.Error(logger).Log("msg", "invalid policy_filter", "err", err)
+			os.Exit(1)
+		}
+	}
+
+	if *configFile != "" {
+		var err error
+
+		cfg, err = config.Load(*configFile)
+		if err != nil {
+			level.Error(logger).Log("msg", err)
+			os.Exit(1)
+		}
+
+		// Registering an exporter catches metric names which clash with the built in metrics, which would otherwise fail every scrape.
+		// The exporter never connects, so the credentials are placeholders.
+		exporter, err := collector.NewExporter("http://localhost", "validate", "validate", false, logger, "", exporterOptions(""))
+		if err != nil {
+			level.Error(logger).Log("msg", err)
+			os.Exit(1)
+		}
+
+		err = prometheus.NewRegistry().Register(exporter)
+		if err != nil {
+			level.Error(logger).Log("msg", "invalid config file", "err", err)
+			os.Exit(1)
+		}
+	}
+
+	prometheus.MustRegister(coalescedScrapes, throttledScrapes)
+
+	if *otlpEndpoint != "" {
+		var err error
+
+		otlpClient, err = newOTLPClient()
+		if err != nil {
+			level.Error(logger).Log("msg", err)
+			os.Exit(1)
+		}
+
+		if len(cfg.Targets) == 0 && len(cfg.ADM) == 0 {
+			level.Warn(logger).Log("msg", "OTLP export only pushes the targets polled from the configuration file, and none are configured")
+		}
+
+		prometheus.MustRegister(otlpExportFailures)
+	}
+
+	if *remoteWriteURL != "" {
+		var err error
+
+		remoteWriteQueue, err = newRemoteWriteQueue()
+		if err != nil {
+			level.Error(logger).Log("msg", err)
+			os.Exit(1)
+		}
+
+		if len(cfg.Targets) == 0 && len(cfg.ADM) == 0 {
+			level.Warn(logger).Log("msg", "remote_write only pushes the targets polled from the configuration file, and none are configured")
+		}
+
+		prometheus.MustRegister(remoteWriteCollector{queue: remoteWriteQueue})
+		remoteWriteQueue.Start()
+	}
+
+	if len(cfg.Targets) > 0 || len(cfg.ADM) > 0 {
+		targetPoller = newPoller(cfg.Targets, cfg.ADM)
+		prometheus.MustRegister(targetPoller)
+		targetPoller.start()
+	}
+
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(`<html>
 				<head><title>Citrix NetScaler Exporter</title></head>
@@ -79,7 +192,9 @@ func main() {
 	})
 
 	http.HandleFunc("/netscaler", handler)
-	http.Handle("/metrics", promhttp.Handler())
+	http.HandleFunc("/sd", sdHandler)
+	// The exporter's own metrics, plus the latest metrics from every polled target.
+	http.Handle("/metrics", promhttp.HandlerFor(prometheus.Gatherers{prometheus.DefaultGatherer, targetPoller}, promhttp.HandlerOpts{}))
 
 	listeningPort := ":" + strconv.Itoa(*bindPort)
 	level.Info(logger).Log("msg", "Listening on port "+listeningPort)
@@ -98,30 +213,131 @@ func handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Targets polled in the background are served from their latest poll.
+	if t := targetPoller.target(target); t != nil {
+		err := t.checkParams(r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(t)
+
+		h := promhttp.HandlerFor(prometheus.Gatherers{registry, prometheus.GathererFunc(t.gather)}, promhttp.HandlerOpts{})
+		h.ServeHTTP(w, r)
+		return
+	}
+
 	ignoreCertCheck := false
 	if strings.ToLower(r.URL.Query().Get("ignore-cert")) == "yes" {
 		ignoreCertCheck = true
 	}
 
-	nsInstance = strings.TrimLeft(target, "https://")
-	nsInstance = strings.TrimLeft(nsInstance, "http://")
-	nsInstance = strings.Trim(nsInstance, " /")
-
 	if *debugFlg {
 		level.Debug(logger).Log("msg", "scraping target", "target", target)
 	}
 
-	exporter, err := collector.NewExporter(target, *username, *password, ignoreCertCheck, logger, nsInstance)
+	// Partitions can be given as a comma separated list, or by repeating the parameter.
+	partitions := splitPartitions(strings.Join(r.URL.Query()["partition"], ","))
+
+	module := r.URL.Query().Get("module")
+	if module == "" {
+		module = config.ModuleADC
+	}
+
+	if module != config.ModuleADC && module != config.ModuleSDX {
+		http.Error(w, "'module' parameter must be "+config.ModuleADC+" or "+config.ModuleSDX, 400)
+		return
+	}
+
+	exporter, err := newExporter(target, module, ignoreCertCheck, partitions)
 	if err != nil {
 		http.Error(w, "Error creating exporter"+err.Error(), 400)
 		level.Error(logger).Log("msg", err)
 		return
 	}
 
-	registry := prometheus.NewRegistry()
-	registry.MustRegister(exporter)
+	families, err := scrape(target, module, ignoreCertCheck, partitions, exporter)
 
-	// Delegate http serving to Prometheus client library, which will call collector.Collect.
-	h := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+	h := promhttp.HandlerFor(prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		return families, err
+	}), promhttp.HandlerOpts{})
 	h.ServeHTTP(w, r)
 }
+
+// newExporter creates an exporter for the target, for the given module.  Partitions only apply to the adc module.
+func newExporter(target string, module string, ignoreCert bool, partitions []string) (prometheus.Collector, error) {
+	url, user, pass, err := nitroEndpoint(target)
+	if err != nil {
+		return nil, err
+	}
+
+	if module == config.ModuleSDX {
+		return collector.NewSDXExporter(url, user, pass, ignoreCert, admProxyInstance(target), logger, targetInstance(target))
+	}
+
+	opts := exporterOptions(admProxyInstance(target))
+	opts.Partitions = partitions
+
+	return collector.NewExporter(url, user, pass, ignoreCert, logger, targetInstance(target), opts)
+}
+
+// exporterOptions returns the exporter options set by flags and the configuration file, for a target proxied through NetScaler ADM to the given instance, if any.
+func exporterOptions(proxyInstance string) collector.Options {
+	return collector.Options{
+		ProxyInstance:        proxyInstance,
+		PolicyFilter:         policyFilter,
+		VPNSessions:          *vpnSessions,
+		VPNSessionsMaxSeries: *vpnSessionsMaxSeries,
+		ServiceGroupBulk:     *serviceGroupBulk,
+		TrafficDomains:       *trafficDomains,
+		Mappings:             cfg.Mappings,
+	}
+}
+
+// nitroEndpoint returns the URL and credentials with which to connect to the target.  When replaying, the target's recording is used instead.
+// Instances managed by NetScaler ADM are connected to through ADM, with ADM's credentials.
+func nitroEndpoint(target string) (string, string, string, error) {
+	if inst, ok := admEndpoint(target); ok {
+		return inst.adm, admUser(), admPass(), nil
+	}
+
+	if *replayDir == "" {
+		return target, *username, *password, nil
+	}
+
+	s, err := replayServer(target)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	// The recording accepts any credentials, so they need not be given when replaying.
+	user, pass := *username, *password
+	if user == "" {
+		user, pass = "replay", "replay"
+	}
+
+	return s.URL, user, pass, nil
+}
+
+// targetInstance returns the value of the ns_instance label for the target; the target without its scheme.
+func targetInstance(target string) string {
+	nsInstance := strings.TrimPrefix(target, "https://")
+	nsInstance = strings.TrimPrefix(nsInstance, "http://")
+	return strings.Trim(nsInstance, " /")
+}
+
+// splitPartitions splits a comma separated list of admin partitions, dropping empty names.
+func splitPartitions(list string) []string {
+	var partitions []string
+
+	for _, p := range strings.Split(list, ",") {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			partitions = append(partitions, p)
+		}
+	}
+
+	return partitions
+}
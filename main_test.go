@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rokett/citrix-netscaler-exporter/config"
+)
+
+func TestTargetInstance(t *testing.T) {
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"https://netscaler.domain.tld", "netscaler.domain.tld"},
+		{"http://10.0.0.1/", "10.0.0.1"},
+		{"https://https-proxy.domain.tld", "https-proxy.domain.tld"},
+		{"http://ns01", "ns01"},
+		// A recording name when replaying.
+		{"hosts", "hosts"},
+		{" spdc-ns01 ", "spdc-ns01"},
+	}
+
+	for _, tt := range tests {
+		got := targetInstance(tt.target)
+		if got != tt.want {
+			t.Errorf("targetInstance(%q) = %q, want %q", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestHandlerPolledTargetParams(t *testing.T) {
+	targetPoller = newPoller([]config.Target{{URL: "https://ns01", Partitions: []string{"tenant1", "tenant2"}}}, nil)
+	defer func() {
+		targetPoller = nil
+	}()
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"target=https://ns01", http.StatusOK},
+		{"target=https://ns01&module=adc", http.StatusOK},
+		{"target=https://ns01&module=sdx", http.StatusBadRequest},
+		{"target=https://ns01&partition=tenant2,tenant1", http.StatusOK},
+		{"target=https://ns01&partition=tenant1&partition=tenant2", http.StatusOK},
+		{"target=https://ns01&partition=tenant1", http.StatusBadRequest},
+		{"target=https://ns01&partition=all", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodGet, "/netscaler?"+tt.query, nil))
+
+		if w.Code != tt.want {
+			t.Errorf("GET /netscaler?%s returned %d, want %d: %s", tt.query, w.Code, tt.want, w.Body.String())
+		}
+	}
+}
@@ -66,6 +66,6 @@ func Connect(c *NitroClient) error {
 	default:
 		body, _ := io.ReadAll(resp.Body)
 
-		return errors.New("read failed: " + resp.Status + " (" + string(body) + ")")
+		return newNitroError(resp, body)
 	}
 }
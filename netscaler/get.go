@@ -0,0 +1,61 @@
+package netscaler
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// get sends a GET request to the Nitro API and returns the response body.
+// If the session has expired, it logs in again, switching back into the current partition, and retries the request once.
+func (c *NitroClient) get(url string) ([]byte, error) {
+	body, err := c.doGet(url)
+	if err != nil && IsSessionExpired(err) {
+		err = Connect(c)
+		if err != nil {
+			return nil, errors.Wrap(err, "error logging in again after session expired")
+		}
+
+		// A new session starts in the default partition.
+		if c.partition != "" {
+			err = SwitchPartition(c, c.partition)
+			if err != nil {
+				return nil, errors.Wrap(err, "error switching partition after session expired")
+			}
+		}
+
+		body, err = c.doGet(url)
+	}
+
+	return body, err
+}
+
+func (c *NitroClient) doGet(url string) ([]byte, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating HTTP request")
+	}
+
+	req.Header.Set("Accept", "application/json")
+	c.setProxyHeader(req)
+
+	resp, err := c.client.Do(req)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "error sending request")
+	}
+
+	switch resp.StatusCode {
+	case 200:
+		body, _ := io.ReadAll(resp.Body)
+
+		return body, checkErrorcode(resp, body)
+	default:
+		body, _ := io.ReadAll(resp.Body)
+
+		return body, newNitroError(resp, body)
+	}
+}
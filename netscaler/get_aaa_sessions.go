@@ -0,0 +1,19 @@
+package netscaler
+
+import "encoding/json"
+
+// AAASession represents the data returned from the /config/aaasession Nitro API endpoint
+type AAASession struct {
+	Username   string      `json:"username"`
+	GroupName  string      `json:"groupname"`
+	PublicIP   string      `json:"publicip"`
+	PublicPort json.Number `json:"publicport"`
+	IPAddress  string      `json:"ipaddress"`
+	Port       json.Number `json:"port"`
+	IntranetIP string      `json:"intranetip"`
+}
+
+// GetAAASessions queries the Nitro API for active AAA sessions
+func GetAAASessions(c *NitroClient, q Query) ([]AAASession, error) {
+	return GetConfigList[AAASession](c, "aaasession", q)
+}
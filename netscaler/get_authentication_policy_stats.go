@@ -0,0 +1,13 @@
+package netscaler
+
+// AuthenticationPolicyStats represents the data returned from the /stat/authenticationpolicy Nitro API endpoint
+type AuthenticationPolicyStats struct {
+	Name          string `json:"name"`
+	Hits          string `json:"pipolicyhits"`
+	UndefinedHits string `json:"pipolicyundefhits"`
+}
+
+// GetAuthenticationPolicyStats queries the Nitro API for authentication policy stats
+func GetAuthenticationPolicyStats(c *NitroClient, q Query) ([]AuthenticationPolicyStats, error) {
+	return GetStat[AuthenticationPolicyStats](c, "authenticationpolicy", q)
+}
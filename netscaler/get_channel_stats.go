@@ -0,0 +1,13 @@
+package netscaler
+
+// ChannelStats represents the data returned from the /stat/channel Nitro API endpoint
+type ChannelStats struct {
+	ID        string `json:"id"`
+	Alias     string `json:"interfacealias"`
+	LinkState string `json:"curlinkstate"`
+}
+
+// GetChannelStats queries the Nitro API for channel (link aggregation) stats
+func GetChannelStats(c *NitroClient, q Query) ([]ChannelStats, error) {
+	return GetStat[ChannelStats](c, "channel", q)
+}
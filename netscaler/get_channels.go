@@ -0,0 +1,17 @@
+package netscaler
+
+// Channel represents the data returned from the /config/channel Nitro API endpoint
+type Channel struct {
+	ID          string   `json:"id"`
+	Alias       string   `json:"ifalias"`
+	Members     []string `json:"ifnum"`
+	State       string   `json:"state"`
+	LinkState   string   `json:"linkstate"`
+	ActualSpeed string   `json:"actspeed"`
+	LACPMode    string   `json:"lacpmode"`
+}
+
+// GetChannels queries the Nitro API for channel (link aggregation) config
+func GetChannels(c *NitroClient, q Query) ([]Channel, error) {
+	return GetConfigList[Channel](c, "channel", q)
+}
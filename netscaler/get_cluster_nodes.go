@@ -0,0 +1,31 @@
+package netscaler
+
+import "encoding/json"
+
+// ClusterNode represents the data returned from the /config/clusternode Nitro API endpoint
+type ClusterNode struct {
+	NodeID    json.Number `json:"nodeid"`
+	IPAddress string      `json:"ipaddress"`
+	State     string      `json:"state"`
+	Health    string      `json:"health"`
+}
+
+// ClusterInstance represents the data returned from the /config/clusterinstance Nitro API endpoint
+type ClusterInstance struct {
+	CLID json.Number `json:"clid"`
+}
+
+// GetClusterNodes queries the Nitro API for the nodes of the cluster.  No nodes are returned if the NetScaler is not part of a cluster.
+func GetClusterNodes(c *NitroClient, q Query) ([]ClusterNode, error) {
+	return GetConfigList[ClusterNode](c, "clusternode", q)
+}
+
+// GetClusterInstance queries the Nitro API for the cluster instance
+func GetClusterInstance(c *NitroClient, q Query) (ClusterInstance, error) {
+	cfg, err := GetConfigList[ClusterInstance](c, "clusterinstance", q)
+	if err != nil {
+		return ClusterInstance{}, err
+	}
+
+	return first(cfg), nil
+}
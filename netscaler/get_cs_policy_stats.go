@@ -0,0 +1,12 @@
+package netscaler
+
+// CSPolicyStats represents the data returned from the /stat/cspolicy Nitro API endpoint
+type CSPolicyStats struct {
+	Name string `json:"name"`
+	Hits string `json:"pipolicyhits"`
+}
+
+// GetCSPolicyStats queries the Nitro API for Content Switching policy stats
+func GetCSPolicyStats(c *NitroClient, q Query) ([]CSPolicyStats, error) {
+	return GetStat[CSPolicyStats](c, "cspolicy", q)
+}
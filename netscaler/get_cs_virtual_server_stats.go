@@ -1,11 +1,5 @@
 package netscaler
 
-import (
-	"encoding/json"
-
-	"github.com/pkg/errors"
-)
-
 // CSVirtualServerStats represents the data returned from the /stat/csvserver Nitro API endpoint
 type CSVirtualServerStats struct {
 	Name                          string `json:"name"`
@@ -30,18 +24,6 @@ type CSVirtualServerStats struct {
 }
 
 // GetCSVirtualServerStats queries the Nitro API for Content Switching virtual server stats
-func GetCSVirtualServerStats(c *NitroClient, querystring string) (NSAPIResponse, error) {
-	stats, err := c.GetStats("csvserver", querystring)
-	if err != nil {
-		return NSAPIResponse{}, err
-	}
-
-	var response = new(NSAPIResponse)
-
-	err = json.Unmarshal(stats, &response)
-	if err != nil {
-		return NSAPIResponse{}, errors.Wrap(err, "error unmarshalling response body")
-	}
-
-	return *response, nil
+func GetCSVirtualServerStats(c *NitroClient, q Query) ([]CSVirtualServerStats, error) {
+	return GetStat[CSVirtualServerStats](c, "csvserver", q)
 }
@@ -1,11 +1,5 @@
 package netscaler
 
-import (
-	"encoding/json"
-
-	"github.com/pkg/errors"
-)
-
 // GSLBServiceStats represents the data returned from the /stat/gslbservice Nitro API endpoint
 type GSLBServiceStats struct {
 	Name                     string `json:"servicename"`
@@ -22,18 +16,6 @@ type GSLBServiceStats struct {
 }
 
 // GetGSLBServiceStats queries the Nitro API for service stats
-func GetGSLBServiceStats(c *NitroClient, querystring string) (NSAPIResponse, error) {
-	stats, err := c.GetStats("gslbservice", querystring)
-	if err != nil {
-		return NSAPIResponse{}, err
-	}
-
-	var response = new(NSAPIResponse)
-
-	err = json.Unmarshal(stats, &response)
-	if err != nil {
-		return NSAPIResponse{}, errors.Wrap(err, "error unmarshalling response body")
-	}
-
-	return *response, nil
+func GetGSLBServiceStats(c *NitroClient, q Query) ([]GSLBServiceStats, error) {
+	return GetStat[GSLBServiceStats](c, "gslbservice", q)
 }
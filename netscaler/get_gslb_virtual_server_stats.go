@@ -1,11 +1,5 @@
 package netscaler
 
-import (
-	"encoding/json"
-
-	"github.com/pkg/errors"
-)
-
 // GSLBVirtualServerStats represents the data returned from the /stat/gslbvserver Nitro API endpoint
 type GSLBVirtualServerStats struct {
 	Name                     string `json:"name"`
@@ -23,18 +17,6 @@ type GSLBVirtualServerStats struct {
 }
 
 // GetGSLBVirtualServerStats queries the Nitro API for virtual server stats
-func GetGSLBVirtualServerStats(c *NitroClient, querystring string) (NSAPIResponse, error) {
-	stats, err := c.GetStats("gslbvserver", querystring)
-	if err != nil {
-		return NSAPIResponse{}, err
-	}
-
-	var response = new(NSAPIResponse)
-
-	err = json.Unmarshal(stats, &response)
-	if err != nil {
-		return NSAPIResponse{}, errors.Wrap(err, "error unmarshalling response body")
-	}
-
-	return *response, nil
+func GetGSLBVirtualServerStats(c *NitroClient, q Query) ([]GSLBVirtualServerStats, error) {
+	return GetStat[GSLBVirtualServerStats](c, "gslbvserver", q)
 }
@@ -0,0 +1,18 @@
+package netscaler
+
+import "encoding/json"
+
+// HANode represents the data returned from the /config/hanode Nitro API endpoint.
+// The node being queried is always ID 0; its peer, if it is in an HA pair, is ID 1.
+type HANode struct {
+	ID        json.Number `json:"id"`
+	Name      string      `json:"name"`
+	IPAddress string      `json:"ipaddress"`
+	State     string      `json:"state"`
+	HAStatus  string      `json:"hastatus"`
+}
+
+// GetHANodes queries the Nitro API for the nodes of the HA pair
+func GetHANodes(c *NitroClient, q Query) ([]HANode, error) {
+	return GetConfigList[HANode](c, "hanode", q)
+}
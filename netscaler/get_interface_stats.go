@@ -1,37 +1,34 @@
 package netscaler
 
-import (
-	"encoding/json"
-
-	"github.com/pkg/errors"
-)
-
 // InterfaceStats represents the data returned from the /stat/interface Nitro API endpoint
 type InterfaceStats struct {
-	ID                      string `json:"id"`
-	TotalReceivedBytes      string `json:"totrxbytes"`
-	TotalTransmitBytes      string `json:"tottxbytes"`
-	TotalReceivedPackets    string `json:"totrxpkts"`
-	TotalTransmitPackets    string `json:"tottxpkts"`
-	JumboPacketsReceived    string `json:"jumbopktsreceived"`
-	JumboPacketsTransmitted string `json:"jumbopktstransmitted"`
-	ErrorPacketsReceived    string `json:"errpktrx"`
-	Alias                   string `json:"interfacealias"`
+	ID                        string  `json:"id"`
+	TotalReceivedBytes        string  `json:"totrxbytes"`
+	TotalTransmitBytes        string  `json:"tottxbytes"`
+	TotalReceivedPackets      string  `json:"totrxpkts"`
+	TotalTransmitPackets      string  `json:"tottxpkts"`
+	JumboPacketsReceived      string  `json:"jumbopktsreceived"`
+	JumboPacketsTransmitted   string  `json:"jumbopktstransmitted"`
+	ErrorPacketsReceived      string  `json:"errpktrx"`
+	ErrorPacketsTransmitted   string  `json:"errpkttx"`
+	InboundDiscards           string  `json:"errifindiscards"`
+	OutboundDiscards          string  `json:"nicerrifoutdiscards"`
+	DroppedPacketsReceived    string  `json:"errdroppedrxpkts"`
+	DroppedPacketsTransmitted string  `json:"errdroppedtxpkts"`
+	CRCErrorsReceived         string  `json:"rxcrcerrors"`
+	LinkHangs                 string  `json:"errlinkhangs"`
+	LinkReinitialisations     string  `json:"linkreinits"`
+	ErrorDisables             string  `json:"nicerrdisables"`
+	TransmitStalls            string  `json:"nictxstalls"`
+	ReceiveStalls             string  `json:"nicrxstalls"`
+	LinkDowns                 string  `json:"errlinkdowns"`
+	BandwidthLimitDrops       string  `json:"errbwlimitdrops"`
+	ReceivedBytesRate         float64 `json:"rxbytesrate"`
+	TransmitBytesRate         float64 `json:"txbytesrate"`
+	Alias                     string  `json:"interfacealias"`
 }
 
 // GetInterfaceStats queries the Nitro API for interface stats
-func GetInterfaceStats(c *NitroClient, querystring string) (NSAPIResponse, error) {
-	stats, err := c.GetStats("interface", querystring)
-	if err != nil {
-		return NSAPIResponse{}, err
-	}
-
-	var response = new(NSAPIResponse)
-
-	err = json.Unmarshal(stats, &response)
-	if err != nil {
-		return NSAPIResponse{}, errors.Wrap(err, "error unmarshalling response body")
-	}
-
-	return *response, nil
+func GetInterfaceStats(c *NitroClient, q Query) ([]InterfaceStats, error) {
+	return GetStat[InterfaceStats](c, "interface", q)
 }
@@ -0,0 +1,28 @@
+package netscaler
+
+import "encoding/json"
+
+// Interface represents the data returned from the /config/interface Nitro API endpoint
+type Interface struct {
+	ID                      string      `json:"id"`
+	Alias                   string      `json:"ifalias"`
+	Type                    string      `json:"intftype"`
+	State                   string      `json:"state"`
+	LinkState               string      `json:"linkstate"`
+	ActualSpeed             string      `json:"actspeed"`
+	ActualDuplex            string      `json:"actduplex"`
+	ActualMTU               string      `json:"actualmtu"`
+	LACPMode                string      `json:"lacpmode"`
+	LACPKey                 json.Number `json:"lacpkey"`
+	LACPActorInSync         string      `json:"lacpactorinsync"`
+	LACPActorCollecting     string      `json:"lacpactorcollecting"`
+	LACPActorDistributing   string      `json:"lacpactordistributing"`
+	LACPPartnerInSync       string      `json:"lacppartnerinsync"`
+	LACPPartnerCollecting   string      `json:"lacppartnercollecting"`
+	LACPPartnerDistributing string      `json:"lacppartnerdistributing"`
+}
+
+// GetInterfaces queries the Nitro API for interface config
+func GetInterfaces(c *NitroClient, q Query) ([]Interface, error) {
+	return GetConfigList[Interface](c, "interface", q)
+}
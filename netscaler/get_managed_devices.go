@@ -0,0 +1,20 @@
+package netscaler
+
+// ADMProxyHeader is the header which tells NetScaler ADM to forward a Nitro request to the managed instance with the IP address given as its value.
+const ADMProxyHeader = "_MPS_API_PROXY_MANAGED_INSTANCE_IP"
+
+// ManagedDevice represents the data returned from the /config/managed_device Nitro API endpoint of NetScaler ADM.
+// Each managed device is an instance which ADM can proxy requests to; see ProxyThroughADM.
+type ManagedDevice struct {
+	ID            string `json:"id"`
+	IPAddress     string `json:"ip_address"`
+	Hostname      string `json:"hostname"`
+	DisplayName   string `json:"display_name"`
+	Type          string `json:"type"`
+	InstanceState string `json:"instance_state"`
+}
+
+// GetManagedDevices queries the Nitro API of NetScaler ADM for the instances it manages
+func GetManagedDevices(c *NitroClient, q Query) ([]ManagedDevice, error) {
+	return GetConfigList[ManagedDevice](c, "managed_device", q)
+}
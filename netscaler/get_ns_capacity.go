@@ -0,0 +1,27 @@
+package netscaler
+
+import "encoding/json"
+
+// NSCapacity represents the data returned from the /config/nscapacity Nitro API endpoint
+type NSCapacity struct {
+	Bandwidth       json.Number `json:"bandwidth"`
+	ActualBandwidth string      `json:"actualbandwidth"`
+	MaxBandwidth    string      `json:"maxbandwidth"`
+	MinBandwidth    string      `json:"minbandwidth"`
+	Unit            string      `json:"unit"`
+	Edition         string      `json:"edition"`
+	Platform        string      `json:"platform"`
+	VCPUCount       string      `json:"vcpucount"`
+	MaxVCPUCount    string      `json:"maxvcpucount"`
+	InstanceCount   string      `json:"instancecount"`
+}
+
+// GetNSCapacity queries the Nitro API for pooled capacity license allocations
+func GetNSCapacity(c *NitroClient, q Query) (NSCapacity, error) {
+	cfg, err := GetConfigList[NSCapacity](c, "nscapacity", q)
+	if err != nil {
+		return NSCapacity{}, err
+	}
+
+	return first(cfg), nil
+}
@@ -0,0 +1,16 @@
+package netscaler
+
+// NSConfig represents the data returned from the /config/nsconfig Nitro API endpoint
+type NSConfig struct {
+	IPAddress string `json:"ipaddress"`
+}
+
+// GetNSConfig queries the Nitro API for the NetScaler's configuration; IPAddress is the NSIP of the NetScaler which answered
+func GetNSConfig(c *NitroClient, q Query) (NSConfig, error) {
+	cfg, err := GetConfigList[NSConfig](c, "nsconfig", q)
+	if err != nil {
+		return NSConfig{}, err
+	}
+
+	return first(cfg), nil
+}
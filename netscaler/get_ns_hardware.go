@@ -0,0 +1,20 @@
+package netscaler
+
+// NSHardware represents the data returned from the /config/nshardware Nitro API endpoint
+type NSHardware struct {
+	Description  string `json:"hwdescription"`
+	SystemID     string `json:"sysid"`
+	HostID       string `json:"hostid"`
+	SerialNumber string `json:"serialno"`
+	UUID         string `json:"netscaleruuid"`
+}
+
+// GetNSHardware queries the Nitro API for hardware details
+func GetNSHardware(c *NitroClient, q Query) (NSHardware, error) {
+	cfg, err := GetConfigList[NSHardware](c, "nshardware", q)
+	if err != nil {
+		return NSHardware{}, err
+	}
+
+	return first(cfg), nil
+}
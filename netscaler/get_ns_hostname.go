@@ -0,0 +1,12 @@
+package netscaler
+
+// NSHostname represents the data returned from the /config/nshostname Nitro API endpoint.
+// Nitro returns a list, with one entry per node when queried via a cluster IP.
+type NSHostname struct {
+	Hostname string `json:"hostname"`
+}
+
+// GetNSHostname queries the Nitro API for the configured hostname
+func GetNSHostname(c *NitroClient, q Query) ([]NSHostname, error) {
+	return GetConfigList[NSHostname](c, "nshostname", q)
+}
@@ -1,29 +1,81 @@
 package netscaler
 
-import (
-	"encoding/json"
-
-	"github.com/pkg/errors"
-)
+import "encoding/json"
 
 // NSLicense represents the data returned from the /config/nslicense Nitro API endpoint
 type NSLicense struct {
-	ModelID string `json:"modelid"`
+	ModelID          string          `json:"modelid"`
+	LicensingMode    string          `json:"licensingmode"`
+	DaysToExpiration string          `json:"daystoexpiration"`
+	Edition          string          `json:"-"`
+	Features         map[string]bool `json:"-"`
 }
 
-// GetNSLicense queries the Nitro API for license config
-func GetNSLicense(c *NitroClient, querystring string) (NSAPIResponse, error) {
-	cfg, err := c.GetConfig("nslicense", querystring)
+// licenseEditions maps the fields which flag the license edition to the name of the edition.
+// They are not features, so are not included in Features.
+var licenseEditions = map[string]string{
+	"isplatinumlic":   "platinum",
+	"isenterpriselic": "enterprise",
+	"isstandardlic":   "standard",
+}
+
+// UnmarshalJSON decodes the license, collecting every true/false field into Features, apart from the edition flags which set Edition.
+// The set of licensed features changes between firmware versions, so they are not listed individually.
+func (l *NSLicense) UnmarshalJSON(data []byte) error {
+	type license NSLicense
+
+	var lic license
+
+	err := json.Unmarshal(data, &lic)
+	if err != nil {
+		return err
+	}
+
+	var fields map[string]interface{}
+
+	err = json.Unmarshal(data, &fields)
 	if err != nil {
-		return NSAPIResponse{}, err
+		return err
 	}
 
-	var response = new(NSAPIResponse)
+	lic.Features = make(map[string]bool)
 
-	err = json.Unmarshal(cfg, &response)
+	for k, v := range fields {
+		var enabled bool
+
+		switch val := v.(type) {
+		case bool:
+			enabled = val
+		case string:
+			if val != "true" && val != "false" {
+				continue
+			}
+			enabled = val == "true"
+		default:
+			continue
+		}
+
+		if edition, ok := licenseEditions[k]; ok {
+			if enabled {
+				lic.Edition = edition
+			}
+			continue
+		}
+
+		lic.Features[k] = enabled
+	}
+
+	*l = NSLicense(lic)
+
+	return nil
+}
+
+// GetNSLicense queries the Nitro API for license config
+func GetNSLicense(c *NitroClient, q Query) (NSLicense, error) {
+	cfg, err := GetConfigList[NSLicense](c, "nslicense", q)
 	if err != nil {
-		return NSAPIResponse{}, errors.Wrap(err, "error unmarshalling response body")
+		return NSLicense{}, err
 	}
 
-	return *response, nil
+	return first(cfg), nil
 }
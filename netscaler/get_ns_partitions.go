@@ -0,0 +1,30 @@
+package netscaler
+
+import "encoding/json"
+
+// NSPartition represents the data returned from the /config/nspartition Nitro API endpoint
+type NSPartition struct {
+	Name string `json:"partitionname"`
+}
+
+// NSPartitionStats represents the data returned from the /stat/nspartition Nitro API endpoint.
+// Bandwidth is in Kbps and memory in MB; a maximum of 0 means the partition is not limited.
+type NSPartitionStats struct {
+	Name               string      `json:"partitionname"`
+	CurrentBandwidth   json.Number `json:"currentbandwidth"`
+	MaxBandwidth       json.Number `json:"maxbandwidth"`
+	CurrentConnections json.Number `json:"currentconnections"`
+	MaxConnections     json.Number `json:"maxconnections"`
+	MemoryUsagePercent json.Number `json:"memoryusagepcnt"`
+	MaxMemory          json.Number `json:"maxmemory"`
+}
+
+// GetNSPartitions queries the Nitro API for the admin partitions
+func GetNSPartitions(c *NitroClient, q Query) ([]NSPartition, error) {
+	return GetConfigList[NSPartition](c, "nspartition", q)
+}
+
+// GetNSPartitionStats queries the Nitro API for the resource usage of each admin partition
+func GetNSPartitionStats(c *NitroClient, q Query) ([]NSPartitionStats, error) {
+	return GetStat[NSPartitionStats](c, "nspartition", q)
+}
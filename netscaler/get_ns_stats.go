@@ -1,11 +1,5 @@
 package netscaler
 
-import (
-	"encoding/json"
-
-	"github.com/pkg/errors"
-)
-
 // NSStats represents the data returned from the /stat/ns Nitro API endpoint
 type NSStats struct {
 	CPUUsagePcnt                           float64 `json:"cpuusagepcnt"`
@@ -22,21 +16,15 @@ type NSStats struct {
 	TCPCurrentClientConnectionsEstablished string  `json:"tcpcurclientconnestablished"`
 	TCPCurrentServerConnections            string  `json:"tcpcurserverconn"`
 	TCPCurrentServerConnectionsEstablished string  `json:"tcpcurserverconnestablished"`
+	StartTime                              string  `json:"starttime"`
 }
 
 // GetNSStats queries the Nitro API for ns stats
-func GetNSStats(c *NitroClient, querystring string) (NSAPIResponse, error) {
-	stats, err := c.GetStats("ns", querystring)
-	if err != nil {
-		return NSAPIResponse{}, err
-	}
-
-	var response = new(NSAPIResponse)
-
-	err = json.Unmarshal(stats, &response)
+func GetNSStats(c *NitroClient, q Query) (NSStats, error) {
+	stats, err := GetStat[NSStats](c, "ns", q)
 	if err != nil {
-		return NSAPIResponse{}, errors.Wrap(err, "error unmarshalling response body")
+		return NSStats{}, err
 	}
 
-	return *response, nil
+	return first(stats), nil
 }
@@ -0,0 +1,17 @@
+package netscaler
+
+// NSVersion represents the data returned from the /config/nsversion Nitro API endpoint
+type NSVersion struct {
+	Version string `json:"version"`
+	Mode    string `json:"mode"`
+}
+
+// GetNSVersion queries the Nitro API for the firmware version
+func GetNSVersion(c *NitroClient, q Query) (NSVersion, error) {
+	cfg, err := GetConfigList[NSVersion](c, "nsversion", q)
+	if err != nil {
+		return NSVersion{}, err
+	}
+
+	return first(cfg), nil
+}
@@ -0,0 +1,13 @@
+package netscaler
+
+// ResponderPolicyStats represents the data returned from the /stat/responderpolicy Nitro API endpoint
+type ResponderPolicyStats struct {
+	Name          string `json:"name"`
+	Hits          string `json:"pipolicyhits"`
+	UndefinedHits string `json:"pipolicyundefhits"`
+}
+
+// GetResponderPolicyStats queries the Nitro API for responder policy stats
+func GetResponderPolicyStats(c *NitroClient, q Query) ([]ResponderPolicyStats, error) {
+	return GetStat[ResponderPolicyStats](c, "responderpolicy", q)
+}
@@ -0,0 +1,13 @@
+package netscaler
+
+// RewritePolicyStats represents the data returned from the /stat/rewritepolicy Nitro API endpoint
+type RewritePolicyStats struct {
+	Name          string `json:"name"`
+	Hits          string `json:"pipolicyhits"`
+	UndefinedHits string `json:"pipolicyundefhits"`
+}
+
+// GetRewritePolicyStats queries the Nitro API for rewrite policy stats
+func GetRewritePolicyStats(c *NitroClient, q Query) ([]RewritePolicyStats, error) {
+	return GetStat[RewritePolicyStats](c, "rewritepolicy", q)
+}
@@ -0,0 +1,38 @@
+package netscaler
+
+import "encoding/json"
+
+// SDXInstance represents the data returned from the /config/ns Nitro API endpoint of an SDX Management Service; one per VPX instance on the appliance.
+// The Management Service returns numbers as strings or numbers depending on the release, so they are decoded as json.Number.
+type SDXInstance struct {
+	Name             string      `json:"name"`
+	IPAddress        string      `json:"ip_address"`
+	Hostname         string      `json:"hostname"`
+	VMState          string      `json:"vm_state"`
+	InstanceState    string      `json:"instance_state"`
+	HAMasterState    string      `json:"ha_master_state"`
+	NetScalerVersion string      `json:"netscaler_version"`
+	Cores            json.Number `json:"number_of_cores"`
+	SSLCores         json.Number `json:"number_of_ssl_cores"`
+	Memory           json.Number `json:"memory_total"`
+	Throughput       json.Number `json:"throughput"`
+	PPS              json.Number `json:"pps"`
+	CPUUsage         json.Number `json:"ns_cpu_usage"`
+	MemoryUsage      json.Number `json:"ns_memory_usage"`
+}
+
+// SDXInterface represents the data returned from the /config/xen_health_interface Nitro API endpoint of an SDX Management Service
+type SDXInterface struct {
+	Port  string `json:"port"`
+	State string `json:"state"`
+}
+
+// GetSDXInstances queries the Nitro API of an SDX Management Service for its VPX instances
+func GetSDXInstances(c *NitroClient, q Query) ([]SDXInstance, error) {
+	return GetConfigList[SDXInstance](c, "ns", q)
+}
+
+// GetSDXInterfaces queries the Nitro API of an SDX Management Service for the health of its physical interfaces
+func GetSDXInterfaces(c *NitroClient, q Query) ([]SDXInterface, error) {
+	return GetConfigList[SDXInterface](c, "xen_health_interface", q)
+}
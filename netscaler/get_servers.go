@@ -0,0 +1,17 @@
+package netscaler
+
+import "encoding/json"
+
+// Server represents the data returned from the /config/server Nitro API endpoint.
+// Servers in the default traffic domain are returned without a td attribute.
+type Server struct {
+	Name      string      `json:"name"`
+	IPAddress string      `json:"ipaddress"`
+	State     string      `json:"state"`
+	TD        json.Number `json:"td"`
+}
+
+// GetServers queries the Nitro API for server config
+func GetServers(c *NitroClient, q Query) ([]Server, error) {
+	return GetConfigList[Server](c, "server", q)
+}
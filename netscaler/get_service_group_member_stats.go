@@ -1,12 +1,5 @@
 package netscaler
 
-import (
-	"encoding/json"
-	"fmt"
-
-	"github.com/pkg/errors"
-)
-
 // ServiceGroupMemberStats represents the data returned from the /stat/servicegroupmember Nitro API endpoint
 type ServiceGroupMemberStats struct {
 	PrimaryPort                  int    `json:"primaryport"`
@@ -24,22 +17,18 @@ type ServiceGroupMemberStats struct {
 	MaxClients                   string `json:"maxclients"`
 	PrimaryIPAddress             string `json:"primaryipaddress"`
 	ServiceGroupName             string `json:"servicegroupname"`
+	ServerName                   string `json:"servername"`
+	// TD is the traffic domain of the service group.  The stat API does not return it; it is filled in from the service group config.
+	TD string `json:"-"`
 }
 
-// GetServiceGroupMemberStats queries the Nitro API for servicegroup member stats
-func GetServiceGroupMemberStats(c *NitroClient, querystring string) (NSAPIResponse, error) {
-	q := fmt.Sprintf("servicegroup/%s", querystring)
-	stats, err := c.GetStats(q, "statbindings=yes")
-	if err != nil {
-		return NSAPIResponse{}, err
-	}
-
-	var response = new(NSAPIResponse)
-
-	err = json.Unmarshal(stats, &response)
-	if err != nil {
-		return NSAPIResponse{}, errors.Wrap(err, "error unmarshalling response body")
-	}
+// GetServiceGroupMemberStats queries the Nitro API for the stats of the members of the named service group
+func GetServiceGroupMemberStats(c *NitroClient, name string) ([]ServiceGroups, error) {
+	return GetStat[ServiceGroups](c, "servicegroup/"+name, Query{StatBindings: true})
+}
 
-	return *response, nil
+// GetAllServiceGroupMemberStats queries the Nitro API for the stats of every service group member in a single request.
+// Older firmware requires the service group to be named, in which case a NitroError is returned and GetServiceGroupMemberStats must be used for each service group instead.
+func GetAllServiceGroupMemberStats(c *NitroClient) ([]ServiceGroupMemberStats, error) {
+	return GetStat[ServiceGroupMemberStats](c, "servicegroupmember", Query{})
 }
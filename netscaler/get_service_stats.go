@@ -1,11 +1,5 @@
 package netscaler
 
-import (
-	"encoding/json"
-
-	"github.com/pkg/errors"
-)
-
 // ServiceStats represents the data returned from the /stat/service Nitro API endpoint
 type ServiceStats struct {
 	Name                         string  `json:"name"`
@@ -25,21 +19,13 @@ type ServiceStats struct {
 	CurrentLoad                  string  `json:"curload"`
 	ServiceHits                  string  `json:"vsvrservicehits"`
 	ActiveTransactions           string  `json:"activetransactions"`
+	PrimaryIPAddress             string  `json:"primaryipaddress"`
+	PrimaryPort                  int     `json:"primaryport"`
+	// TD is the traffic domain of the service.  The stat API does not return it; it is filled in from the service config.
+	TD                           string  `json:"-"`
 }
 
 // GetServiceStats queries the Nitro API for service stats
-func GetServiceStats(c *NitroClient, querystring string) (NSAPIResponse, error) {
-	stats, err := c.GetStats("service", querystring)
-	if err != nil {
-		return NSAPIResponse{}, err
-	}
-
-	var response = new(NSAPIResponse)
-
-	err = json.Unmarshal(stats, &response)
-	if err != nil {
-		return NSAPIResponse{}, errors.Wrap(err, "error unmarshalling response body")
-	}
-
-	return *response, nil
+func GetServiceStats(c *NitroClient, q Query) ([]ServiceStats, error) {
+	return GetStat[ServiceStats](c, "service", q)
 }
@@ -1,13 +1,7 @@
 package netscaler
 
-import (
-	"io"
-	"net/http"
-
-	"github.com/pkg/errors"
-)
-
 // GetStats sends a request to the Nitro API and retrieves stats for the given type.
+// Errors returned by the NetScaler are of type *NitroError.
 func (c *NitroClient) GetStats(statsType string, querystring string) ([]byte, error) {
 	url := c.url + "stat/" + statsType
 
@@ -15,29 +9,5 @@ func (c *NitroClient) GetStats(statsType string, querystring string) ([]byte, er
 		url = url + "?" + querystring
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, errors.Wrap(err, "error creating HTTP request")
-	}
-
-	req.Header.Set("Accept", "application/json")
-
-	resp, err := c.client.Do(req)
-	if resp != nil {
-		defer resp.Body.Close()
-	}
-	if err != nil {
-		return nil, errors.Wrap(err, "error sending request")
-	}
-
-	switch resp.StatusCode {
-	case 200:
-		body, _ := io.ReadAll(resp.Body)
-
-		return body, nil
-	default:
-		body, _ := io.ReadAll(resp.Body)
-
-		return body, errors.New("read failed: " + resp.Status + " (" + string(body) + ")")
-	}
+	return c.get(url)
 }
@@ -0,0 +1,16 @@
+package netscaler
+
+// SystemFile represents the data returned from the /config/systemfile Nitro API endpoint.
+// FileContent is base64 encoded, and is only populated when a single file is requested.
+type SystemFile struct {
+	FileName     string   `json:"filename"`
+	FileLocation string   `json:"filelocation"`
+	FileContent  string   `json:"filecontent"`
+	FileMode     []string `json:"filemode"`
+}
+
+// GetSystemFiles queries the Nitro API for files on the NetScaler filesystem.
+// The query must contain at least the filelocation argument; for example Query{Args: map[string]string{"filelocation": "/nsconfig/license"}}
+func GetSystemFiles(c *NitroClient, q Query) ([]SystemFile, error) {
+	return GetConfigList[SystemFile](c, "systemfile", q)
+}
@@ -0,0 +1,32 @@
+package netscaler
+
+import "encoding/json"
+
+// TrafficDomain represents the data returned from the /config/nstrafficdomain Nitro API endpoint
+type TrafficDomain struct {
+	TD        json.Number `json:"td"`
+	AliasName string      `json:"aliasname"`
+	State     string      `json:"state"`
+}
+
+// TrafficDomainStats represents the data returned from the /stat/nstrafficdomain Nitro API endpoint.
+// The rates are per second.
+type TrafficDomainStats struct {
+	TD                   json.Number `json:"td"`
+	TotalReceivedPackets json.Number `json:"nstdtotrxpkts"`
+	ReceivedPacketsRate  json.Number `json:"nstdrxpktsrate"`
+	TotalTransmitPackets json.Number `json:"nstdtottxpkts"`
+	TransmitPacketsRate  json.Number `json:"nstdtxpktsrate"`
+	TotalDroppedPackets  json.Number `json:"nstdtotdroppedpkts"`
+	DroppedPacketsRate   json.Number `json:"nstddroppedpktsrate"`
+}
+
+// GetTrafficDomains queries the Nitro API for the traffic domains.  The default traffic domain, 0, is not returned.
+func GetTrafficDomains(c *NitroClient, q Query) ([]TrafficDomain, error) {
+	return GetConfigList[TrafficDomain](c, "nstrafficdomain", q)
+}
+
+// GetTrafficDomainStats queries the Nitro API for the packet stats of each traffic domain
+func GetTrafficDomainStats(c *NitroClient, q Query) ([]TrafficDomainStats, error) {
+	return GetStat[TrafficDomainStats](c, "nstrafficdomain", q)
+}
@@ -1,11 +1,5 @@
 package netscaler
 
-import (
-	"encoding/json"
-
-	"github.com/pkg/errors"
-)
-
 // VirtualServerStats represents the data returned from the /stat/lbvserver Nitro API endpoint
 type VirtualServerStats struct {
 	Name                     string `json:"name"`
@@ -21,21 +15,13 @@ type VirtualServerStats struct {
 	TotalResponseBytes       string `json:"totalresponsebytes"`
 	CurrentClientConnections string `json:"curclntconnections"`
 	CurrentServerConnections string `json:"cursrvrconnections"`
+	PrimaryIPAddress         string `json:"primaryipaddress"`
+	PrimaryPort              int    `json:"primaryport"`
+	// TD is the traffic domain of the virtual server.  The stat API does not return it; it is filled in from the virtual server config.
+	TD string `json:"-"`
 }
 
 // GetVirtualServerStats queries the Nitro API for virtual server stats
-func GetVirtualServerStats(c *NitroClient, querystring string) (NSAPIResponse, error) {
-	stats, err := c.GetStats("lbvserver", querystring)
-	if err != nil {
-		return NSAPIResponse{}, err
-	}
-
-	var response = new(NSAPIResponse)
-
-	err = json.Unmarshal(stats, &response)
-	if err != nil {
-		return NSAPIResponse{}, errors.Wrap(err, "error unmarshalling response body")
-	}
-
-	return *response, nil
+func GetVirtualServerStats(c *NitroClient, q Query) ([]VirtualServerStats, error) {
+	return GetStat[VirtualServerStats](c, "lbvserver", q)
 }
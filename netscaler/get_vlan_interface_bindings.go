@@ -0,0 +1,15 @@
+package netscaler
+
+import "encoding/json"
+
+// VLANInterfaceBinding represents the data returned from the /config/vlan_interface_binding Nitro API endpoint
+type VLANInterfaceBinding struct {
+	ID        json.Number `json:"id"`
+	Interface string      `json:"ifnum"`
+	Tagged    bool        `json:"tagged"`
+}
+
+// GetVLANInterfaceBindings queries the Nitro API for the interfaces bound to every VLAN
+func GetVLANInterfaceBindings(c *NitroClient) ([]VLANInterfaceBinding, error) {
+	return GetConfigList[VLANInterfaceBinding](c, "vlan_interface_binding", Query{BulkBindings: true})
+}
@@ -0,0 +1,19 @@
+package netscaler
+
+import "encoding/json"
+
+// VPNICAConnection represents the data returned from the /config/vpnicaconnection Nitro API endpoint
+type VPNICAConnection struct {
+	Username          string      `json:"username"`
+	Domain            string      `json:"domain"`
+	SourceIP          string      `json:"srcip"`
+	SourcePort        json.Number `json:"srcport"`
+	DestinationIP     string      `json:"destip"`
+	DestinationPort   json.Number `json:"destport"`
+	TransportProtocol string      `json:"transproto"`
+}
+
+// GetVPNICAConnections queries the Nitro API for active ICA connections through NetScaler Gateway
+func GetVPNICAConnections(c *NitroClient, q Query) ([]VPNICAConnection, error) {
+	return GetConfigList[VPNICAConnection](c, "vpnicaconnection", q)
+}
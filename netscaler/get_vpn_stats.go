@@ -0,0 +1,24 @@
+package netscaler
+
+// VPNStats represents the data returned from the /stat/vpn Nitro API endpoint
+type VPNStats struct {
+	LicenseFailures              string `json:"vpnlicensefail"`
+	ICALicenseFailures           string `json:"icalicensefailure"`
+	IntranetIPFailures           string `json:"iipfailedmipdisabled"`
+	ClientSecurityCheckRequests  string `json:"csrequesthit"`
+	ClientSecurityCheckSuccesses string `json:"totalcsconnsucc"`
+	STAConnectionSuccesses       string `json:"staconnsuccess"`
+	STAConnectionFailures        string `json:"staconnfailure"`
+	PTicketValidationsNotStarted string `json:"csgptktvalidatenotstarted"`
+	RTicketValidationsNotStarted string `json:"csgrtktvalidatenotstarted"`
+}
+
+// GetVPNStats queries the Nitro API for global VPN (NetScaler Gateway) stats
+func GetVPNStats(c *NitroClient, q Query) (VPNStats, error) {
+	stats, err := GetStat[VPNStats](c, "vpn", q)
+	if err != nil {
+		return VPNStats{}, err
+	}
+
+	return first(stats), nil
+}
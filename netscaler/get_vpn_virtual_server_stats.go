@@ -1,34 +1,22 @@
 package netscaler
 
-import (
-	"encoding/json"
-
-	"github.com/pkg/errors"
-)
+import "encoding/json"
 
 // VPNVirtualServerStats represents the data returned from the /stat/vpnvserver Nitro API endpoint
 type VPNVirtualServerStats struct {
-	Name               string `json:"name"`
-	TotalRequests      string `json:"totalrequests"`
-	TotalResponses     string `json:"totalresponses"`
-	TotalRequestBytes  string `json:"totalrequestbytes"`
-	TotalResponseBytes string `json:"totalresponsebytes"`
-	State              string `json:"state"`
+	Name               string      `json:"name"`
+	PrimaryIPAddress   string      `json:"primaryipaddress"`
+	PrimaryPort        json.Number `json:"primaryport"`
+	TotalRequests      string      `json:"totalrequests"`
+	TotalResponses     string      `json:"totalresponses"`
+	TotalRequestBytes  string      `json:"totalrequestbytes"`
+	TotalResponseBytes string      `json:"totalresponsebytes"`
+	State              string      `json:"state"`
+	CurrentUsers       string      `json:"curtotalvpnusers"`
+	CurrentSSLVPNUsers string      `json:"cursslvpnusers"`
 }
 
 // GetVPNVirtualServerStats queries the Nitro API for VPN virtual server stats
-func GetVPNVirtualServerStats(c *NitroClient, querystring string) (NSAPIResponse, error) {
-	stats, err := c.GetStats("vpnvserver", querystring)
-	if err != nil {
-		return NSAPIResponse{}, err
-	}
-
-	var response = new(NSAPIResponse)
-
-	err = json.Unmarshal(stats, &response)
-	if err != nil {
-		return NSAPIResponse{}, errors.Wrap(err, "error unmarshalling response body")
-	}
-
-	return *response, nil
+func GetVPNVirtualServerStats(c *NitroClient, q Query) ([]VPNVirtualServerStats, error) {
+	return GetStat[VPNVirtualServerStats](c, "vpnvserver", q)
 }
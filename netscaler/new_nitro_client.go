@@ -16,6 +16,10 @@ type NitroClient struct {
 	username string
 	password string
 	client   *http.Client
+	// partition is the admin partition the session was last switched into, so that it can be switched back after logging in again.
+	partition string
+	// proxyInstance is the IP address of the managed instance which requests are proxied to, when the client is logged in to NetScaler ADM rather than a NetScaler.
+	proxyInstance string
 }
 
 // NewNitroClient creates a new client used to interact with the Nitro API.
@@ -52,6 +56,19 @@ func NewNitroClient(url string, username string, password string, ignoreCert boo
 	return c, nil
 }
 
+// ProxyThroughADM makes the client send its requests, other than logging in and out, to the managed instance with the given IP address.
+// The client must be connected to NetScaler ADM, which forwards each request to the instance using the instance's ADM profile; the exporter needs no credentials for the instance itself.
+func (c *NitroClient) ProxyThroughADM(instanceIP string) {
+	c.proxyInstance = instanceIP
+}
+
+// setProxyHeader adds the header which tells NetScaler ADM to forward the request to the managed instance, if the client proxies through ADM.
+func (c *NitroClient) setProxyHeader(req *http.Request) {
+	if c.proxyInstance != "" {
+		req.Header.Set(ADMProxyHeader, c.proxyInstance)
+	}
+}
+
 func (c *NitroClient) CloseIdleConnection() {
 	c.client.CloseIdleConnections()
 }
@@ -0,0 +1,222 @@
+package netscaler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Query holds the optional parameters for a Nitro API request.
+type Query struct {
+	// Attrs limits the response to the given attributes.
+	Attrs []string
+	// Filter limits the response to resources whose attributes match the given values.
+	Filter map[string]string
+	// Args passes arguments required by some resources; for example filelocation for systemfile.
+	Args map[string]string
+	// StatBindings includes the stats of bound resources; for example the members of a service group.
+	StatBindings bool
+	// BulkBindings returns the bindings of every resource, rather than of a single named resource.
+	BulkBindings bool
+	// PageSize sets the number of resources per page.  GetConfigList fetches every page unless PageNo is also set.
+	PageSize int
+	// PageNo fetches a single page, starting at 1.
+	PageNo int
+	// Count returns the number of resources, rather than the resources themselves.
+	Count bool
+}
+
+// Encode returns the query as a Nitro query string, without the leading question mark.
+func (q Query) Encode() string {
+	var params []string
+
+	if len(q.Attrs) > 0 {
+		params = append(params, "attrs="+strings.Join(q.Attrs, ","))
+	}
+
+	if len(q.Filter) > 0 {
+		params = append(params, "filter="+encodeNitroPairs(q.Filter))
+	}
+
+	if len(q.Args) > 0 {
+		params = append(params, "args="+encodeNitroPairs(q.Args))
+	}
+
+	if q.StatBindings {
+		params = append(params, "statbindings=yes")
+	}
+
+	if q.BulkBindings {
+		params = append(params, "bulkbindings=yes")
+	}
+
+	if q.PageSize > 0 {
+		params = append(params, "pagesize="+strconv.Itoa(q.PageSize))
+	}
+
+	if q.PageNo > 0 {
+		params = append(params, "pageno="+strconv.Itoa(q.PageNo))
+	}
+
+	if q.Count {
+		params = append(params, "count=yes")
+	}
+
+	return strings.Join(params, "&")
+}
+
+// encodeNitroPairs encodes a map in the key:value,key:value form used by the Nitro filter and args parameters.
+// Keys are sorted so that the same query always produces the same URL.
+func encodeNitroPairs(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+":"+url.QueryEscape(m[k]))
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+// GetStat queries the /stat Nitro API endpoint for the resource, and decodes the result into a list of T.
+// The resource may include a name; for example servicegroup/web.
+// Resources which Nitro returns as a single object, such as ns, are returned as a list of one.
+func GetStat[T any](c *NitroClient, resource string, q Query) ([]T, error) {
+	body, err := c.GetStats(resource, q.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeResource[T](body, resource)
+}
+
+// maxConfigPages is the most pages GetConfigList fetches for one resource, so that a NetScaler which keeps returning full pages cannot keep it fetching forever.
+const maxConfigPages = 1000
+
+// GetConfigList queries the /config Nitro API endpoint for the resource, and decodes the result into a list of T.
+// If q.PageSize is set and q.PageNo is not, every page is fetched in turn and the results combined.
+// Firmware which does not support paging ignores pageno and returns the same page every time, so fetching stops when a page repeats the one before it.
+func GetConfigList[T any](c *NitroClient, resource string, q Query) ([]T, error) {
+	if q.PageSize == 0 || q.PageNo > 0 {
+		body, err := c.GetConfig(resource, q.Encode())
+		if err != nil {
+			return nil, err
+		}
+
+		return decodeResource[T](body, resource)
+	}
+
+	var all []T
+	var previous []byte
+
+	for q.PageNo = 1; ; q.PageNo++ {
+		if q.PageNo > maxConfigPages {
+			return nil, errors.Errorf("error fetching %s: more than %d pages of %d", resource, maxConfigPages, q.PageSize)
+		}
+
+		body, err := c.GetConfig(resource, q.Encode())
+		if err != nil {
+			return nil, err
+		}
+
+		if bytes.Equal(body, previous) {
+			return all, nil
+		}
+		previous = body
+
+		page, err := decodeResource[T](body, resource)
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, page...)
+
+		if len(page) < q.PageSize {
+			return all, nil
+		}
+	}
+}
+
+// GetConfigCount queries the /config Nitro API endpoint for the number of resources of the given type.
+func GetConfigCount(c *NitroClient, resource string, q Query) (int, error) {
+	q.Count = true
+
+	counts, err := GetConfigList[struct {
+		Count float64 `json:"__count"`
+	}](c, resource, q)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(counts) == 0 {
+		return 0, nil
+	}
+
+	return int(counts[0].Count), nil
+}
+
+// decodeResource decodes the named resource from a Nitro response body.
+// Nitro is not consistent with the case of its keys (the interface resource is returned as Interface), so the key is matched case-insensitively.
+// A response which does not contain the resource, for example because none are configured, is not an error.
+func decodeResource[T any](body []byte, resource string) ([]T, error) {
+	key := strings.SplitN(resource, "/", 2)[0]
+
+	var response map[string]json.RawMessage
+
+	err := json.Unmarshal(body, &response)
+	if err != nil {
+		return nil, errors.Wrap(err, "error unmarshalling response body")
+	}
+
+	var raw json.RawMessage
+	for k, v := range response {
+		if strings.EqualFold(k, key) {
+			raw = v
+			break
+		}
+	}
+
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	if raw[0] != '[' {
+		var single T
+
+		err = json.Unmarshal(raw, &single)
+		if err != nil {
+			return nil, errors.Wrap(err, "error unmarshalling response body")
+		}
+
+		return []T{single}, nil
+	}
+
+	var list []T
+
+	err = json.Unmarshal(raw, &list)
+	if err != nil {
+		return nil, errors.Wrap(err, "error unmarshalling response body")
+	}
+
+	return list, nil
+}
+
+// first returns the first item in the list, or the zero value if the list is empty.
+func first[T any](list []T) T {
+	var zero T
+
+	if len(list) == 0 {
+		return zero
+	}
+
+	return list[0]
+}
@@ -0,0 +1,140 @@
+package netscaler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Nitro error codes which callers are likely to want to react to.
+const (
+	ErrorCodeNotAuthorized      int64 = 10
+	ErrorCodeNoSuchResource     int64 = 258
+	ErrorCodeInvalidCredentials int64 = 354
+	ErrorCodeSessionExpired     int64 = 444
+	ErrorCodeAuthTimeout        int64 = 1027
+)
+
+// NitroError is returned when the NetScaler responds to a request with an error.
+// Code, Message and Severity are taken from the Nitro response body when it can be decoded.
+type NitroError struct {
+	StatusCode int
+	Status     string
+	Code       int64
+	Message    string
+	Severity   string
+}
+
+func (e *NitroError) Error() string {
+	if e.Code == 0 && e.Message == "" {
+		return "read failed: " + e.Status
+	}
+
+	return "read failed: " + e.Status + " (errorcode " + strconv.FormatInt(e.Code, 10) + ": " + e.Message + ")"
+}
+
+// newNitroError builds a NitroError from the response and its body.
+// The body is not always JSON, for example when a proxy sits in front of the NetScaler, so a decode failure is not an error in itself.
+func newNitroError(resp *http.Response, body []byte) *NitroError {
+	var response = new(NSAPIResponse)
+
+	json.Unmarshal(body, &response)
+
+	return &NitroError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Code:       response.Errorcode,
+		Message:    response.Message,
+		Severity:   response.Severity,
+	}
+}
+
+// checkErrorcode returns a NitroError if a successful response carries a Nitro error in its body.
+// Warnings are not treated as errors.
+func checkErrorcode(resp *http.Response, body []byte) error {
+	var response struct {
+		Errorcode int64  `json:"errorcode"`
+		Severity  string `json:"severity"`
+	}
+
+	err := json.Unmarshal(body, &response)
+	if err != nil {
+		// Not a Nitro response; leave it to the caller to report when it tries to unmarshal the body.
+		return nil
+	}
+
+	if response.Errorcode == 0 || response.Severity != "ERROR" {
+		return nil
+	}
+
+	return newNitroError(resp, body)
+}
+
+// asNitroError returns the underlying NitroError, if there is one.
+func asNitroError(err error) (*NitroError, bool) {
+	nitroErr, ok := errors.Cause(err).(*NitroError)
+	return nitroErr, ok
+}
+
+// ErrorCode returns the Nitro error code of the error, or 0 if the error did not come from the Nitro API.
+func ErrorCode(err error) int64 {
+	nitroErr, ok := asNitroError(err)
+	if !ok {
+		return 0
+	}
+
+	return nitroErr.Code
+}
+
+// IsPermissionDenied reports whether the error was caused by the user account not being allowed to run the command; usually because of the Command Policy.
+func IsPermissionDenied(err error) bool {
+	nitroErr, ok := asNitroError(err)
+	if !ok {
+		return false
+	}
+
+	return nitroErr.Code == ErrorCodeNotAuthorized || nitroErr.StatusCode == http.StatusForbidden
+}
+
+// IsNotFound reports whether the error was caused by the requested resource not existing.
+func IsNotFound(err error) bool {
+	nitroErr, ok := asNitroError(err)
+	if !ok {
+		return false
+	}
+
+	return nitroErr.Code == ErrorCodeNoSuchResource || nitroErr.StatusCode == http.StatusNotFound
+}
+
+// IsSessionExpired reports whether the error was caused by the login session having expired, in which case logging in again should resolve it.
+func IsSessionExpired(err error) bool {
+	nitroErr, ok := asNitroError(err)
+	if !ok {
+		return false
+	}
+
+	return nitroErr.Code == ErrorCodeSessionExpired || nitroErr.Code == ErrorCodeAuthTimeout
+}
+
+// IsTemporary reports whether the request which failed with the error may succeed if it is made again; because the NetScaler could not be reached,
+// returned a server error or asked for fewer requests, or because the session expired.  Any other error from the NetScaler means it rejected the request itself.
+func IsTemporary(err error) bool {
+	nitroErr, ok := asNitroError(err)
+	if !ok {
+		return true
+	}
+
+	return nitroErr.StatusCode/100 == 5 || nitroErr.StatusCode == http.StatusTooManyRequests || IsSessionExpired(err)
+}
+
+// IsLoginFailure reports whether the error was caused by the NetScaler rejecting the login.
+func IsLoginFailure(err error) bool {
+	nitroErr, ok := asNitroError(err)
+	if !ok {
+		return false
+	}
+
+	return nitroErr.Code == ErrorCodeInvalidCredentials || nitroErr.StatusCode == http.StatusUnauthorized
+}
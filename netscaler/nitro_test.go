@@ -0,0 +1,290 @@
+package netscaler_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/rokett/citrix-netscaler-exporter/netscaler"
+	"github.com/rokett/citrix-netscaler-exporter/netscaler/nitrotest"
+)
+
+func TestQueryEncode(t *testing.T) {
+	tests := []struct {
+		name  string
+		query netscaler.Query
+		want  string
+	}{
+		{
+			name:  "empty",
+			query: netscaler.Query{},
+			want:  "",
+		},
+		{
+			name:  "attrs",
+			query: netscaler.Query{Attrs: []string{"name", "td"}},
+			want:  "attrs=name,td",
+		},
+		{
+			name:  "filter and args are sorted and escaped",
+			query: netscaler.Query{Filter: map[string]string{"td": "1", "name": "web app"}, Args: map[string]string{"filelocation": "/nsconfig/license"}},
+			want:  "filter=name:web+app,td:1&args=filelocation:%2Fnsconfig%2Flicense",
+		},
+		{
+			name:  "bindings",
+			query: netscaler.Query{StatBindings: true, BulkBindings: true},
+			want:  "statbindings=yes&bulkbindings=yes",
+		},
+		{
+			name:  "paging",
+			query: netscaler.Query{PageSize: 100, PageNo: 2},
+			want:  "pagesize=100&pageno=2",
+		},
+		{
+			name:  "count",
+			query: netscaler.Query{Filter: map[string]string{"state": "ENABLED"}, Count: true},
+			want:  "filter=state:ENABLED&count=yes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.query.Encode()
+			if got != tt.want {
+				t.Errorf("Encode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// connect logs a client in to the server.
+func connect(t *testing.T, url string) *netscaler.NitroClient {
+	t.Helper()
+
+	c, err := netscaler.NewNitroClient(url, "user", "pass", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = netscaler.Connect(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func TestGetStatDecoding(t *testing.T) {
+	srv := nitrotest.NewServer()
+	defer srv.Close()
+
+	// Nitro returns some resources as a single object, and does not match the case of the resource name.
+	srv.SetFixture("stat/ns", []byte(`{"errorcode":0,"message":"Done","ns":{"cpuusagepcnt":4.2,"starttime":"Mon Jan  8 09:30:00 2024"}}`))
+	srv.SetFixture("stat/interface", []byte(`{"errorcode":0,"message":"Done","Interface":[{"id":"0/1"},{"id":"1/1"}]}`))
+	srv.SetFixture("stat/servicegroup/web", []byte(`{"errorcode":0,"message":"Done","servicegroup":[{"servicegroupname":"web"}]}`))
+
+	c := connect(t, srv.URL)
+
+	ns, err := netscaler.GetNSStats(c, netscaler.Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ns.CPUUsagePcnt != 4.2 || ns.StartTime != "Mon Jan  8 09:30:00 2024" {
+		t.Errorf("GetNSStats() = %+v", ns)
+	}
+
+	interfaces, err := netscaler.GetInterfaceStats(c, netscaler.Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(interfaces) != 2 || interfaces[1].ID != "1/1" {
+		t.Errorf("GetInterfaceStats() = %+v", interfaces)
+	}
+
+	groups, err := netscaler.GetServiceGroupMemberStats(c, "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 1 || groups[0].Name != "web" {
+		t.Errorf("GetServiceGroupMemberStats() = %+v", groups)
+	}
+
+	// A resource which is not configured is missing from the response, which is not an error.
+	vservers, err := netscaler.GetVirtualServerStats(c, netscaler.Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vservers) != 0 {
+		t.Errorf("GetVirtualServerStats() = %+v, want none", vservers)
+	}
+}
+
+func TestGetVPNSessionPorts(t *testing.T) {
+	srv := nitrotest.NewServer()
+	defer srv.Close()
+
+	// Ports may be returned as strings or as numbers.
+	srv.SetFixture("config/aaasession", []byte(`{"errorcode":0,"message":"Done","aaasession":[{"username":"user1","publicport":"50123","port":"443"},{"username":"user2","publicport":50124,"port":443}]}`))
+	srv.SetFixture("config/vpnicaconnection", []byte(`{"errorcode":0,"message":"Done","vpnicaconnection":[{"username":"user1","srcport":"50125","destport":"2598"},{"username":"user2","srcport":50126,"destport":2598}]}`))
+
+	c := connect(t, srv.URL)
+
+	sessions, err := netscaler.GetAAASessions(c, netscaler.Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 || sessions[0].PublicPort != "50123" || sessions[0].Port != "443" || sessions[1].PublicPort != "50124" || sessions[1].Port != "443" {
+		t.Errorf("GetAAASessions() = %+v", sessions)
+	}
+
+	connections, err := netscaler.GetVPNICAConnections(c, netscaler.Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(connections) != 2 || connections[0].SourcePort != "50125" || connections[0].DestinationPort != "2598" || connections[1].SourcePort != "50126" || connections[1].DestinationPort != "2598" {
+		t.Errorf("GetVPNICAConnections() = %+v", connections)
+	}
+}
+
+func TestGetStatNitroError(t *testing.T) {
+	srv := nitrotest.NewServer()
+	defer srv.Close()
+
+	srv.SetError("stat/lbvserver", http.StatusForbidden, netscaler.ErrorCodeNotAuthorized, "Not authorized to execute this command")
+
+	c := connect(t, srv.URL)
+
+	_, err := netscaler.GetVirtualServerStats(c, netscaler.Query{})
+	if !netscaler.IsPermissionDenied(err) {
+		t.Fatalf("GetVirtualServerStats() error = %v, want permission denied", err)
+	}
+	if netscaler.ErrorCode(err) != netscaler.ErrorCodeNotAuthorized {
+		t.Errorf("ErrorCode() = %d, want %d", netscaler.ErrorCode(err), netscaler.ErrorCodeNotAuthorized)
+	}
+}
+
+// serviceGroups returns a config/servicegroup response listing the named service groups.
+func serviceGroups(names ...string) []byte {
+	body := `{"errorcode":0,"message":"Done","servicegroup":[`
+	for i, name := range names {
+		if i > 0 {
+			body += ","
+		}
+		body += `{"servicegroupname":"` + name + `"}`
+	}
+
+	return []byte(body + "]}")
+}
+
+func TestGetConfigListPaging(t *testing.T) {
+	srv := nitrotest.NewServer()
+	defer srv.Close()
+
+	srv.SetFixture("config/servicegroup", serviceGroups("sg1", "sg2", "sg3", "sg4", "sg5"))
+
+	c := connect(t, srv.URL)
+
+	groups, err := netscaler.GetServiceGroups(c, netscaler.Query{PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(groups) != 5 || groups[0].Name != "sg1" || groups[4].Name != "sg5" {
+		t.Errorf("GetServiceGroups() = %+v, want sg1 to sg5", groups)
+	}
+
+	for page := 1; page <= 3; page++ {
+		key := nitrotest.Key("config/servicegroup", "pagesize=2&pageno="+strconv.Itoa(page))
+		if n := srv.Requests(key); n != 1 {
+			t.Errorf("Requests(%q) = %d, want 1", key, n)
+		}
+	}
+
+	// A single page is fetched when the page number is given.
+	groups, err = netscaler.GetServiceGroups(c, netscaler.Query{PageSize: 2, PageNo: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(groups) != 1 || groups[0].Name != "sg5" {
+		t.Errorf("GetServiceGroups() page 3 = %+v, want sg5", groups)
+	}
+}
+
+func TestGetConfigListRepeatedPage(t *testing.T) {
+	requests := 0
+
+	// Firmware which does not support paging returns every resource, whatever the page number.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write(serviceGroups("sg1", "sg2", "sg3"))
+	}))
+	defer srv.Close()
+
+	c, _ := netscaler.NewNitroClient(srv.URL, "user", "pass", false)
+
+	groups, err := netscaler.GetServiceGroups(c, netscaler.Query{PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(groups) != 3 {
+		t.Errorf("GetServiceGroups() returned %d service groups, want 3", len(groups))
+	}
+
+	if requests != 2 {
+		t.Errorf("sent %d requests, want 2", requests)
+	}
+}
+
+func TestGetConfigListMaxPages(t *testing.T) {
+	requests := 0
+
+	// A different full page every time would otherwise never end.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write(serviceGroups(fmt.Sprintf("sg%d", requests)))
+	}))
+	defer srv.Close()
+
+	c, _ := netscaler.NewNitroClient(srv.URL, "user", "pass", false)
+
+	_, err := netscaler.GetServiceGroups(c, netscaler.Query{PageSize: 1})
+	if err == nil {
+		t.Fatal("GetServiceGroups() returned no error, want too many pages")
+	}
+
+	if requests != 1000 {
+		t.Errorf("sent %d requests, want 1000", requests)
+	}
+}
+
+func TestGetConfigCount(t *testing.T) {
+	srv := nitrotest.NewServer()
+	defer srv.Close()
+
+	srv.SetFixture("config/servicegroup", serviceGroups("sg1", "sg2", "sg3"))
+
+	c := connect(t, srv.URL)
+
+	count, err := netscaler.GetConfigCount(c, "servicegroup", netscaler.Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 3 {
+		t.Errorf("GetConfigCount() = %d, want 3", count)
+	}
+
+	// Nothing configured.
+	count, err = netscaler.GetConfigCount(c, "lbvserver", netscaler.Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 0 {
+		t.Errorf("GetConfigCount() = %d, want 0", count)
+	}
+}
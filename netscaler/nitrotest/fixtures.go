@@ -0,0 +1,16 @@
+package nitrotest
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed fixtures
+var fixtures embed.FS
+
+// Fixtures returns a small set of anonymised fixtures covering the resources which the exporter requests most often.
+// Fixture files are laid out as described by FixtureFile.
+func Fixtures() fs.FS {
+	sub, _ := fs.Sub(fixtures, "fixtures")
+	return sub
+}
@@ -0,0 +1,201 @@
+package nitrotest
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Recorder is a proxy in front of a NetScaler, which saves every successful Nitro GET response as a fixture.
+// Point a NitroClient at the Recorder's URL rather than at the NetScaler, and the fixtures written can later be served by a Server.
+type Recorder struct {
+	*httptest.Server
+
+	dir      string
+	scrubber *Scrubber
+
+	mu       sync.Mutex
+	recorded map[string]bool
+	err      error
+	// partitions maps each session token to the admin partition the session was switched into.
+	partitions map[string]string
+}
+
+// partitionContextKey holds the partition named in a switch partition request, until its response is seen.
+type partitionContextKey struct{}
+
+// NewRecorder starts a Recorder which proxies requests to the NetScaler at target, writing fixtures into dir.
+// If scrubber is not nil, responses are scrubbed before they are written.
+func NewRecorder(target string, dir string, ignoreCert bool, scrubber *Scrubber) (*Recorder, error) {
+	u, err := url.Parse(strings.Trim(target, " /"))
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing target")
+	}
+
+	r := &Recorder{
+		dir:        dir,
+		scrubber:   scrubber,
+		recorded:   make(map[string]bool),
+		partitions: make(map[string]string),
+	}
+
+	proxy := httputil.NewSingleHostReverseProxy(u)
+
+	director := proxy.Director
+	proxy.Director = func(req *http.Request) {
+		director(req)
+		req.Host = u.Host
+	}
+
+	if ignoreCert {
+		proxy.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		}
+	}
+
+	proxy.ModifyResponse = r.modifyResponse
+
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPost && strings.TrimPrefix(req.URL.Path, apiPrefix) == "config/nspartition" && req.URL.Query().Get("action") == "Switch" {
+			req = withPartition(req)
+		}
+
+		proxy.ServeHTTP(w, req)
+	}))
+
+	return r, nil
+}
+
+// Recorded returns the keys of the fixtures written, sorted.
+func (r *Recorder) Recorded() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	keys := make([]string, 0, len(r.recorded))
+	for k := range r.recorded {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Err returns the first error encountered writing a fixture, if any.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.err
+}
+
+func (r *Recorder) modifyResponse(resp *http.Response) error {
+	// The NetScaler marks its session cookie as secure, which would stop it being sent back to the Recorder over plain HTTP.
+	cookies := resp.Header.Values("Set-Cookie")
+	resp.Header.Del("Set-Cookie")
+	for _, c := range cookies {
+		c = strings.ReplaceAll(c, "; Secure", "")
+		c = strings.ReplaceAll(c, ";Secure", "")
+		resp.Header.Add("Set-Cookie", c)
+	}
+
+	token := ""
+	if cookie, err := resp.Request.Cookie(tokenCookie); err == nil {
+		token = cookie.Value
+	}
+
+	if partition, ok := resp.Request.Context().Value(partitionContextKey{}).(string); ok && resp.StatusCode < 300 {
+		r.mu.Lock()
+		r.partitions[token] = partition
+		r.mu.Unlock()
+	}
+
+	if resp.Request.Method != http.MethodGet || resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Request.URL.Path, apiPrefix) {
+		return nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return errors.Wrap(err, "error reading response body")
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	key := Key(strings.TrimPrefix(resp.Request.URL.Path, apiPrefix), resp.Request.URL.RawQuery)
+
+	r.mu.Lock()
+	partition := r.partitions[token]
+	r.mu.Unlock()
+
+	if partition != "" && partition != "default" {
+		key = PartitionKey(partition, key)
+	}
+
+	if r.scrubber != nil {
+		key = r.scrubber.Key(key)
+		body = r.scrubber.Body(body)
+	}
+
+	r.write(key, body)
+
+	return nil
+}
+
+func (r *Recorder) write(key string, body []byte) {
+	file := filepath.Join(r.dir, filepath.FromSlash(FixtureFile(key)))
+
+	err := os.MkdirAll(filepath.Dir(file), 0755)
+	if err == nil {
+		err = os.WriteFile(file, body, 0644)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err != nil {
+		if r.err == nil {
+			r.err = errors.Wrap(err, "error writing fixture "+file)
+		}
+		return
+	}
+
+	r.recorded[key] = true
+}
+
+// withPartition returns the switch partition request with the partition it names held in its context, so that the partition can be
+// recorded against the session once the NetScaler has accepted it.
+func withPartition(req *http.Request) *http.Request {
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return req
+	}
+
+	var payload struct {
+		NSPartition struct {
+			PartitionName string `json:"partitionname"`
+		} `json:"nspartition"`
+	}
+
+	err = json.Unmarshal(body, &payload)
+	if err != nil {
+		return req
+	}
+
+	return req.WithContext(context.WithValue(req.Context(), partitionContextKey{}, payload.NSPartition.PartitionName))
+}
@@ -0,0 +1,345 @@
+package nitrotest
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// sensitiveFields are the Nitro fields whose values identify the appliance, its configuration or its users.
+// Values are replaced with pseudonyms; IP and MAC addresses are replaced wherever they appear.
+var sensitiveFields = map[string]bool{
+	"name":                 true,
+	"servicegroupname":     true,
+	"servername":           true,
+	"vservername":          true,
+	"hostname":             true,
+	"host":                 true,
+	"username":             true,
+	"groupname":            true,
+	"domain":               true,
+	"ifalias":              true,
+	"interfacealias":       true,
+	"serialno":             true,
+	"encodedserialno":      true,
+	"hostid":               true,
+	"netscaleruuid":        true,
+	"filename":             true,
+	"servicename":          true,
+	"monitorname":          true,
+	"policyname":           true,
+	"partitionname":        true,
+	"aliasname":            true,
+	"licenseserverip":      true,
+	"primaryipaddress":     true,
+	"ipaddress":            true,
+	"ipv46":                true,
+	"publicip":             true,
+	"intranetip":           true,
+	"srcip":                true,
+	"destip":               true,
+	"svrip":                true,
+	"primaryipv6address":   true,
+	"clientip":             true,
+	"targetvserver":        true,
+	"lbvserver":            true,
+	"backupvserver":        true,
+	"authenticationserver": true,
+	"mac":                  true,
+	"macaddress":           true,
+}
+
+var (
+	ipv4Regex = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+	ipv6Regex = regexp.MustCompile(`\b[0-9a-fA-F]{0,4}(?::[0-9a-fA-F]{0,4}){2,7}\b`)
+	macRegex  = regexp.MustCompile(`\b[0-9a-fA-F]{2}(?:[:-][0-9a-fA-F]{2}){5}\b`)
+)
+
+// Scrubber replaces identifying values in Nitro responses with pseudonyms.
+// The same value is always replaced with the same pseudonym, so that references between resources still match after scrubbing;
+// for example a service group name in the servicegroup config and in the URL used to request its stats.
+type Scrubber struct {
+	mu         sync.Mutex
+	pseudonyms map[string]string
+	counts     map[string]int
+}
+
+// NewScrubber returns a Scrubber with no pseudonyms assigned.
+func NewScrubber() *Scrubber {
+	return &Scrubber{
+		pseudonyms: make(map[string]string),
+		counts:     make(map[string]int),
+	}
+}
+
+// Body scrubs a Nitro JSON response body.  A body which is not JSON is returned unchanged.
+func (s *Scrubber) Body(body []byte) []byte {
+	var v interface{}
+
+	err := json.Unmarshal(body, &v)
+	if err != nil {
+		return body
+	}
+
+	b, err := json.MarshalIndent(s.value("", v), "", "  ")
+	if err != nil {
+		return body
+	}
+
+	return b
+}
+
+// Key scrubs a fixture key, as returned by Key.
+// Resource names in the path, such as the service group in stat/servicegroup/<name>, and sensitive filter and args values are replaced.
+func (s *Scrubber) Key(key string) string {
+	if strings.HasPrefix(key, partitionPrefix) {
+		parts := strings.SplitN(strings.TrimPrefix(key, partitionPrefix), "/", 2)
+		if len(parts) == 2 {
+			return PartitionKey(s.string("partitionname", parts[0]), s.Key(parts[1]))
+		}
+	}
+
+	parts := strings.SplitN(key, "?", 2)
+
+	segments := strings.Split(parts[0], "/")
+	for i := 2; i < len(segments); i++ {
+		segments[i] = s.pseudonym("name", segments[i])
+	}
+
+	scrubbed := strings.Join(segments, "/")
+	if len(parts) == 1 {
+		return scrubbed
+	}
+
+	var params []string
+	for _, p := range strings.Split(parts[1], "&") {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) == 2 && (kv[0] == "args" || kv[0] == "filter") {
+			p = kv[0] + "=" + s.pairs(kv[1])
+		}
+
+		params = append(params, p)
+	}
+
+	return Key(scrubbed, strings.Join(params, "&"))
+}
+
+// pairs scrubs the values of a Nitro key:value,key:value parameter.
+// Values are URL encoded, so they are decoded before scrubbing so that they match the same values in response bodies.
+func (s *Scrubber) pairs(encoded string) string {
+	var pairs []string
+
+	for _, pair := range strings.Split(encoded, ",") {
+		kv := strings.SplitN(pair, ":", 2)
+		if len(kv) == 2 && sensitiveFields[kv[0]] {
+			value := unescape(kv[1])
+			pair = kv[0] + ":" + escape(s.string(kv[0], value))
+		}
+
+		pairs = append(pairs, pair)
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+func (s *Scrubber) value(field string, v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		// Sorting the keys means pseudonyms are assigned in the same order every time the same response is scrubbed.
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			val[k] = s.value(k, val[k])
+		}
+		return val
+	case []interface{}:
+		for i, item := range val {
+			val[i] = s.value(field, item)
+		}
+		return val
+	case string:
+		if field == "filecontent" {
+			return s.licenseFile(val)
+		}
+
+		return s.string(field, val)
+	}
+
+	return v
+}
+
+func (s *Scrubber) string(field string, v string) string {
+	if v == "" {
+		return v
+	}
+
+	// The default partition exists on every NetScaler, and the exporter refers to it by name.
+	if field == "partitionname" && v == "default" {
+		return v
+	}
+
+	if sensitiveFields[field] {
+		// Service group members are named <service group>?<server>?<port>; each part is scrubbed separately so that they still match the service group and server.
+		if strings.Contains(v, "?") {
+			parts := strings.Split(v, "?")
+			for i, p := range parts {
+				parts[i] = s.string(field, p)
+			}
+			return strings.Join(parts, "?")
+		}
+
+		if net.ParseIP(v) != nil {
+			return s.ip(v)
+		}
+
+		if macRegex.FindString(v) == v {
+			return s.mac(v)
+		}
+
+		if isNumber(v) {
+			return v
+		}
+
+		return s.pseudonym(field, v)
+	}
+
+	// MAC addresses are replaced first, as the IPv6 pattern also matches them.
+	v = macRegex.ReplaceAllStringFunc(v, s.mac)
+
+	v = ipv4Regex.ReplaceAllStringFunc(v, func(m string) string {
+		if net.ParseIP(m) == nil {
+			return m
+		}
+		return s.ip(m)
+	})
+
+	return ipv6Regex.ReplaceAllStringFunc(v, func(m string) string {
+		if net.ParseIP(m) == nil {
+			return m
+		}
+		return s.ip(m)
+	})
+}
+
+// licenseFile reduces a base64 encoded license file to the feature and expiry of each INCREMENT and FEATURE line,
+// dropping host IDs, serial numbers and signatures.
+func (s *Scrubber) licenseFile(encoded string) string {
+	content, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return ""
+	}
+
+	var lines []string
+
+	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+
+		if len(fields) >= 5 && (fields[0] == "INCREMENT" || fields[0] == "FEATURE") {
+			lines = append(lines, strings.Join(fields[:5], " "))
+		}
+	}
+
+	return base64.StdEncoding.EncodeToString([]byte(strings.Join(lines, "\n") + "\n"))
+}
+
+// pseudonym returns the pseudonym for the value, assigning the next one for the field if it has not been seen before.
+// File extensions are kept, as the exporter uses them to pick out license files.
+func (s *Scrubber) pseudonym(field string, v string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p, ok := s.pseudonyms[v]; ok {
+		return p
+	}
+
+	ext := ""
+	if field == "filename" {
+		if i := strings.LastIndex(v, "."); i > 0 {
+			ext = v[i:]
+		}
+	}
+
+	s.counts[field]++
+	p := fmt.Sprintf("%s%d%s", field, s.counts[field], ext)
+	s.pseudonyms[v] = p
+
+	return p
+}
+
+// ip returns the pseudonym for an IP address; IPv4 addresses are mapped into 198.18.0.0/15 and IPv6 addresses into 2001:db8::/32, which are reserved for testing and documentation.
+func (s *Scrubber) ip(v string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p, ok := s.pseudonyms[v]; ok {
+		return p
+	}
+
+	var p string
+	if strings.Contains(v, ":") {
+		s.counts["ipv6"]++
+		p = fmt.Sprintf("2001:db8::%x", s.counts["ipv6"])
+	} else {
+		s.counts["ipv4"]++
+		n := s.counts["ipv4"]
+		p = fmt.Sprintf("198.%d.%d.%d", 18+(n>>16)&1, (n>>8)&255, n&255)
+	}
+
+	s.pseudonyms[v] = p
+
+	return p
+}
+
+// mac returns the pseudonym for a MAC address; pseudonyms are locally administered addresses, in 02:00:00:00:00:00/24, so they cannot clash with a real interface.
+func (s *Scrubber) mac(v string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := strings.ToLower(strings.ReplaceAll(v, "-", ":"))
+
+	if p, ok := s.pseudonyms[key]; ok {
+		return p
+	}
+
+	s.counts["mac"]++
+	n := s.counts["mac"]
+	p := fmt.Sprintf("02:00:00:%02x:%02x:%02x", (n>>16)&255, (n>>8)&255, n&255)
+	s.pseudonyms[key] = p
+
+	return p
+}
+
+func isNumber(v string) bool {
+	for _, r := range v {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func escape(v string) string {
+	return url.QueryEscape(v)
+}
+
+func unescape(v string) string {
+	u, err := url.QueryUnescape(v)
+	if err != nil {
+		return v
+	}
+
+	return u
+}
@@ -0,0 +1,159 @@
+package nitrotest_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/rokett/citrix-netscaler-exporter/netscaler/nitrotest"
+)
+
+func TestScrubberBody(t *testing.T) {
+	license := base64.StdEncoding.EncodeToString([]byte("# Citrix license\nINCREMENT CNS_V10_SERVER CITRIX 2024.0101 15-jan-2025 uncounted \\\n\tHOSTID=000c29a1b2c3 SIGN=\"0123 4567\"\nFEATURE CNS_SSE_SERVER CITRIX 2024.0101 permanent 1 SN=ABC123\n"))
+	scrubbedLicense := base64.StdEncoding.EncodeToString([]byte("INCREMENT CNS_V10_SERVER CITRIX 2024.0101 15-jan-2025\nFEATURE CNS_SSE_SERVER CITRIX 2024.0101 permanent\n"))
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "names and IP addresses",
+			body: `{"lbvserver":[{"name":"web_vs","primaryipaddress":"10.1.2.3","curclntconnections":"5"},{"name":"app_vs","primaryipaddress":"fd00::1"}]}`,
+			want: `{"lbvserver":[{"name":"name1","primaryipaddress":"198.18.0.1","curclntconnections":"5"},{"name":"name2","primaryipaddress":"2001:db8::1"}]}`,
+		},
+		{
+			name: "IP address in free text",
+			body: `{"errorcode":0,"message":"Connection to 10.1.2.3 failed"}`,
+			want: `{"errorcode":0,"message":"Connection to 198.18.0.1 failed"}`,
+		},
+		{
+			name: "MAC addresses",
+			body: `{"Interface":[{"id":"0/1","mac":"00:50:56:8e:4d:2b","description":"peer 00-50-56-8E-4D-2B, gateway 00:50:56:8e:4d:2c"}]}`,
+			want: `{"Interface":[{"id":"0/1","mac":"02:00:00:00:00:01","description":"peer 02:00:00:00:00:01, gateway 02:00:00:00:00:02"}]}`,
+		},
+		{
+			name: "service group member names",
+			body: `{"servicegroupmember":[{"servicegroupname":"sg_app?app01.example.com?8080","servername":"app01.example.com"},{"servicegroupname":"sg_app?10.1.2.3?443"}]}`,
+			want: `{"servicegroupmember":[{"servicegroupname":"servicegroupname1?servername1?8080","servername":"servername1"},{"servicegroupname":"servicegroupname1?198.18.0.1?443"}]}`,
+		},
+		{
+			name: "license file",
+			body: `{"systemfile":[{"filename":"FID_0123abcd.lic","filelocation":"/nsconfig/license","filecontent":"` + license + `"}]}`,
+			want: `{"systemfile":[{"filename":"filename1.lic","filelocation":"/nsconfig/license","filecontent":"` + scrubbedLicense + `"}]}`,
+		},
+		{
+			name: "default partition",
+			body: `{"nspartition":[{"partitionname":"default"},{"partitionname":"tenant1"}]}`,
+			want: `{"nspartition":[{"partitionname":"default"},{"partitionname":"partitionname1"}]}`,
+		},
+		{
+			name: "traffic domain alias",
+			body: `{"nstrafficdomain":[{"td":10,"aliasname":"tenant-finance","state":"ENABLED"}]}`,
+			want: `{"nstrafficdomain":[{"td":10,"aliasname":"aliasname1","state":"ENABLED"}]}`,
+		},
+		{
+			name: "not JSON",
+			body: `<html>Not Found</html>`,
+			want: `<html>Not Found</html>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := nitrotest.NewScrubber().Body([]byte(tt.body))
+
+			var gotValue, wantValue interface{}
+			if json.Unmarshal([]byte(tt.want), &wantValue) != nil {
+				if string(got) != tt.want {
+					t.Errorf("Body() = %s, want %s", got, tt.want)
+				}
+				return
+			}
+
+			err := json.Unmarshal(got, &gotValue)
+			if err != nil {
+				t.Fatalf("Body() = %s, which is not JSON: %v", got, err)
+			}
+
+			if !reflect.DeepEqual(gotValue, wantValue) {
+				t.Errorf("Body() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScrubberKey(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		want string
+	}{
+		{
+			name: "resource",
+			key:  "stat/lbvserver",
+			want: "stat/lbvserver",
+		},
+		{
+			name: "resource name",
+			key:  "stat/servicegroup/sg_app?statbindings=yes",
+			want: "stat/servicegroup/name1?statbindings=yes",
+		},
+		{
+			name: "filter",
+			key:  "config/aaasession?filter=username:alice,clientip:10.1.2.3,state:ACTIVE&pagesize=100&pageno=1",
+			want: "config/aaasession?filter=username:username1,clientip:198.18.0.1,state:ACTIVE&pagesize=100&pageno=1",
+		},
+		{
+			name: "args",
+			key:  "config/systemfile?args=filelocation:%2Fnsconfig%2Flicense,filename:FID_0123abcd.lic",
+			want: "config/systemfile?args=filelocation:%2Fnsconfig%2Flicense,filename:filename1.lic",
+		},
+		{
+			name: "partition",
+			key:  "partitions/tenant1/stat/servicegroup/sg_app",
+			want: "partitions/partitionname1/stat/servicegroup/name1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := nitrotest.NewScrubber().Key(tt.key)
+			if got != tt.want {
+				t.Errorf("Key() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScrubberKeyMatchesBody(t *testing.T) {
+	s := nitrotest.NewScrubber()
+
+	key := s.Key("stat/servicegroup/sg_app?statbindings=yes")
+	body := s.Body([]byte(`{"servicegroup":[{"servicegroupname":"sg_app","servicegroupmember":[{"servicegroupname":"sg_app?10.1.2.3?80","primaryipaddress":"10.1.2.3"}]}]}`))
+
+	var got struct {
+		ServiceGroup []struct {
+			Name    string `json:"servicegroupname"`
+			Members []struct {
+				Name string `json:"servicegroupname"`
+				IP   string `json:"primaryipaddress"`
+			} `json:"servicegroupmember"`
+		} `json:"servicegroup"`
+	}
+
+	err := json.Unmarshal(body, &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if key != "stat/servicegroup/"+got.ServiceGroup[0].Name+"?statbindings=yes" {
+		t.Errorf("Key() = %q does not name service group %q", key, got.ServiceGroup[0].Name)
+	}
+
+	member := got.ServiceGroup[0].Members[0]
+	if member.Name != got.ServiceGroup[0].Name+"?"+member.IP+"?80" {
+		t.Errorf("member %q does not match service group %q and IP address %q", member.Name, got.ServiceGroup[0].Name, member.IP)
+	}
+}
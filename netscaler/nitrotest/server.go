@@ -0,0 +1,598 @@
+// Package nitrotest provides a fake Nitro API server, serving canned responses, for testing code which talks to a NetScaler.
+package nitrotest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/rokett/citrix-netscaler-exporter/netscaler"
+)
+
+// Nitro error codes returned by the server; these match what a NetScaler returns.
+const (
+	errorCodeInvalidCredentials = 354
+	errorCodeSessionExpired     = 444
+)
+
+const (
+	apiPrefix       = "/nitro/v1/"
+	tokenCookie     = "NITRO_AUTH_TOKEN"
+	partitionPrefix = "partitions/"
+)
+
+// Server is a fake Nitro API.
+// Login creates a session, which every other request must present; as with a NetScaler the session is held in a cookie.
+// Each request is answered from the fixture stored against its key; see Key.
+// A session can be switched into an admin partition, after which requests are answered from the partition's fixtures; see PartitionKey.
+// The server can also stand in for NetScaler ADM, proxying requests to the instances added with AddManagedInstance.
+type Server struct {
+	*httptest.Server
+
+	// Username and Password are the only credentials which the server accepts.  If Username is empty, any credentials are accepted.
+	Username string
+	Password string
+
+	mu       sync.Mutex
+	fixtures map[string]response
+	// sessions maps each session token to the partition the session is in; empty for the default partition.
+	sessions map[string]string
+	latency  time.Duration
+	requests map[string]int
+	// instances maps the IP address of each instance managed by the server, when it stands in for NetScaler ADM, to the server for the instance.
+	instances map[string]*Server
+	// proxied maps each session with the server and managed instance, as a proxiedSession, to the partition which ADM's session with the instance is in.
+	proxied map[proxiedSession]string
+}
+
+// proxiedSession identifies the session which NetScaler ADM holds with a managed instance on behalf of a session with ADM.
+type proxiedSession struct {
+	token string
+	ip    string
+}
+
+type response struct {
+	status int
+	body   []byte
+}
+
+// NewServer starts a server with no fixtures.
+// Requests for resources with no fixture succeed, but return no resources, as a NetScaler does when none are configured.
+func NewServer() *Server {
+	s := &Server{
+		fixtures:  make(map[string]response),
+		sessions:  make(map[string]string),
+		requests:  make(map[string]int),
+		instances: make(map[string]*Server),
+		proxied:   make(map[proxiedSession]string),
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// NewServerWithFixtures starts a server with the fixtures in fsys loaded; see LoadFixtures.
+func NewServerWithFixtures(fsys fs.FS) (*Server, error) {
+	s := NewServer()
+
+	err := s.LoadFixtures(fsys)
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Key returns the key which a request for the resource is served from; for example stat/lbvserver.
+// The resource path is relative to /nitro/v1/, and rawQuery is the query string without the leading question mark.
+// Requests are served from the fixture which matches both path and query if there is one, otherwise from the fixture which matches the path alone.
+// When served from the fixture for the path alone, count=yes and pagesize/pageno are answered from the fixture's list, as a NetScaler would.
+func Key(resourcePath string, rawQuery string) string {
+	if rawQuery == "" {
+		return resourcePath
+	}
+
+	return resourcePath + "?" + rawQuery
+}
+
+// PartitionKey returns the key which a request is served from when the session has been switched into an admin partition other than default.
+// For example stat/lbvserver in partition tenant1 is served from partitions/tenant1/stat/lbvserver.
+// Requests in a partition are only served from the partition's fixtures.
+func PartitionKey(partition string, key string) string {
+	return partitionPrefix + partition + "/" + key
+}
+
+// FixtureFile returns the name of the file which holds the fixture for key.
+// The query string is escaped, so that the name is valid on every operating system; for example
+// config/systemfile?args=filelocation:%2Fnsconfig%2Flicense is stored as config/systemfile@args%3Dfilelocation%253A%252Fnsconfig%252Flicense.json
+func FixtureFile(key string) string {
+	parts := strings.SplitN(key, "?", 2)
+	if len(parts) == 1 {
+		return parts[0] + ".json"
+	}
+
+	return parts[0] + "@" + url.QueryEscape(parts[1]) + ".json"
+}
+
+// fixtureKey is the reverse of FixtureFile.
+func fixtureKey(file string) (string, error) {
+	name := strings.TrimSuffix(file, ".json")
+
+	parts := strings.SplitN(name, "@", 2)
+	if len(parts) == 1 {
+		return name, nil
+	}
+
+	query, err := url.QueryUnescape(parts[1])
+	if err != nil {
+		return "", errors.Wrap(err, "error unescaping query in fixture file name "+file)
+	}
+
+	return Key(parts[0], query), nil
+}
+
+// LoadFixtures loads every .json file in fsys as a fixture; the file name is mapped to a key by FixtureFile.
+func (s *Server) LoadFixtures(fsys fs.FS) error {
+	return fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || path.Ext(p) != ".json" {
+			return nil
+		}
+
+		body, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return errors.Wrap(err, "error reading fixture "+p)
+		}
+
+		key, err := fixtureKey(p)
+		if err != nil {
+			return err
+		}
+
+		s.SetFixture(key, body)
+
+		return nil
+	})
+}
+
+// SetFixture sets the body returned for requests matching key.
+func (s *Server) SetFixture(key string, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fixtures[key] = response{
+		status: http.StatusOK,
+		body:   body,
+	}
+}
+
+// SetError makes requests matching key fail with the given HTTP status and Nitro error.
+func (s *Server) SetError(key string, status int, code int64, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fixtures[key] = response{
+		status: status,
+		body:   nitroError(code, message),
+	}
+}
+
+// AddManagedInstance makes the server stand in for NetScaler ADM, managing the instance with the given IP address.
+// Requests carrying the ADM proxy header for the IP address are answered by the instance, without logging in to it, as ADM holds the instance's credentials.
+// Unless config/managed_device has a fixture, it lists every instance added.
+func (s *Server) AddManagedInstance(ip string, instance *Server) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.instances[ip] = instance
+}
+
+// SetLatency delays every response by d.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latency = d
+}
+
+// ExpireSessions ends every current session, so that the next request from each client fails as though its session had expired.
+func (s *Server) ExpireSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions = make(map[string]string)
+	s.proxied = make(map[proxiedSession]string)
+}
+
+// Requests returns the number of requests received for key, including the query string if there was one.
+// Login and logout are counted against config/login and config/logout.
+func (s *Server) Requests(key string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests[key]
+}
+
+// TotalRequests returns the number of requests received for every key.
+func (s *Server) TotalRequests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	total := 0
+	for _, n := range s.requests {
+		total += n
+	}
+
+	return total
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	latency := s.latency
+	s.mu.Unlock()
+
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+
+	if !strings.HasPrefix(r.URL.Path, apiPrefix) {
+		http.NotFound(w, r)
+		return
+	}
+
+	resourcePath := strings.TrimPrefix(r.URL.Path, apiPrefix)
+	key := Key(resourcePath, r.URL.RawQuery)
+
+	s.mu.Lock()
+	s.requests[key]++
+	s.mu.Unlock()
+
+	switch {
+	case r.Header.Get(netscaler.ADMProxyHeader) != "" && resourcePath != "config/login" && resourcePath != "config/logout":
+		s.proxy(w, r, resourcePath, key)
+	case r.Method == http.MethodPost && resourcePath == "config/login":
+		s.login(w, r)
+	case r.Method == http.MethodPost && resourcePath == "config/logout":
+		s.logout(w, r)
+	case r.Method == http.MethodPost && resourcePath == "config/nspartition" && r.URL.Query().Get("action") == "Switch":
+		s.switchPartition(w, r)
+	case r.Method == http.MethodGet:
+		s.get(w, r, resourcePath, key)
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, nitroError(1, "Method not supported"))
+	}
+}
+
+func (s *Server) login(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Login struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"login"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, nitroError(1, "Invalid JSON input"))
+		return
+	}
+
+	if s.Username != "" && (payload.Login.Username != s.Username || payload.Login.Password != s.Password) {
+		writeJSON(w, http.StatusUnauthorized, nitroError(errorCodeInvalidCredentials, "Invalid username or password"))
+		return
+	}
+
+	b := make([]byte, 16)
+	rand.Read(b)
+	token := hex.EncodeToString(b)
+
+	s.mu.Lock()
+	s.sessions[token] = ""
+	s.mu.Unlock()
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     tokenCookie,
+		Value:    token,
+		Path:     "/nitro/v1",
+		HttpOnly: true,
+	})
+
+	writeJSON(w, http.StatusCreated, nitroError(0, "Done"))
+}
+
+func (s *Server) logout(w http.ResponseWriter, r *http.Request) {
+	token, _, ok := s.session(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	delete(s.sessions, token)
+	for ps := range s.proxied {
+		if ps.token == token {
+			delete(s.proxied, ps)
+		}
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, nitroError(0, "Done"))
+}
+
+func (s *Server) switchPartition(w http.ResponseWriter, r *http.Request) {
+	token, _, ok := s.session(w, r)
+	if !ok {
+		return
+	}
+
+	partition, ok := switchPartitionName(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	s.sessions[token] = partition
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, nitroError(0, "Done"))
+}
+
+// switchPartitionName returns the partition named by a request to switch partition, or empty for the default partition, writing the error response if the request is not valid.
+func switchPartitionName(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var payload struct {
+		NSPartition struct {
+			PartitionName string `json:"partitionname"`
+		} `json:"nspartition"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil || payload.NSPartition.PartitionName == "" {
+		writeJSON(w, http.StatusBadRequest, nitroError(1, "Invalid JSON input"))
+		return "", false
+	}
+
+	partition := payload.NSPartition.PartitionName
+	if partition == "default" {
+		partition = ""
+	}
+
+	return partition, true
+}
+
+func (s *Server) get(w http.ResponseWriter, r *http.Request, resourcePath string, key string) {
+	_, partition, ok := s.session(w, r)
+	if !ok {
+		return
+	}
+
+	s.serveFixture(w, partition, resourcePath, key)
+}
+
+// serveFixture answers a request from the fixture for its key in the partition.
+func (s *Server) serveFixture(w http.ResponseWriter, partition string, resourcePath string, key string) {
+	if partition != "" {
+		key = PartitionKey(partition, key)
+		resourcePath = PartitionKey(partition, resourcePath)
+	}
+
+	s.mu.Lock()
+	resp, ok := s.fixtures[key]
+	if !ok {
+		resp, ok = s.fixtures[resourcePath]
+		if ok && key != resourcePath {
+			resp = pageFixture(resp, resourcePath, strings.SplitN(key, "?", 2)[1])
+		}
+	}
+	if !ok && resourcePath == "config/managed_device" && len(s.instances) > 0 {
+		resp, ok = s.managedDevices(), true
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		writeJSON(w, http.StatusOK, nitroError(0, "Done"))
+		return
+	}
+
+	writeJSON(w, resp.status, resp.body)
+}
+
+// pageFixture answers a count=yes or paged request from the fixture for the whole resource.
+// Responses which are not a successful list of the resource are returned unchanged.
+func pageFixture(resp response, resourcePath string, rawQuery string) response {
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil || resp.status != http.StatusOK {
+		return resp
+	}
+
+	pageSize, _ := strconv.Atoi(query.Get("pagesize"))
+	pageNo, _ := strconv.Atoi(query.Get("pageno"))
+	count := query.Get("count") == "yes"
+
+	if !count && (pageSize <= 0 || pageNo <= 0) {
+		return resp
+	}
+
+	var body map[string]json.RawMessage
+
+	err = json.Unmarshal(resp.body, &body)
+	if err != nil {
+		return resp
+	}
+
+	name := path.Base(resourcePath)
+
+	for k, v := range body {
+		if !strings.EqualFold(k, name) {
+			continue
+		}
+
+		var list []json.RawMessage
+
+		err = json.Unmarshal(v, &list)
+		if err != nil {
+			return resp
+		}
+
+		if count {
+			body[k], _ = json.Marshal([]map[string]int{{"__count": len(list)}})
+		} else {
+			start := (pageNo - 1) * pageSize
+			if start > len(list) {
+				start = len(list)
+			}
+
+			end := start + pageSize
+			if end > len(list) {
+				end = len(list)
+			}
+
+			body[k], _ = json.Marshal(list[start:end])
+		}
+
+		b, _ := json.Marshal(body)
+
+		return response{
+			status: resp.status,
+			body:   b,
+		}
+	}
+
+	return resp
+}
+
+// managedDevices returns the config/managed_device response listing every managed instance.  The caller must hold s.mu.
+func (s *Server) managedDevices() response {
+	ips := make([]string, 0, len(s.instances))
+	for ip := range s.instances {
+		ips = append(ips, ip)
+	}
+	sort.Strings(ips)
+
+	devices := make([]map[string]string, 0, len(ips))
+	for _, ip := range ips {
+		devices = append(devices, map[string]string{
+			"ip_address":     ip,
+			"hostname":       ip,
+			"type":           "nsvpx",
+			"instance_state": "Up",
+		})
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"errorcode":      0,
+		"message":        "Done",
+		"managed_device": devices,
+	})
+
+	return response{
+		status: http.StatusOK,
+		body:   body,
+	}
+}
+
+// proxy answers a request carrying the ADM proxy header from the managed instance, as NetScaler ADM would.
+// The request must carry a session with the server; the instance itself needs no session.
+// Switching partition switches ADM's session with the instance, which belongs to the session with the server, so requests through another session are not affected.
+func (s *Server) proxy(w http.ResponseWriter, r *http.Request, resourcePath string, key string) {
+	token, _, ok := s.session(w, r)
+	if !ok {
+		return
+	}
+
+	ip := r.Header.Get(netscaler.ADMProxyHeader)
+
+	s.mu.Lock()
+	instance, ok := s.instances[ip]
+	s.mu.Unlock()
+
+	if !ok {
+		writeJSON(w, http.StatusNotFound, nitroError(1, "Device with IP address "+ip+" is not managed"))
+		return
+	}
+
+	instance.mu.Lock()
+	instance.requests[key]++
+	instance.mu.Unlock()
+
+	ps := proxiedSession{token: token, ip: ip}
+
+	switch {
+	case r.Method == http.MethodPost && resourcePath == "config/nspartition" && r.URL.Query().Get("action") == "Switch":
+		partition, ok := switchPartitionName(w, r)
+		if !ok {
+			return
+		}
+
+		s.mu.Lock()
+		s.proxied[ps] = partition
+		s.mu.Unlock()
+
+		writeJSON(w, http.StatusOK, nitroError(0, "Done"))
+	case r.Method == http.MethodGet:
+		s.mu.Lock()
+		partition := s.proxied[ps]
+		s.mu.Unlock()
+
+		instance.serveFixture(w, partition, resourcePath, key)
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, nitroError(1, "Method not supported"))
+	}
+}
+
+// session checks that the request carries a current session, writing the error response if it does not.
+// It returns the session token, and the partition the session is in.
+func (s *Server) session(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	cookie, err := r.Cookie(tokenCookie)
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, nitroError(errorCodeInvalidCredentials, "Invalid username or password"))
+		return "", "", false
+	}
+
+	s.mu.Lock()
+	partition, ok := s.sessions[cookie.Value]
+	s.mu.Unlock()
+
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, nitroError(errorCodeSessionExpired, "Session expired"))
+		return "", "", false
+	}
+
+	return cookie.Value, partition, true
+}
+
+func nitroError(code int64, message string) []byte {
+	severity := "NONE"
+	if code != 0 {
+		severity = "ERROR"
+	}
+
+	b, _ := json.Marshal(map[string]interface{}{
+		"errorcode": code,
+		"message":   message,
+		"severity":  severity,
+	})
+
+	return b
+}
+
+func writeJSON(w http.ResponseWriter, status int, body []byte) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(body)
+}
@@ -1,20 +1,8 @@
 package netscaler
 
-// NSAPIResponse represents the main portion of the Nitro API response
+// NSAPIResponse represents the portion of the Nitro API response which is common to every resource
 type NSAPIResponse struct {
-	Errorcode               int64                     `json:"errorcode"`
-	Message                 string                    `json:"message"`
-	Severity                string                    `json:"severity"`
-	NSLicense               NSLicense                 `json:"nslicense"`
-	NSStats                 NSStats                   `json:"ns"`
-	InterfaceStats          []InterfaceStats          `json:"Interface"`
-	VirtualServerStats      []VirtualServerStats      `json:"lbvserver"`
-	ServiceStats            []ServiceStats            `json:"service"`
-	ServiceGroups           []ServiceGroups           `json:"servicegroup"`
-	ServiceGroupMemberStats []ServiceGroupMemberStats `json:"servicegroupmember"`
-	GSLBServiceStats        []GSLBServiceStats        `json:"gslbservice"`
-	GSLBVirtualServerStats  []GSLBVirtualServerStats  `json:"gslbvserver"`
-	CSVirtualServerStats    []CSVirtualServerStats    `json:"csvserver"`
-	VPNVirtualServerStats   []VPNVirtualServerStats   `json:"vpnvserver"`
-	AAAStats                AAAStats                  `json:"aaa"`
+	Errorcode int64  `json:"errorcode"`
+	Message   string `json:"message"`
+	Severity  string `json:"severity"`
 }
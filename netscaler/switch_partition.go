@@ -0,0 +1,64 @@
+package netscaler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// SwitchPartitionPayload is the request body that needs to be sent to NetScaler to switch partition
+type SwitchPartitionPayload struct {
+	NSPartition struct {
+		PartitionName string `json:"partitionname"`
+	} `json:"nspartition"`
+}
+
+// SwitchPartition switches the session into the named admin partition.
+// Requests made after switching only see the resources of that partition.
+func SwitchPartition(c *NitroClient, partition string) error {
+	url := c.url + "config/nspartition?action=Switch"
+
+	var p SwitchPartitionPayload
+
+	p.NSPartition.PartitionName = partition
+
+	reqBody, err := json.Marshal(p)
+	if err != nil {
+		return errors.Wrap(err, "error marshalling payload")
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return errors.Wrap(err, "error creating HTTP request")
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	c.setProxyHeader(req)
+
+	resp, err := c.client.Do(req)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		return errors.Wrap(err, "error sending request")
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+
+	switch resp.StatusCode {
+	case 200, 201:
+		err = checkErrorcode(resp, body)
+		if err != nil {
+			return err
+		}
+
+		c.partition = partition
+
+		return nil
+	default:
+		return newNitroError(resp, body)
+	}
+}
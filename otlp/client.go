@@ -0,0 +1,173 @@
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/http2"
+)
+
+// Protocols which metrics can be pushed with; the names match the OTEL_EXPORTER_OTLP_PROTOCOL environment variable.
+const (
+	ProtocolGRPC = "grpc"
+	ProtocolHTTP = "http/protobuf"
+)
+
+const (
+	httpPath = "/v1/metrics"
+	grpcPath = "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export"
+)
+
+// Client pushes metrics to an OTLP receiver, such as the OpenTelemetry Collector.
+type Client struct {
+	url      string
+	protocol string
+	headers  map[string]string
+	client   *http.Client
+}
+
+// NewClient creates a client which pushes to the receiver at endpoint, with the given protocol.
+// For gRPC, endpoint is the receiver's base URL; http:// connects without TLS.  For HTTP, /v1/metrics is added to the endpoint unless it already ends with it.
+// headers are sent with every request; for example to authenticate with the receiver.
+func NewClient(endpoint string, protocol string, headers map[string]string, timeout time.Duration) (*Client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing OTLP endpoint")
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.Errorf("OTLP endpoint %q must start with http:// or https://", endpoint)
+	}
+
+	c := &Client{
+		protocol: protocol,
+		headers:  headers,
+		client: &http.Client{
+			Timeout: timeout,
+		},
+	}
+
+	base := strings.TrimRight(endpoint, "/")
+
+	switch protocol {
+	case ProtocolGRPC:
+		c.url = base + grpcPath
+
+		transport := &http2.Transport{}
+
+		// gRPC needs HTTP/2, which without TLS has to be requested explicitly.
+		if u.Scheme == "http" {
+			transport.AllowHTTP = true
+			transport.DialTLSContext = func(ctx context.Context, network string, addr string, _ *tls.Config) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, addr)
+			}
+		}
+
+		c.client.Transport = transport
+	case ProtocolHTTP:
+		c.url = base
+		if !strings.HasSuffix(base, httpPath) {
+			c.url = base + httpPath
+		}
+	default:
+		return nil, errors.Errorf("OTLP protocol %q must be %s or %s", protocol, ProtocolGRPC, ProtocolHTTP)
+	}
+
+	return c, nil
+}
+
+// Export sends an ExportMetricsServiceRequest message, as returned by Encode, to the receiver.
+func (c *Client) Export(body []byte) error {
+	if c.protocol == ProtocolGRPC {
+		return c.exportGRPC(body)
+	}
+
+	req, err := http.NewRequest("POST", c.url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "error creating HTTP request")
+	}
+
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	c.setHeaders(req)
+
+	resp, err := c.client.Do(req)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		return errors.Wrap(err, "error sending request")
+	}
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	if resp.StatusCode/100 != 2 {
+		return errors.Errorf("OTLP receiver returned %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+
+	return nil
+}
+
+// exportGRPC sends the message as a unary gRPC call.  The call's status is returned in the trailers, or in the headers if the receiver failed the call before responding.
+func (c *Client) exportGRPC(body []byte) error {
+	// Each gRPC message is prefixed with a compression flag, which is 0 as the message is not compressed, and its length.
+	frame := make([]byte, 5, 5+len(body))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(body)))
+	frame = append(frame, body...)
+
+	req, err := http.NewRequest("POST", c.url, bytes.NewReader(frame))
+	if err != nil {
+		return errors.Wrap(err, "error creating HTTP request")
+	}
+
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	c.setHeaders(req)
+
+	resp, err := c.client.Do(req)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		return errors.Wrap(err, "error sending request")
+	}
+
+	// The trailers are only available once the body has been read.
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("OTLP receiver returned %s", resp.Status)
+	}
+
+	status := resp.Trailer.Get("Grpc-Status")
+	message := resp.Trailer.Get("Grpc-Message")
+	if status == "" {
+		status = resp.Header.Get("Grpc-Status")
+		message = resp.Header.Get("Grpc-Message")
+	}
+
+	if status != "0" {
+		if m, err := url.PathUnescape(message); err == nil {
+			message = m
+		}
+
+		return errors.Errorf("OTLP receiver returned gRPC status %s: %s", status, message)
+	}
+
+	return nil
+}
+
+func (c *Client) setHeaders(req *http.Request) {
+	for name, value := range c.headers {
+		req.Header.Set(name, value)
+	}
+}
@@ -0,0 +1,255 @@
+// Package otlp converts Prometheus metric families to OpenTelemetry metrics, and pushes them to an OTLP receiver over gRPC or HTTP.
+// The OTLP protobuf messages are encoded by hand, as only a small part of the protocol is needed.
+package otlp
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Protobuf wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+)
+
+// aggregationTemporalityCumulative is the OTLP AggregationTemporality of Prometheus counters.
+const aggregationTemporalityCumulative = 2
+
+// Resource is a set of metrics which share the same resource attributes; for example those from one NetScaler.
+type Resource struct {
+	Attributes map[string]string
+	Families   []*dto.MetricFamily
+}
+
+// Resources groups the series in families into resources, by the values of the labels in resourceLabels.
+// resourceLabels maps each label to the resource attribute it becomes; for example ns_instance to service.instance.id.
+// Those labels are removed from the series, and every resource also gets the attributes in common.  Empty values are left out.
+func Resources(families []*dto.MetricFamily, common map[string]string, resourceLabels map[string]string) []Resource {
+	type group struct {
+		attributes map[string]string
+		families   map[string]*dto.MetricFamily
+		order      []string
+	}
+
+	groups := make(map[string]*group)
+	var keys []string
+
+	for _, f := range families {
+		for _, m := range f.GetMetric() {
+			attributes := make(map[string]string)
+			for name, value := range common {
+				if value != "" {
+					attributes[name] = value
+				}
+			}
+
+			var labels []*dto.LabelPair
+
+			for _, l := range m.GetLabel() {
+				attribute, ok := resourceLabels[l.GetName()]
+				if !ok {
+					labels = append(labels, l)
+					continue
+				}
+
+				if l.GetValue() != "" {
+					attributes[attribute] = l.GetValue()
+				}
+			}
+
+			// As in Prometheus, a label with an empty value is the same as no label, so series without it share a resource with those where it is empty.
+			key := ""
+			for _, name := range sortedKeys(attributes) {
+				key += name + "=" + attributes[name] + "\xff"
+			}
+
+			g, ok := groups[key]
+			if !ok {
+				g = &group{
+					attributes: attributes,
+					families:   make(map[string]*dto.MetricFamily),
+				}
+				groups[key] = g
+				keys = append(keys, key)
+			}
+
+			gf, ok := g.families[f.GetName()]
+			if !ok {
+				gf = &dto.MetricFamily{
+					Name: f.Name,
+					Help: f.Help,
+					Type: f.Type,
+				}
+				g.families[f.GetName()] = gf
+				g.order = append(g.order, f.GetName())
+			}
+
+			metric := *m
+			metric.Label = labels
+			gf.Metric = append(gf.Metric, &metric)
+		}
+	}
+
+	sort.Strings(keys)
+
+	resources := make([]Resource, 0, len(keys))
+	for _, key := range keys {
+		g := groups[key]
+
+		r := Resource{Attributes: g.attributes}
+		for _, name := range g.order {
+			r.Families = append(r.Families, g.families[name])
+		}
+
+		resources = append(resources, r)
+	}
+
+	return resources
+}
+
+// Encode returns the ExportMetricsServiceRequest message for the resources.
+// scope names the instrumentation scope which every metric belongs to.  Counters are sent as cumulative sums, and gauges and untyped metrics as gauges.
+// Other metric types are not produced by the exporter, and are left out.
+//
+// The start time of each sum is left unset, as it is not known; NetScaler counters count from when the appliance, or the entity, last started or was cleared,
+// not from when the exporter started, and a NetScaler which restarts would otherwise appear not to have reset its counters.
+func Encode(resources []Resource, scope string, version string, now time.Time) []byte {
+	req := proto.NewBuffer(nil)
+
+	for _, r := range resources {
+		resource := proto.NewBuffer(nil)
+		for _, name := range sortedKeys(r.Attributes) {
+			encodeBytes(resource, 1, encodeKeyValue(name, r.Attributes[name]))
+		}
+
+		scopeMetrics := proto.NewBuffer(nil)
+
+		s := proto.NewBuffer(nil)
+		encodeString(s, 1, scope)
+		encodeString(s, 2, version)
+		encodeBytes(scopeMetrics, 1, s.Bytes())
+
+		for _, f := range r.Families {
+			metric := encodeMetric(f, now)
+			if metric != nil {
+				encodeBytes(scopeMetrics, 2, metric)
+			}
+		}
+
+		resourceMetrics := proto.NewBuffer(nil)
+		encodeBytes(resourceMetrics, 1, resource.Bytes())
+		encodeBytes(resourceMetrics, 2, scopeMetrics.Bytes())
+
+		encodeBytes(req, 1, resourceMetrics.Bytes())
+	}
+
+	return req.Bytes()
+}
+
+// encodeMetric returns the Metric message for the family, or nil if its type is not supported.
+func encodeMetric(f *dto.MetricFamily, now time.Time) []byte {
+	points := proto.NewBuffer(nil)
+
+	for _, m := range f.GetMetric() {
+		var value float64
+
+		switch f.GetType() {
+		case dto.MetricType_GAUGE:
+			value = m.GetGauge().GetValue()
+		case dto.MetricType_COUNTER:
+			value = m.GetCounter().GetValue()
+		case dto.MetricType_UNTYPED:
+			value = m.GetUntyped().GetValue()
+		default:
+			return nil
+		}
+
+		ts := now
+		if m.TimestampMs != nil {
+			ts = time.Unix(0, m.GetTimestampMs()*int64(time.Millisecond))
+		}
+
+		point := proto.NewBuffer(nil)
+		encodeFixed64(point, 3, uint64(ts.UnixNano()))
+		encodeFixed64(point, 4, math.Float64bits(value))
+		for _, l := range m.GetLabel() {
+			if l.GetValue() != "" {
+				encodeBytes(point, 7, encodeKeyValue(l.GetName(), l.GetValue()))
+			}
+		}
+
+		encodeBytes(points, 1, point.Bytes())
+	}
+
+	metric := proto.NewBuffer(nil)
+	encodeString(metric, 1, f.GetName())
+	encodeString(metric, 2, f.GetHelp())
+
+	if f.GetType() == dto.MetricType_COUNTER {
+		sum := proto.NewBuffer(points.Bytes())
+		encodeVarint(sum, 2, aggregationTemporalityCumulative)
+		encodeVarint(sum, 3, 1)
+		encodeBytes(metric, 7, sum.Bytes())
+	} else {
+		encodeBytes(metric, 5, points.Bytes())
+	}
+
+	return metric.Bytes()
+}
+
+// encodeKeyValue returns the KeyValue message for a string attribute.
+func encodeKeyValue(key string, value string) []byte {
+	anyValue := proto.NewBuffer(nil)
+	encodeString(anyValue, 1, value)
+
+	kv := proto.NewBuffer(nil)
+	encodeString(kv, 1, key)
+	encodeBytes(kv, 2, anyValue.Bytes())
+
+	return kv.Bytes()
+}
+
+func encodeTag(b *proto.Buffer, field int, wireType int) {
+	b.EncodeVarint(uint64(field<<3 | wireType))
+}
+
+func encodeVarint(b *proto.Buffer, field int, v uint64) {
+	encodeTag(b, field, wireVarint)
+	b.EncodeVarint(v)
+}
+
+func encodeFixed64(b *proto.Buffer, field int, v uint64) {
+	encodeTag(b, field, wireFixed64)
+	b.EncodeFixed64(v)
+}
+
+func encodeBytes(b *proto.Buffer, field int, v []byte) {
+	encodeTag(b, field, wireBytes)
+	b.EncodeRawBytes(v)
+}
+
+// encodeString encodes a string field, leaving it out if it is empty as protobuf does.
+func encodeString(b *proto.Buffer, field int, v string) {
+	if v == "" {
+		return
+	}
+
+	encodeTag(b, field, wireBytes)
+	b.EncodeStringBytes(v)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,399 @@
+// Package otlptest provides a stand-in OTLP receiver, which accepts metrics over gRPC and HTTP and decodes them, for testing code which pushes metrics.
+package otlptest
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+const (
+	httpPath = "/v1/metrics"
+	grpcPath = "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export"
+)
+
+// gRPC status codes returned by the receiver.
+const (
+	grpcOK          = 0
+	grpcInvalid     = 3
+	grpcUnavailable = 14
+)
+
+// Receiver is a stand-in OTLP receiver.  It serves OTLP/HTTP at /v1/metrics, and OTLP/gRPC over HTTP/2 without TLS, on the same URL.
+type Receiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []Request
+	failure  string
+}
+
+// Request is an export request received by the receiver.
+type Request struct {
+	// Protocol is grpc or http/protobuf.
+	Protocol  string
+	Header    http.Header
+	Resources []Resource
+}
+
+// Resource is a ResourceMetrics message, with the metrics of every scope.
+type Resource struct {
+	Attributes map[string]string
+	Metrics    []Metric
+}
+
+// Metric is a gauge or sum; other types are not decoded.
+type Metric struct {
+	Name string
+	// Type is gauge or sum.
+	Type      string
+	Monotonic bool
+	Points    []Point
+}
+
+// Point is a NumberDataPoint.
+type Point struct {
+	Attributes map[string]string
+	Start      time.Time
+	Time       time.Time
+	Value      float64
+}
+
+// NewReceiver starts a receiver.
+func NewReceiver() *Receiver {
+	r := &Receiver{}
+
+	r.Server = httptest.NewServer(h2c.NewHandler(http.HandlerFunc(r.serveHTTP), &http2.Server{}))
+
+	return r
+}
+
+// Requests returns every request received, in the order they were received.
+func (r *Receiver) Requests() []Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Request(nil), r.requests...)
+}
+
+// Fail makes every request fail with the message, as an unavailable receiver would, until Fail is called with an empty message.
+func (r *Receiver) Fail(message string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.failure = message
+}
+
+func (r *Receiver) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	r.mu.Lock()
+	failure := r.failure
+	r.mu.Unlock()
+
+	switch req.URL.Path {
+	case httpPath:
+		if failure != "" {
+			http.Error(w, failure, http.StatusServiceUnavailable)
+			return
+		}
+
+		err = r.receive("http/protobuf", req.Header, body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+	case grpcPath:
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+
+		if failure != "" {
+			writeGRPCStatus(w, grpcUnavailable, failure)
+			return
+		}
+
+		if len(body) < 5 || int(binary.BigEndian.Uint32(body[1:5])) != len(body)-5 {
+			writeGRPCStatus(w, grpcInvalid, "invalid gRPC message frame")
+			return
+		}
+
+		err = r.receive("grpc", req.Header, body[5:])
+		if err != nil {
+			writeGRPCStatus(w, grpcInvalid, err.Error())
+			return
+		}
+
+		// The response is an empty ExportMetricsServiceResponse.
+		w.Write(make([]byte, 5))
+		writeGRPCStatus(w, grpcOK, "")
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func writeGRPCStatus(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Grpc-Status", strconv.Itoa(code))
+	w.Header().Set("Grpc-Message", message)
+}
+
+func (r *Receiver) receive(protocol string, header http.Header, body []byte) error {
+	resources, err := decodeRequest(body)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.requests = append(r.requests, Request{
+		Protocol:  protocol,
+		Header:    header,
+		Resources: resources,
+	})
+
+	return nil
+}
+
+// field is a decoded protobuf field; v holds varint and fixed64 values, and b length delimited values.
+type field struct {
+	num int
+	v   uint64
+	b   []byte
+}
+
+// decodeFields splits a protobuf message into its fields.
+func decodeFields(msg []byte) ([]field, error) {
+	var fields []field
+
+	for len(msg) > 0 {
+		tag, n := binary.Uvarint(msg)
+		if n <= 0 {
+			return nil, errors.New("error decoding field tag")
+		}
+		msg = msg[n:]
+
+		f := field{num: int(tag >> 3)}
+
+		switch tag & 7 {
+		case 0:
+			f.v, n = binary.Uvarint(msg)
+			if n <= 0 {
+				return nil, errors.Errorf("error decoding field %d", f.num)
+			}
+		case 1:
+			if len(msg) < 8 {
+				return nil, errors.Errorf("error decoding field %d", f.num)
+			}
+			f.v, n = binary.LittleEndian.Uint64(msg), 8
+		case 2:
+			length, l := binary.Uvarint(msg)
+			if l <= 0 || uint64(len(msg)-l) < length {
+				return nil, errors.Errorf("error decoding field %d", f.num)
+			}
+			f.b, n = msg[l:l+int(length)], l+int(length)
+		case 5:
+			if len(msg) < 4 {
+				return nil, errors.Errorf("error decoding field %d", f.num)
+			}
+			f.v, n = uint64(binary.LittleEndian.Uint32(msg)), 4
+		default:
+			return nil, errors.Errorf("unsupported wire type %d", tag&7)
+		}
+		msg = msg[n:]
+
+		fields = append(fields, f)
+	}
+
+	return fields, nil
+}
+
+func decodeRequest(msg []byte) ([]Resource, error) {
+	fields, err := decodeFields(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []Resource
+
+	for _, f := range fields {
+		if f.num != 1 {
+			continue
+		}
+
+		r, err := decodeResourceMetrics(f.b)
+		if err != nil {
+			return nil, err
+		}
+
+		resources = append(resources, r)
+	}
+
+	return resources, nil
+}
+
+func decodeResourceMetrics(msg []byte) (Resource, error) {
+	r := Resource{Attributes: make(map[string]string)}
+
+	fields, err := decodeFields(msg)
+	if err != nil {
+		return r, err
+	}
+
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			resource, err := decodeFields(f.b)
+			if err != nil {
+				return r, err
+			}
+
+			for _, a := range resource {
+				if a.num == 1 {
+					err = decodeKeyValue(a.b, r.Attributes)
+					if err != nil {
+						return r, err
+					}
+				}
+			}
+		case 2:
+			scope, err := decodeFields(f.b)
+			if err != nil {
+				return r, err
+			}
+
+			for _, m := range scope {
+				if m.num != 2 {
+					continue
+				}
+
+				metric, err := decodeMetric(m.b)
+				if err != nil {
+					return r, err
+				}
+
+				r.Metrics = append(r.Metrics, metric)
+			}
+		}
+	}
+
+	return r, nil
+}
+
+func decodeMetric(msg []byte) (Metric, error) {
+	var m Metric
+
+	fields, err := decodeFields(msg)
+	if err != nil {
+		return m, err
+	}
+
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			m.Name = string(f.b)
+		case 5, 7:
+			m.Type = "gauge"
+			if f.num == 7 {
+				m.Type = "sum"
+			}
+
+			data, err := decodeFields(f.b)
+			if err != nil {
+				return m, err
+			}
+
+			for _, d := range data {
+				switch {
+				case d.num == 1:
+					p, err := decodePoint(d.b)
+					if err != nil {
+						return m, err
+					}
+
+					m.Points = append(m.Points, p)
+				case d.num == 3 && f.num == 7:
+					m.Monotonic = d.v == 1
+				}
+			}
+		}
+	}
+
+	return m, nil
+}
+
+func decodePoint(msg []byte) (Point, error) {
+	p := Point{Attributes: make(map[string]string)}
+
+	fields, err := decodeFields(msg)
+	if err != nil {
+		return p, err
+	}
+
+	for _, f := range fields {
+		switch f.num {
+		case 2:
+			p.Start = time.Unix(0, int64(f.v))
+		case 3:
+			p.Time = time.Unix(0, int64(f.v))
+		case 4:
+			p.Value = math.Float64frombits(f.v)
+		case 6:
+			p.Value = float64(int64(f.v))
+		case 7:
+			err = decodeKeyValue(f.b, p.Attributes)
+			if err != nil {
+				return p, err
+			}
+		}
+	}
+
+	return p, nil
+}
+
+// decodeKeyValue adds a KeyValue message with a string value to attributes.
+func decodeKeyValue(msg []byte, attributes map[string]string) error {
+	fields, err := decodeFields(msg)
+	if err != nil {
+		return err
+	}
+
+	var key, value string
+
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			key = string(f.b)
+		case 2:
+			anyValue, err := decodeFields(f.b)
+			if err != nil {
+				return err
+			}
+
+			for _, v := range anyValue {
+				if v.num == 1 {
+					value = string(v.b)
+				}
+			}
+		}
+	}
+
+	attributes[key] = value
+
+	return nil
+}
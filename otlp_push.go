@@ -0,0 +1,70 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/rokett/citrix-netscaler-exporter/otlp"
+)
+
+var (
+	otlpExportFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "citrix_netscaler_otlp_export_failures_total",
+			Help: "Number of polls whose metrics could not be pushed to the OTLP receiver",
+		},
+		[]string{
+			"ns_instance",
+		},
+	)
+
+	// otlpClient pushes the metrics from every poll to the OTLP receiver; nil if OTLP export is not enabled.
+	otlpClient *otlp.Client
+
+	// otlpResourceLabels are the labels which become resource attributes, rather than attributes of each data point.
+	otlpResourceLabels = map[string]string{
+		"ns_instance": "service.instance.id",
+		"partition":   "partition",
+	}
+)
+
+// newOTLPClient creates the client for the OTLP receiver given by the otlp flags.
+func newOTLPClient() (*otlp.Client, error) {
+	headers := make(map[string]string)
+
+	for _, h := range strings.Split(*otlpHeaders, ",") {
+		if strings.TrimSpace(h) == "" {
+			continue
+		}
+
+		parts := strings.SplitN(h, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, errors.Errorf("invalid OTLP header %q; must be name=value", h)
+		}
+
+		headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+
+	return otlp.NewClient(*otlpEndpoint, *otlpProtocol, headers, 30*time.Second)
+}
+
+// pushOTLP sends the metrics from a poll of the target to the OTLP receiver.
+// The metrics are grouped into a resource for each partition, and the target's service discovery labels, such as site, are added to every resource.
+func (t *polledTarget) pushOTLP(families []*dto.MetricFamily) {
+	attributes := t.sdLabels()
+	attributes["service.name"] = app
+	attributes["service.version"] = version
+
+	resources := otlp.Resources(families, attributes, otlpResourceLabels)
+
+	err := otlpClient.Export(otlp.Encode(resources, app, version, time.Now()))
+	if err != nil {
+		otlpExportFailures.WithLabelValues(t.nsInstance).Inc()
+		level.Error(logger).Log("msg", "error pushing metrics to the OTLP receiver", "target", t.URL, "err", err)
+	}
+}
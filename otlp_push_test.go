@@ -0,0 +1,173 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/rokett/citrix-netscaler-exporter/config"
+	"github.com/rokett/citrix-netscaler-exporter/otlp"
+	"github.com/rokett/citrix-netscaler-exporter/otlp/otlptest"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// otlpTestFamilies returns a gauge and a counter for a virtual server in each of two partitions of ns01.
+func otlpTestFamilies(t *testing.T) []*dto.MetricFamily {
+	t.Helper()
+
+	state := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "virtual_servers_state", Help: "Current state of the server"}, []string{"ns_instance", "partition", "td", "virtual_server"})
+	hits := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "virtual_servers_total_hits", Help: "Total virtual server hits"}, []string{"ns_instance", "partition", "td", "virtual_server"})
+
+	state.WithLabelValues("ns01", "default", "0", "vs_web").Set(1)
+	state.WithLabelValues("ns01", "tenant1", "", "vs_ledger").Set(0)
+	hits.WithLabelValues("ns01", "default", "0", "vs_web").Add(1200)
+	hits.WithLabelValues("ns01", "tenant1", "", "vs_ledger").Add(31)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(state, hits)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return families
+}
+
+func TestPushOTLP(t *testing.T) {
+	logger = log.NewNopLogger()
+
+	defer func(c *otlp.Client) {
+		otlpClient = c
+	}(otlpClient)
+
+	for _, protocol := range []string{otlp.ProtocolGRPC, otlp.ProtocolHTTP} {
+		t.Run(protocol, func(t *testing.T) {
+			receiver := otlptest.NewReceiver()
+			defer receiver.Close()
+
+			var err error
+
+			otlpClient, err = otlp.NewClient(receiver.URL, protocol, map[string]string{"Authorization": "Bearer token"}, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			target := newPolledTarget(config.Target{URL: "https://ns01", Labels: map[string]string{"site": "lon"}}, "", nil)
+			target.pushOTLP(otlpTestFamilies(t))
+
+			requests := receiver.Requests()
+			if len(requests) != 1 {
+				t.Fatalf("receiver got %d requests, want 1", len(requests))
+			}
+
+			req := requests[0]
+
+			if req.Protocol != protocol {
+				t.Errorf("request protocol = %q, want %q", req.Protocol, protocol)
+			}
+
+			if got := req.Header.Get("Authorization"); got != "Bearer token" {
+				t.Errorf("Authorization header = %q, want Bearer token", got)
+			}
+
+			// One resource for each partition, which the partition and ns_instance labels become attributes of.
+			if len(req.Resources) != 2 {
+				t.Fatalf("request has %d resources, want 2", len(req.Resources))
+			}
+
+			for i, partition := range []string{"default", "tenant1"} {
+				r := req.Resources[i]
+
+				for name, want := range map[string]string{
+					"service.instance.id": "ns01",
+					"service.name":        app,
+					"partition":           partition,
+					"site":                "lon",
+				} {
+					if got := r.Attributes[name]; got != want {
+						t.Errorf("resource %d attribute %s = %q, want %q", i, name, got, want)
+					}
+				}
+
+				if len(r.Metrics) != 2 {
+					t.Fatalf("resource %d has %d metrics, want 2", i, len(r.Metrics))
+				}
+
+				for _, m := range r.Metrics {
+					if len(m.Points) != 1 {
+						t.Fatalf("metric %s has %d points, want 1", m.Name, len(m.Points))
+					}
+
+					p := m.Points[0]
+
+					if _, ok := p.Attributes["ns_instance"]; ok {
+						t.Errorf("metric %s has an ns_instance attribute, which belongs to the resource", m.Name)
+					}
+
+					if _, ok := p.Attributes["partition"]; ok {
+						t.Errorf("metric %s has a partition attribute, which belongs to the resource", m.Name)
+					}
+
+					if p.Time.IsZero() {
+						t.Errorf("metric %s has no time", m.Name)
+					}
+
+					// The start of NetScaler counters is not known.
+					if !p.Start.IsZero() {
+						t.Errorf("metric %s has start time %v, want none", m.Name, p.Start)
+					}
+				}
+			}
+
+			hits := req.Resources[0].Metrics[1]
+			if hits.Name != "virtual_servers_total_hits" || hits.Type != "sum" || !hits.Monotonic || hits.Points[0].Value != 1200 {
+				t.Errorf("resource 0 metric 1 = %+v, want a monotonic sum of 1200 virtual server hits", hits)
+			}
+
+			if got := hits.Points[0].Attributes; got["td"] != "0" || got["virtual_server"] != "vs_web" {
+				t.Errorf("hits attributes = %v, want td 0 and virtual server vs_web", got)
+			}
+
+			// Empty labels are left out.
+			if _, ok := req.Resources[1].Metrics[0].Points[0].Attributes["td"]; ok {
+				t.Error("vs_ledger has a td attribute, although its td label is empty")
+			}
+		})
+	}
+}
+
+func TestPushOTLPFailure(t *testing.T) {
+	logger = log.NewNopLogger()
+
+	defer func(c *otlp.Client) {
+		otlpClient = c
+	}(otlpClient)
+
+	receiver := otlptest.NewReceiver()
+	defer receiver.Close()
+
+	receiver.Fail("receiver unavailable")
+
+	var err error
+
+	otlpClient, err = otlp.NewClient(receiver.URL, otlp.ProtocolGRPC, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The counter is kept between tests, so only the failures from this push are counted.
+	before := &dto.Metric{}
+	otlpExportFailures.WithLabelValues("ns-otlp-failure").Write(before)
+
+	target := newPolledTarget(config.Target{URL: "https://ns-otlp-failure"}, "", nil)
+	target.pushOTLP(otlpTestFamilies(t))
+
+	failures := &dto.Metric{}
+	otlpExportFailures.WithLabelValues("ns-otlp-failure").Write(failures)
+
+	if got := failures.GetCounter().GetValue() - before.GetCounter().GetValue(); got != 1 {
+		t.Errorf("otlp export failures = %v, want 1", got)
+	}
+}
@@ -0,0 +1,425 @@
+package main
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/rokett/citrix-netscaler-exporter/config"
+	"github.com/rokett/citrix-netscaler-exporter/netscaler"
+)
+
+var lastSuccessfulScrape = prometheus.NewDesc(
+	"citrix_netscaler_last_successful_scrape_timestamp_seconds",
+	"Time at which the last successful background poll of the NetScaler completed, as seconds since the Unix epoch; 0 if no poll has succeeded",
+	[]string{
+		"ns_instance",
+	},
+	nil,
+)
+
+// startPolling polls a discovered target in the background until its stop channel is closed; tests replace it.
+var startPolling = func(t *polledTarget) {
+	go t.run()
+}
+
+// poller polls the targets listed in the configuration file in the background, so that scrapes can be served without querying the NetScaler.
+// The HA and cluster peers of those targets are discovered and polled too, as are the instances managed by each NetScaler ADM in the configuration file.
+type poller struct {
+	mu sync.Mutex
+	// targets is keyed by the target URL.
+	targets map[string]*polledTarget
+	adms    []config.ADM
+}
+
+// polledTarget holds the metrics from the latest poll of a target.
+type polledTarget struct {
+	config.Target
+	nsInstance string
+	// discoveredBy is the URL of the configured target or NetScaler ADM which the target was discovered from, or empty if the target is configured.
+	discoveredBy string
+	// stop is closed to stop polling a discovered target which is no longer a peer.
+	stop chan struct{}
+
+	mu          sync.Mutex
+	families    []*dto.MetricFamily
+	polledAt    time.Time
+	lastSuccess time.Time
+	// peerLabels identify the HA pair or cluster which the target belongs to, or the NetScaler ADM which it was discovered from.
+	peerLabels map[string]string
+}
+
+func newPoller(targets []config.Target, adms []config.ADM) *poller {
+	p := &poller{
+		targets: make(map[string]*polledTarget),
+		adms:    adms,
+	}
+
+	for _, t := range targets {
+		p.targets[t.URL] = newPolledTarget(t, "", nil)
+	}
+
+	return p
+}
+
+func newPolledTarget(t config.Target, discoveredBy string, peerLabels map[string]string) *polledTarget {
+	return &polledTarget{
+		Target:       t,
+		nsInstance:   targetInstance(t.URL),
+		discoveredBy: discoveredBy,
+		stop:         make(chan struct{}),
+		peerLabels:   peerLabels,
+	}
+}
+
+// start polls every target on its own interval, until the exporter exits.
+// Discovery does not run when replaying, as the recordings of any peers would not be found at the addresses the NetScaler reports,
+// and NetScaler ADM cannot be recorded.
+func (p *poller) start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, t := range p.targets {
+		go t.run()
+
+		// An SDX Management Service has no HA or cluster peers to discover.
+		if *discoveryInterval > 0 && *replayDir == "" && t.Module != config.ModuleSDX {
+			go p.discover(t)
+		}
+	}
+
+	if *replayDir != "" {
+		return
+	}
+
+	for _, adm := range p.adms {
+		go p.discoverADM(adm)
+	}
+}
+
+// target returns the polled target for the URL, or nil if it is not polled.
+func (p *poller) target(url string) *polledTarget {
+	if p == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.targets[url]
+}
+
+// list returns every polled target, sorted by URL.
+func (p *poller) list() []*polledTarget {
+	if p == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	targets := make([]*polledTarget, 0, len(p.targets))
+	for _, t := range p.targets {
+		targets = append(targets, t)
+	}
+
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].URL < targets[j].URL
+	})
+
+	return targets
+}
+
+// discover checks the configured target for HA and cluster peers every discovery interval, polling any it finds.
+func (p *poller) discover(t *polledTarget) {
+	p.updatePeers(t)
+
+	ticker := time.NewTicker(*discoveryInterval)
+	for range ticker.C {
+		p.updatePeers(t)
+	}
+}
+
+// updatePeers polls the peers of the configured target which are not already polled, and stops polling those which are no longer peers.
+// Peers which are also listed in the configuration file keep their own settings; other peers get the settings of the configured target.
+func (p *poller) updatePeers(t *polledTarget) {
+	peers, peerLabels, nsip, err := discoverPeers(t.Target)
+	if err != nil {
+		if netscaler.IsPermissionDenied(err) {
+			level.Warn(logger).Log("msg", "not permitted to discover HA and cluster peers; check the Command Policy", "target", t.URL, "err", err)
+			return
+		}
+
+		level.Error(logger).Log("msg", "error discovering HA and cluster peers", "target", t.URL, "err", err)
+		return
+	}
+
+	t.setPeerLabels(peerLabels)
+	setReportedNSIP(t.URL, nsip)
+
+	p.setDiscovered(t.Target, peers, peerLabels)
+}
+
+// discoverADM lists the instances managed by the NetScaler ADM every discovery interval, polling them through ADM.
+// If discovery is disabled, the instances are only listed once.
+func (p *poller) discoverADM(adm config.ADM) {
+	p.updateADMInstances(adm)
+
+	if *discoveryInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(*discoveryInterval)
+	for range ticker.C {
+		p.updateADMInstances(adm)
+	}
+}
+
+// updateADMInstances polls the instances managed by the NetScaler ADM which are not already polled, and stops polling those which it no longer manages.
+// Each instance gets the settings of the ADM from the configuration file.
+func (p *poller) updateADMInstances(adm config.ADM) {
+	instances, err := listADMInstances(adm)
+	if err != nil {
+		level.Error(logger).Log("msg", "error listing NetScaler ADM instances", "adm", adm.URL, "err", err)
+		return
+	}
+
+	p.setDiscovered(adm.Target, instances, admLabels(adm))
+}
+
+// setDiscovered polls the targets discovered from the source which are not already polled, and stops polling those which are no longer found.
+// Targets which are also listed in the configuration file keep their own settings; other targets get the settings of the source.
+func (p *poller) setDiscovered(source config.Target, urls []string, labels map[string]string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	current := make(map[string]bool)
+
+	for _, url := range urls {
+		current[url] = true
+
+		if existing, ok := p.targets[url]; ok {
+			existing.setPeerLabels(labels)
+			continue
+		}
+
+		target := source
+		target.URL = url
+
+		level.Info(logger).Log("msg", "discovered target", "source", source.URL, "target", url)
+
+		pt := newPolledTarget(target, source.URL, labels)
+		p.targets[url] = pt
+		startPolling(pt)
+	}
+
+	for url, pt := range p.targets {
+		if pt.discoveredBy == source.URL && !current[url] {
+			level.Info(logger).Log("msg", "target no longer found", "source", source.URL, "target", url)
+
+			close(pt.stop)
+			delete(p.targets, url)
+		}
+	}
+}
+
+// Gather implements prometheus.Gatherer, returning the latest metrics for every target.
+func (p *poller) Gather() ([]*dto.MetricFamily, error) {
+	var gatherers prometheus.Gatherers
+
+	for _, t := range p.list() {
+		gatherers = append(gatherers, prometheus.GathererFunc(t.gather))
+	}
+
+	return gatherers.Gather()
+}
+
+// Describe implements prometheus.Collector.
+func (p *poller) Describe(ch chan<- *prometheus.Desc) {
+	ch <- lastSuccessfulScrape
+}
+
+// Collect implements prometheus.Collector, exporting the time of the last successful poll of every target.
+func (p *poller) Collect(ch chan<- prometheus.Metric) {
+	for _, t := range p.list() {
+		t.Collect(ch)
+	}
+}
+
+func (t *polledTarget) run() {
+	t.poll()
+
+	ticker := time.NewTicker(t.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			t.poll()
+		case <-t.stop:
+			return
+		}
+	}
+}
+
+func (t *polledTarget) setPeerLabels(labels map[string]string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.peerLabels = labels
+}
+
+// sdLabels returns the labels added to the target by service discovery; those set in the configuration file, plus those identifying its HA pair or cluster.
+func (t *polledTarget) sdLabels() map[string]string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	labels := make(map[string]string)
+
+	for name, value := range t.Labels {
+		labels[name] = value
+	}
+
+	for name, value := range t.peerLabels {
+		labels[name] = value
+	}
+
+	return labels
+}
+
+// poll collects every metric from the target, and keeps them to be served until the next poll.  If OTLP export or remote_write is enabled, they are pushed too.
+// A poll which fails to gather keeps the previous metrics, which are served until they reach the target's staleness limit.
+func (t *polledTarget) poll() {
+	if *debugFlg {
+		level.Debug(logger).Log("msg", "polling target", "target", t.URL)
+	}
+
+	exporter, err := newExporter(t.URL, t.Module, t.IgnoreCert, t.Partitions)
+	if err != nil {
+		level.Error(logger).Log("msg", "error creating exporter", "target", t.URL, "err", err)
+		return
+	}
+
+	registry := prometheus.NewRegistry()
+
+	err = registry.Register(exporter)
+	if err != nil {
+		level.Error(logger).Log("msg", "error registering exporter", "target", t.URL, "err", err)
+		return
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		level.Error(logger).Log("msg", "error polling target", "target", t.URL, "err", err)
+		return
+	}
+
+	now := time.Now()
+
+	t.mu.Lock()
+	t.families = families
+	t.polledAt = now
+
+	if targetUp(families) {
+		t.lastSuccess = now
+	}
+	t.mu.Unlock()
+
+	if otlpClient != nil {
+		t.pushOTLP(families)
+	}
+
+	if remoteWriteQueue != nil {
+		t.pushRemoteWrite(families, now)
+	}
+}
+
+// gather returns the metrics from the latest poll.  Once they are older than the staleness limit nothing is returned,
+// so that Prometheus marks the series stale rather than recording old values as current.
+func (t *polledTarget) gather() ([]*dto.MetricFamily, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.polledAt.IsZero() || time.Since(t.polledAt) > t.MaxStaleness {
+		return nil, nil
+	}
+
+	return t.families, nil
+}
+
+// checkParams checks that the module and partitions asked for by a scrape match those the target is polled with, as the scrape is served from the latest poll.
+// Parameters which are not given are taken to match.
+func (t *polledTarget) checkParams(query url.Values) error {
+	module := t.Module
+	if module == "" {
+		module = config.ModuleADC
+	}
+
+	if m := query.Get("module"); m != "" && m != module {
+		return errors.Errorf("%s is polled with module %s, so 'module' must be %s or not given", t.URL, module, module)
+	}
+
+	if p, ok := query["partition"]; ok {
+		partitions := splitPartitions(strings.Join(p, ","))
+		if !samePartitions(partitions, t.Partitions) {
+			return errors.Errorf("%s is polled with partitions %q, so 'partition' must list the same partitions or not be given", t.URL, strings.Join(t.Partitions, ","))
+		}
+	}
+
+	return nil
+}
+
+// samePartitions reports whether two lists name the same partitions, in any order.
+func samePartitions(a []string, b []string) bool {
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+
+	sort.Strings(a)
+	sort.Strings(b)
+
+	return strings.Join(a, ",") == strings.Join(b, ",")
+}
+
+// Describe implements prometheus.Collector.
+func (t *polledTarget) Describe(ch chan<- *prometheus.Desc) {
+	ch <- lastSuccessfulScrape
+}
+
+// Collect implements prometheus.Collector, exporting the time of the last successful poll of the target.
+func (t *polledTarget) Collect(ch chan<- prometheus.Metric) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	val := 0.0
+	if !t.lastSuccess.IsZero() {
+		val = float64(t.lastSuccess.UnixNano()) / 1e9
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		lastSuccessfulScrape, prometheus.GaugeValue, val, t.nsInstance,
+	)
+}
+
+// targetUp reports whether the exporter logged in to the NetScaler, according to the citrix_netscaler_up metric.
+func targetUp(families []*dto.MetricFamily) bool {
+	for _, f := range families {
+		if f.GetName() != "citrix_netscaler_up" {
+			continue
+		}
+
+		for _, m := range f.GetMetric() {
+			if m.GetGauge().GetValue() == 1 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
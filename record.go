@@ -0,0 +1,105 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/go-kit/kit/log"
+
+	"github.com/rokett/citrix-netscaler-exporter/collector"
+	"github.com/rokett/citrix-netscaler-exporter/config"
+	"github.com/rokett/citrix-netscaler-exporter/netscaler/nitrotest"
+)
+
+// record runs every collector once against the target, saving each Nitro response into a directory which can be replayed later.
+// It returns the exit code for the process.
+func record(args []string) int {
+	fs := flag.NewFlagSet("record", flag.ExitOnError)
+
+	target := fs.String("target", "", "URL of the NetScaler to record; for example https://netscaler.domain.tld")
+	out := fs.String("out", "", "Directory to write the recorded Nitro responses to")
+	username := fs.String("username", "", "Username with which to connect to the NetScaler API")
+	password := fs.String("password", "", "Password with which to connect to the NetScaler API")
+	ignoreCert := fs.Bool("ignore-cert", false, "Skip the certificate check?  This should be used sparingly, and only when you fully trust the endpoint")
+	configFile := fs.String("config", "", "Path to a YAML configuration file; the resources in any mappings are recorded too")
+	partitions := fs.String("partition", "", "Comma separated list of admin partitions to record, or all to record every partition")
+	scrub := fs.Bool("scrub", true, "Replace IP addresses, hostnames and names with pseudonyms?")
+
+	fs.Parse(args)
+
+	if *target == "" || *out == "" || *username == "" || *password == "" {
+		fmt.Fprintln(os.Stderr, "Usage: record -target <url> -out <dir> -username <username> -password <password>")
+		fs.PrintDefaults()
+		return 1
+	}
+
+	logger := log.NewLogfmtLogger(os.Stderr)
+	logger = log.With(logger, "ts", log.DefaultTimestampUTC, "caller", log.DefaultCaller, "app", app, "mode", "record")
+
+	cfg := new(config.Config)
+	if *configFile != "" {
+		var err error
+
+		cfg, err = config.Load(*configFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	var scrubber *nitrotest.Scrubber
+	if *scrub {
+		scrubber = nitrotest.NewScrubber()
+	}
+
+	recorder, err := nitrotest.NewRecorder(*target, *out, *ignoreCert, scrubber)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer recorder.Close()
+
+	// Everything optional is turned on, so that the recording covers every endpoint.
+	// Service group members are fetched one service group at a time and then in bulk, so that the recording can be replayed with or without servicegroup_bulk.
+	for _, bulk := range []bool{false, true} {
+		exporter, err := collector.NewExporter(recorder.URL, *username, *password, false, logger, "record", collector.Options{
+			VPNSessions:          true,
+			VPNSessionsMaxSeries: *vpnSessionsMaxSeries,
+			ServiceGroupBulk:     bulk,
+			TrafficDomains:       true,
+			Partitions:           splitPartitions(*partitions),
+			Mappings:             cfg.Mappings,
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(exporter)
+
+		_, err = registry.Gather()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	if recorder.Err() != nil {
+		fmt.Fprintln(os.Stderr, recorder.Err())
+		return 1
+	}
+
+	recorded := recorder.Recorded()
+	if len(recorded) == 0 {
+		fmt.Fprintln(os.Stderr, "no Nitro responses were recorded; check the errors logged above")
+		return 1
+	}
+
+	fmt.Printf("Recorded %d Nitro responses to %s\n", len(recorded), *out)
+
+	return 0
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/rokett/citrix-netscaler-exporter/remotewrite"
+)
+
+// remoteWriteJob is the job label of pushed series, as Prometheus would add when scraping.
+const remoteWriteJob = "netscaler"
+
+// remoteWriteQueue sends the metrics from every poll to the remote_write endpoint; nil if remote_write is not enabled.
+var remoteWriteQueue *remotewrite.Queue
+
+var (
+	remoteWritePendingDesc = prometheus.NewDesc(
+		"citrix_netscaler_remote_write_pending_requests",
+		"Number of remote_write requests in the WAL waiting to be sent",
+		nil,
+		nil,
+	)
+
+	remoteWritePendingBytesDesc = prometheus.NewDesc(
+		"citrix_netscaler_remote_write_pending_bytes",
+		"Size of the remote_write requests in the WAL waiting to be sent",
+		nil,
+		nil,
+	)
+
+	remoteWriteSentDesc = prometheus.NewDesc(
+		"citrix_netscaler_remote_write_sent_requests_total",
+		"Number of remote_write requests accepted by the endpoint",
+		nil,
+		nil,
+	)
+
+	remoteWriteFailedDesc = prometheus.NewDesc(
+		"citrix_netscaler_remote_write_failed_sends_total",
+		"Number of attempts to send a remote_write request which failed, including those which were retried",
+		nil,
+		nil,
+	)
+
+	remoteWriteDroppedDesc = prometheus.NewDesc(
+		"citrix_netscaler_remote_write_dropped_requests_total",
+		"Number of remote_write requests dropped unsent, because the WAL was full, the endpoint rejected them or they could not be read from the WAL",
+		nil,
+		nil,
+	)
+)
+
+// remoteWriteCollector exposes the state of the remote_write queue.
+type remoteWriteCollector struct {
+	queue *remotewrite.Queue
+}
+
+// Describe implements prometheus.Collector.
+func (c remoteWriteCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- remoteWritePendingDesc
+	ch <- remoteWritePendingBytesDesc
+	ch <- remoteWriteSentDesc
+	ch <- remoteWriteFailedDesc
+	ch <- remoteWriteDroppedDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c remoteWriteCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.queue.Stats()
+
+	ch <- prometheus.MustNewConstMetric(remoteWritePendingDesc, prometheus.GaugeValue, float64(stats.Pending))
+	ch <- prometheus.MustNewConstMetric(remoteWritePendingBytesDesc, prometheus.GaugeValue, float64(stats.PendingBytes))
+	ch <- prometheus.MustNewConstMetric(remoteWriteSentDesc, prometheus.CounterValue, float64(stats.Sent))
+	ch <- prometheus.MustNewConstMetric(remoteWriteFailedDesc, prometheus.CounterValue, float64(stats.Failed))
+	ch <- prometheus.MustNewConstMetric(remoteWriteDroppedDesc, prometheus.CounterValue, float64(stats.Dropped))
+}
+
+// newRemoteWriteQueue creates the queue for the remote_write endpoint given by the remote_write flags, resuming any requests left in its WAL.
+func newRemoteWriteQueue() (*remotewrite.Queue, error) {
+	if *remoteWriteWALMaxMB <= 0 {
+		return nil, errors.New("remote_write_wal_max_mb must be greater than 0")
+	}
+
+	client, err := remotewrite.NewClient(*remoteWriteURL, *remoteWriteUsername, *remoteWritePassword, *remoteWriteToken, 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	return remotewrite.NewQueue(*remoteWriteWALDir, int64(*remoteWriteWALMaxMB)*1024*1024, *remoteWriteBackoff, client, logger)
+}
+
+// pushRemoteWrite queues the metrics from a poll of the target to be sent to the remote_write endpoint, timestamped with the time of the poll.
+// As no Prometheus scrapes the target, the job and instance labels it would add are added here, unless set in the configuration file, along with the target's service discovery labels, such as site.
+func (t *polledTarget) pushRemoteWrite(families []*dto.MetricFamily, polledAt time.Time) {
+	labels := t.sdLabels()
+	if labels["job"] == "" {
+		labels["job"] = remoteWriteJob
+	}
+	if labels["instance"] == "" {
+		labels["instance"] = t.URL
+	}
+
+	err := remoteWriteQueue.Append(remotewrite.Encode(families, labels, polledAt))
+	if err != nil {
+		level.Error(logger).Log("msg", "error queueing metrics for remote_write", "target", t.URL, "err", err)
+	}
+}
@@ -0,0 +1,108 @@
+package remotewrite
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Client sends WriteRequest messages to a Prometheus remote_write endpoint.
+type Client struct {
+	url         string
+	username    string
+	password    string
+	bearerToken string
+	client      *http.Client
+}
+
+// SendError is returned when the endpoint rejects a request.
+type SendError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *SendError) Error() string {
+	return "remote_write endpoint returned " + http.StatusText(e.StatusCode) + ": " + e.Message
+}
+
+// NewClient creates a client for the remote_write endpoint at url.
+// Requests authenticate with basic auth if username is given, or with the bearer token if it is given; not both.
+func NewClient(endpoint string, username string, password string, bearerToken string, timeout time.Duration) (*Client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing remote_write URL")
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.Errorf("remote_write URL %q must start with http:// or https://", endpoint)
+	}
+
+	if username != "" && bearerToken != "" {
+		return nil, errors.New("remote_write can use basic auth or a bearer token, not both")
+	}
+
+	return &Client{
+		url:         endpoint,
+		username:    username,
+		password:    password,
+		bearerToken: bearerToken,
+		client: &http.Client{
+			Timeout: timeout,
+		},
+	}, nil
+}
+
+// Send posts a compressed WriteRequest, as returned by Encode, to the endpoint.
+func (c *Client) Send(body []byte) error {
+	req, err := http.NewRequest("POST", c.url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "error creating HTTP request")
+	}
+
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	if c.bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.bearerToken)
+	}
+
+	resp, err := c.client.Do(req)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		return errors.Wrap(err, "error sending request")
+	}
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	if resp.StatusCode/100 != 2 {
+		return &SendError{
+			StatusCode: resp.StatusCode,
+			Message:    strings.TrimSpace(string(respBody)),
+		}
+	}
+
+	return nil
+}
+
+// IsRetryable reports whether a failed send may succeed if it is retried.
+// As in Prometheus, requests the endpoint rejects as invalid are not retried, but server errors, rate limiting and failures to connect are.
+func IsRetryable(err error) bool {
+	sendErr, ok := errors.Cause(err).(*SendError)
+	if !ok {
+		return true
+	}
+
+	return sendErr.StatusCode/100 == 5 || sendErr.StatusCode == http.StatusTooManyRequests
+}
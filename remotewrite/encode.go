@@ -0,0 +1,118 @@
+// Package remotewrite sends samples to a Prometheus remote_write endpoint, buffering them on disk while the endpoint cannot be reached.
+// The remote_write protobuf messages are encoded by hand, as only a small part of the protocol is needed.
+package remotewrite
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Protobuf wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+)
+
+type label struct {
+	name  string
+	value string
+}
+
+// Encode returns the snappy compressed WriteRequest message for the families, with a sample for each series at ts.
+// The labels in extra are added to every series which does not already have them.  Labels with empty values are left out, as Prometheus does.
+// Gauges, counters and untyped metrics are encoded; other metric types are not produced by the exporter, and are left out.
+func Encode(families []*dto.MetricFamily, extra map[string]string, ts time.Time) []byte {
+	req := proto.NewBuffer(nil)
+
+	for _, f := range families {
+		for _, m := range f.GetMetric() {
+			var value float64
+
+			switch f.GetType() {
+			case dto.MetricType_GAUGE:
+				value = m.GetGauge().GetValue()
+			case dto.MetricType_COUNTER:
+				value = m.GetCounter().GetValue()
+			case dto.MetricType_UNTYPED:
+				value = m.GetUntyped().GetValue()
+			default:
+				continue
+			}
+
+			timestamp := ts.UnixNano() / int64(time.Millisecond)
+			if m.TimestampMs != nil {
+				timestamp = m.GetTimestampMs()
+			}
+
+			encodeBytes(req, 1, encodeTimeSeries(seriesLabels(f.GetName(), m.GetLabel(), extra), value, timestamp))
+		}
+	}
+
+	return snappy.Encode(nil, req.Bytes())
+}
+
+// seriesLabels returns the labels of a series, sorted by name as remote_write requires.
+func seriesLabels(name string, pairs []*dto.LabelPair, extra map[string]string) []label {
+	labels := []label{{"__name__", name}}
+	seen := map[string]bool{"__name__": true}
+
+	for _, l := range pairs {
+		if l.GetValue() != "" {
+			labels = append(labels, label{l.GetName(), l.GetValue()})
+		}
+		seen[l.GetName()] = true
+	}
+
+	for n, v := range extra {
+		if !seen[n] && v != "" {
+			labels = append(labels, label{n, v})
+		}
+	}
+
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].name < labels[j].name
+	})
+
+	return labels
+}
+
+// encodeTimeSeries returns the TimeSeries message for a series with a single sample.
+func encodeTimeSeries(labels []label, value float64, timestamp int64) []byte {
+	ts := proto.NewBuffer(nil)
+
+	for _, l := range labels {
+		lb := proto.NewBuffer(nil)
+		encodeString(lb, 1, l.name)
+		encodeString(lb, 2, l.value)
+		encodeBytes(ts, 1, lb.Bytes())
+	}
+
+	sample := proto.NewBuffer(nil)
+	encodeTag(sample, 1, wireFixed64)
+	sample.EncodeFixed64(math.Float64bits(value))
+	encodeTag(sample, 2, wireVarint)
+	sample.EncodeVarint(uint64(timestamp))
+	encodeBytes(ts, 2, sample.Bytes())
+
+	return ts.Bytes()
+}
+
+func encodeTag(b *proto.Buffer, field int, wireType int) {
+	b.EncodeVarint(uint64(field<<3 | wireType))
+}
+
+func encodeBytes(b *proto.Buffer, field int, v []byte) {
+	encodeTag(b, field, wireBytes)
+	b.EncodeRawBytes(v)
+}
+
+func encodeString(b *proto.Buffer, field int, v string) {
+	encodeTag(b, field, wireBytes)
+	b.EncodeStringBytes(v)
+}
@@ -0,0 +1,337 @@
+package remotewrite
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+)
+
+const (
+	segmentExt = ".wal"
+	tmpExt     = ".tmp"
+
+	minBackoff = time.Second
+)
+
+// Queue sends requests to a remote_write endpoint in the order they were added.  Each request is written to a file in the
+// write-ahead log directory before it is sent, and removed once the endpoint accepts it, so that samples survive an outage
+// of the endpoint or a restart of the exporter.  Requests are retried with exponential backoff until they are accepted.
+// If the log grows beyond its maximum size the oldest requests are dropped, so that a long outage cannot fill the disk.
+type Queue struct {
+	dir        string
+	maxSize    int64
+	maxBackoff time.Duration
+	client     *Client
+	logger     log.Logger
+
+	mu       sync.Mutex
+	segments []segment
+	size     int64
+	next     uint64
+	sent     uint64
+	failed   uint64
+	dropped  uint64
+
+	notify chan struct{}
+	stop   chan struct{}
+}
+
+type segment struct {
+	name string
+	size int64
+}
+
+// Stats describes the state of a queue.
+type Stats struct {
+	// Pending is the number of requests waiting to be sent, and PendingBytes their size.
+	Pending      int
+	PendingBytes int64
+	// Sent is the number of requests accepted by the endpoint.
+	Sent uint64
+	// Failed is the number of attempts to send a request which failed, including those which were retried.
+	Failed uint64
+	// Dropped is the number of requests removed from the log unsent; because the log was full, because the endpoint rejected them,
+	// or because they could not be read back from the log.
+	Dropped uint64
+}
+
+// NewQueue opens the write-ahead log in dir, creating it if needed, and queues any requests left in it to be sent again.
+// Call Start to begin sending.
+func NewQueue(dir string, maxSize int64, maxBackoff time.Duration, client *Client, logger log.Logger) (*Queue, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating remote_write WAL directory")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading remote_write WAL directory")
+	}
+
+	if maxBackoff < minBackoff {
+		maxBackoff = minBackoff
+	}
+
+	q := &Queue{
+		dir:        dir,
+		maxSize:    maxSize,
+		maxBackoff: maxBackoff,
+		client:     client,
+		logger:     logger,
+		notify:     make(chan struct{}, 1),
+		stop:       make(chan struct{}),
+	}
+
+	for _, e := range entries {
+		name := e.Name()
+
+		// A temporary file is a request which was being written when the exporter stopped, so is incomplete.
+		if strings.HasSuffix(name, tmpExt) {
+			os.Remove(filepath.Join(dir, name))
+			continue
+		}
+
+		if !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		info, err := e.Info()
+		if err != nil {
+			return nil, errors.Wrap(err, "error reading remote_write WAL directory")
+		}
+
+		q.segments = append(q.segments, segment{name: name, size: info.Size()})
+		q.size += info.Size()
+
+		if seq >= q.next {
+			q.next = seq + 1
+		}
+	}
+
+	sort.Slice(q.segments, func(i, j int) bool {
+		return q.segments[i].name < q.segments[j].name
+	})
+
+	q.mu.Lock()
+	q.truncate()
+	q.mu.Unlock()
+
+	if len(q.segments) > 0 {
+		level.Info(logger).Log("msg", "resuming remote_write from WAL", "requests", len(q.segments), "bytes", q.size)
+	}
+
+	return q, nil
+}
+
+// Start begins sending queued requests in the background.
+func (q *Queue) Start() {
+	go q.run()
+}
+
+// Stop stops sending requests.  Those not yet sent stay in the log, to be sent when it is next opened.
+func (q *Queue) Stop() {
+	close(q.stop)
+}
+
+// Append writes a request, as returned by Encode, to the log and queues it to be sent.
+func (q *Queue) Append(body []byte) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	name := fmt.Sprintf("%020d%s", q.next, segmentExt)
+	q.next++
+
+	// The request is written to a temporary file which is renamed once complete, so a crash cannot leave a partial request to be sent.
+	tmp := filepath.Join(q.dir, name+tmpExt)
+
+	err := writeFileSync(tmp, body)
+	if err != nil {
+		os.Remove(tmp)
+		return errors.Wrap(err, "error writing to remote_write WAL")
+	}
+
+	err = os.Rename(tmp, filepath.Join(q.dir, name))
+	if err != nil {
+		os.Remove(tmp)
+		return errors.Wrap(err, "error writing to remote_write WAL")
+	}
+
+	q.segments = append(q.segments, segment{name: name, size: int64(len(body))})
+	q.size += int64(len(body))
+
+	q.truncate()
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Stats returns the current state of the queue.
+func (q *Queue) Stats() Stats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return Stats{
+		Pending:      len(q.segments),
+		PendingBytes: q.size,
+		Sent:         q.sent,
+		Failed:       q.failed,
+		Dropped:      q.dropped,
+	}
+}
+
+// truncate drops the oldest requests until the log is within its maximum size.  The newest request is always kept.
+// The caller must hold the lock.
+func (q *Queue) truncate() {
+	dropped := 0
+
+	for q.maxSize > 0 && q.size > q.maxSize && len(q.segments) > 1 {
+		q.remove(q.segments[0].name)
+		q.dropped++
+		dropped++
+	}
+
+	if dropped > 0 {
+		level.Warn(q.logger).Log("msg", "remote_write WAL is full; dropped the oldest requests", "dropped", dropped)
+	}
+}
+
+// remove deletes a request from the log, if it is still there, and reports whether it was.  The caller must hold the lock.
+func (q *Queue) remove(name string) bool {
+	for i, s := range q.segments {
+		if s.name != name {
+			continue
+		}
+
+		os.Remove(filepath.Join(q.dir, name))
+
+		q.size -= s.size
+		q.segments = append(q.segments[:i], q.segments[i+1:]...)
+
+		return true
+	}
+
+	return false
+}
+
+// oldest returns the name of the oldest request in the log, or an empty string if there are none.
+func (q *Queue) oldest() string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.segments) == 0 {
+		return ""
+	}
+
+	return q.segments[0].name
+}
+
+func (q *Queue) run() {
+	backoff := minBackoff
+
+	for {
+		name := q.oldest()
+		if name == "" {
+			select {
+			case <-q.notify:
+				continue
+			case <-q.stop:
+				return
+			}
+		}
+
+		// The request may have been dropped since it was listed, in which case the next is tried.  Otherwise it cannot be
+		// read, and retrying would never succeed, so it is dropped.
+		body, err := os.ReadFile(filepath.Join(q.dir, name))
+		if err != nil {
+			q.mu.Lock()
+			unreadable := q.remove(name)
+			if unreadable {
+				q.dropped++
+			}
+			q.mu.Unlock()
+
+			if unreadable {
+				level.Error(q.logger).Log("msg", "error reading request from remote_write WAL; dropping it", "segment", name, "err", err)
+			}
+
+			continue
+		}
+
+		err = q.client.Send(body)
+
+		q.mu.Lock()
+		switch {
+		case err == nil:
+			q.sent++
+			q.remove(name)
+		case !IsRetryable(err):
+			q.failed++
+			q.dropped++
+			q.remove(name)
+		default:
+			q.failed++
+		}
+		q.mu.Unlock()
+
+		if err == nil {
+			backoff = minBackoff
+			continue
+		}
+
+		if !IsRetryable(err) {
+			level.Error(q.logger).Log("msg", "remote_write endpoint rejected request; dropping it", "err", err)
+			continue
+		}
+
+		level.Error(q.logger).Log("msg", "error sending to remote_write endpoint; retrying", "retry_in", backoff, "err", err)
+
+		select {
+		case <-time.After(backoff):
+		case <-q.stop:
+			return
+		}
+
+		backoff *= 2
+		if backoff > q.maxBackoff {
+			backoff = q.maxBackoff
+		}
+	}
+}
+
+// writeFileSync writes a file and flushes it to disk, so that it survives a crash once written.
+func writeFileSync(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+
+	closeErr := f.Close()
+	if err == nil {
+		err = closeErr
+	}
+
+	return err
+}
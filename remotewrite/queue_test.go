@@ -0,0 +1,253 @@
+package remotewrite
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+)
+
+// receiver is a stand-in remote_write endpoint which records the body of each request and answers with the status
+// returned by respond.
+type receiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []string
+	respond  func(body string, attempt int) int
+}
+
+func newReceiver(t *testing.T, respond func(body string, attempt int) int) *receiver {
+	r := &receiver{respond: respond}
+
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		b, _ := io.ReadAll(req.Body)
+		body := string(b)
+
+		r.mu.Lock()
+		r.requests = append(r.requests, body)
+		attempt := 0
+		for _, seen := range r.requests {
+			if seen == body {
+				attempt++
+			}
+		}
+		r.mu.Unlock()
+
+		w.WriteHeader(r.respond(body, attempt))
+	}))
+	t.Cleanup(r.Close)
+
+	return r
+}
+
+// received returns the body of each request received, in order.
+func (r *receiver) received() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.requests...)
+}
+
+func accept(string, int) int {
+	return http.StatusNoContent
+}
+
+func newTestQueue(t *testing.T, dir string, maxSize int64, r *receiver) *Queue {
+	client, err := NewClient(r.URL, "", "", "", 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q, err := NewQueue(dir, maxSize, time.Second, client, log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return q
+}
+
+// waitForSent waits until the queue has sent n requests and has none pending.
+func waitForSent(t *testing.T, q *Queue, n uint64) Stats {
+	deadline := time.Now().Add(10 * time.Second)
+
+	for {
+		stats := q.Stats()
+		if stats.Sent >= n && stats.Pending == 0 {
+			return stats
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %d requests to be sent; %+v", n, stats)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// segments returns the names of the files in the WAL directory.
+func segments(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+
+	return names
+}
+
+func TestQueueResume(t *testing.T) {
+	dir := t.TempDir()
+	r := newReceiver(t, accept)
+
+	// The first queue is never started, as if the exporter stopped before the endpoint could be reached.
+	q := newTestQueue(t, dir, 0, r)
+	for _, body := range []string{"one", "two", "three"} {
+		if err := q.Append([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A request which was being written when the exporter stopped.
+	if err := os.WriteFile(filepath.Join(dir, "00000000000000000003.wal.tmp"), []byte("partial"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	q = newTestQueue(t, dir, 0, r)
+
+	stats := q.Stats()
+	if stats.Pending != 3 || stats.PendingBytes != int64(len("onetwothree")) {
+		t.Errorf("resumed with %d requests of %d bytes pending, want 3 of %d", stats.Pending, stats.PendingBytes, len("onetwothree"))
+	}
+
+	if err := q.Append([]byte("four")); err != nil {
+		t.Fatal(err)
+	}
+
+	q.Start()
+	defer q.Stop()
+
+	waitForSent(t, q, 4)
+
+	if got, want := r.received(), []string{"one", "two", "three", "four"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("received %q, want %q", got, want)
+	}
+
+	if names := segments(t, dir); len(names) != 0 {
+		t.Errorf("WAL holds %q after sending, want nothing", names)
+	}
+}
+
+func TestQueueTruncate(t *testing.T) {
+	dir := t.TempDir()
+	r := newReceiver(t, accept)
+
+	q := newTestQueue(t, dir, 10, r)
+	for _, body := range []string{"aaaa", "bbbb", "cccc", "dddd"} {
+		if err := q.Append([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats := q.Stats()
+	if stats.Pending != 2 || stats.PendingBytes != 8 || stats.Dropped != 2 {
+		t.Errorf("got %+v, want 2 requests of 8 bytes pending and 2 dropped", stats)
+	}
+
+	if got, want := segments(t, dir), []string{"00000000000000000002.wal", "00000000000000000003.wal"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("WAL holds %q, want %q", got, want)
+	}
+
+	// The newest request is kept even if it alone is larger than the maximum size.
+	if err := q.Append([]byte("a request larger than the WAL")); err != nil {
+		t.Fatal(err)
+	}
+
+	if stats := q.Stats(); stats.Pending != 1 || stats.Dropped != 4 {
+		t.Errorf("got %+v, want 1 request pending and 4 dropped", stats)
+	}
+
+	q.Start()
+	defer q.Stop()
+
+	waitForSent(t, q, 1)
+
+	if got, want := r.received(), []string{"a request larger than the WAL"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("received %q, want %q", got, want)
+	}
+}
+
+func TestQueueRetry(t *testing.T) {
+	dir := t.TempDir()
+
+	// The endpoint rejects "invalid" outright, fails "retried" once with a server error, and accepts everything else.
+	r := newReceiver(t, func(body string, attempt int) int {
+		switch {
+		case body == "invalid":
+			return http.StatusBadRequest
+		case body == "retried" && attempt == 1:
+			return http.StatusServiceUnavailable
+		default:
+			return http.StatusNoContent
+		}
+	})
+
+	q := newTestQueue(t, dir, 0, r)
+	for _, body := range []string{"invalid", "retried", "last"} {
+		if err := q.Append([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	q.Start()
+	defer q.Stop()
+
+	stats := waitForSent(t, q, 2)
+
+	if stats.Sent != 2 || stats.Failed != 2 || stats.Dropped != 1 {
+		t.Errorf("got %+v, want 2 sent, 2 failed and 1 dropped", stats)
+	}
+
+	if got, want := r.received(), []string{"invalid", "retried", "retried", "last"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("received %q, want %q", got, want)
+	}
+}
+
+func TestQueueUnreadableSegment(t *testing.T) {
+	dir := t.TempDir()
+	r := newReceiver(t, accept)
+
+	// A segment which cannot be read is dropped rather than retried forever.
+	if err := os.Mkdir(filepath.Join(dir, "00000000000000000000.wal"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	q := newTestQueue(t, dir, 0, r)
+	if err := q.Append([]byte("readable")); err != nil {
+		t.Fatal(err)
+	}
+
+	q.Start()
+	defer q.Stop()
+
+	stats := waitForSent(t, q, 1)
+
+	if stats.Dropped != 1 {
+		t.Errorf("dropped %d requests, want 1", stats.Dropped)
+	}
+
+	if got, want := r.received(), []string{"readable"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("received %q, want %q", got, want)
+	}
+}
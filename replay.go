@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/rokett/citrix-netscaler-exporter/netscaler/nitrotest"
+)
+
+var (
+	// replayServers holds a fake Nitro API for each recording which has been scraped, keyed by target.
+	replayServers   = make(map[string]*nitrotest.Server)
+	replayServersMu sync.Mutex
+)
+
+// replayServer returns the fake Nitro API serving the recording for target, starting it if needed.
+// The target is the name of a directory within the replay directory, as written by the record subcommand.
+func replayServer(target string) (*nitrotest.Server, error) {
+	if target == "" || target != filepath.Base(target) || strings.HasPrefix(target, ".") {
+		return nil, errors.New("replay target must be the name of a directory within " + *replayDir)
+	}
+
+	replayServersMu.Lock()
+	defer replayServersMu.Unlock()
+
+	if s, ok := replayServers[target]; ok {
+		return s, nil
+	}
+
+	dir := filepath.Join(*replayDir, target)
+
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		return nil, errors.New("no recording found for target " + target)
+	}
+
+	s, err := nitrotest.NewServerWithFixtures(os.DirFS(dir))
+	if err != nil {
+		return nil, errors.Wrap(err, "error loading recording for target "+target)
+	}
+
+	replayServers[target] = s
+
+	return s, nil
+}
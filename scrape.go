@@ -0,0 +1,117 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+var (
+	coalescedScrapes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "citrix_netscaler_scrapes_coalesced_total",
+			Help: "Number of scrapes served from a collection already in progress for another scrape of the same target",
+		},
+		[]string{
+			"ns_instance",
+		},
+	)
+
+	throttledScrapes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "citrix_netscaler_scrapes_throttled_total",
+			Help: "Number of scrapes served from the previous collection because it completed less than min_scrape_interval ago",
+		},
+		[]string{
+			"ns_instance",
+		},
+	)
+
+	// collections holds the latest collection for each target, which may still be in progress.
+	// Keyed by target, module, whether the certificate check is skipped, and the partitions collected.
+	collections   = make(map[collectionKey]*collection)
+	collectionsMu sync.Mutex
+)
+
+type collectionKey struct {
+	target     string
+	module     string
+	ignoreCert bool
+	partitions string
+}
+
+// collection is the result of gathering every metric from a target once; done is closed when it completes.
+type collection struct {
+	done      chan struct{}
+	families  []*dto.MetricFamily
+	err       error
+	completed time.Time
+}
+
+// scrape gathers the metrics for the target using the exporter, unless another scrape of the same target is already doing so,
+// in which case its result is shared rather than logging in to the NetScaler a second time.
+// A successful result is also reused by scrapes within min_scrape_interval of it completing.
+func scrape(target string, module string, ignoreCert bool, partitions []string, exporter prometheus.Collector) ([]*dto.MetricFamily, error) {
+	key := collectionKey{target, module, ignoreCert, strings.Join(partitions, ",")}
+	nsInstance := targetInstance(target)
+
+	collectionsMu.Lock()
+
+	if c, ok := collections[key]; ok {
+		select {
+		case <-c.done:
+			if c.err == nil && time.Since(c.completed) < *minScrapeInterval {
+				collectionsMu.Unlock()
+				throttledScrapes.WithLabelValues(nsInstance).Inc()
+				return c.families, nil
+			}
+		default:
+			collectionsMu.Unlock()
+			coalescedScrapes.WithLabelValues(nsInstance).Inc()
+			<-c.done
+			return c.families, c.err
+		}
+	}
+
+	c := &collection{
+		done: make(chan struct{}),
+	}
+	collections[key] = c
+
+	collectionsMu.Unlock()
+
+	registry := prometheus.NewRegistry()
+
+	c.err = registry.Register(exporter)
+	if c.err == nil {
+		c.families, c.err = registry.Gather()
+	}
+	c.completed = time.Now()
+
+	close(c.done)
+
+	// A collection is only reused within min_scrape_interval of completing successfully, so it is forgotten after that
+	// rather than keeping an entry for every target, module and set of partitions ever scraped.
+	if c.err != nil || *minScrapeInterval <= 0 {
+		forgetCollection(key, c)
+	} else {
+		time.AfterFunc(*minScrapeInterval, func() {
+			forgetCollection(key, c)
+		})
+	}
+
+	return c.families, c.err
+}
+
+// forgetCollection removes the collection from collections, unless it has already been replaced by a newer one.
+func forgetCollection(key collectionKey, c *collection) {
+	collectionsMu.Lock()
+	defer collectionsMu.Unlock()
+
+	if collections[key] == c {
+		delete(collections, key)
+	}
+}
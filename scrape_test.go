@@ -0,0 +1,240 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rokett/citrix-netscaler-exporter/collector"
+	"github.com/rokett/citrix-netscaler-exporter/netscaler/nitrotest"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// collectionCount returns the number of collections held.
+func collectionCount() int {
+	collectionsMu.Lock()
+	defer collectionsMu.Unlock()
+
+	return len(collections)
+}
+
+func TestScrapeForgetsCollections(t *testing.T) {
+	defer func(d time.Duration) {
+		*minScrapeInterval = d
+	}(*minScrapeInterval)
+
+	newCollector := func() prometheus.Collector {
+		return prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_gauge", Help: "Test gauge"})
+	}
+
+	*minScrapeInterval = 0
+
+	for _, target := range []string{"https://ns01", "https://ns02", "https://ns03"} {
+		_, err := scrape(target, "adc", false, nil, newCollector())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := collectionCount(); n != 0 {
+		t.Errorf("holding %d collections with no min_scrape_interval, want 0", n)
+	}
+
+	*minScrapeInterval = 50 * time.Millisecond
+
+	_, err := scrape("https://ns01", "adc", false, nil, newCollector())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := collectionCount(); n != 1 {
+		t.Errorf("holding %d collections within min_scrape_interval, want 1", n)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	if n := collectionCount(); n != 0 {
+		t.Errorf("holding %d collections after min_scrape_interval, want 0", n)
+	}
+}
+
+// resetCollections forgets every collection when the test ends, so that collections held for min_scrape_interval do not leak into other tests.
+func resetCollections(t *testing.T) {
+	t.Cleanup(func() {
+		collectionsMu.Lock()
+		defer collectionsMu.Unlock()
+
+		collections = make(map[collectionKey]*collection)
+	})
+}
+
+// scrapeTestServer returns a fake NetScaler which is slow enough to respond that scrapes started together overlap.
+func scrapeTestServer(t *testing.T) *nitrotest.Server {
+	srv, err := nitrotest.NewServerWithFixtures(nitrotest.Fixtures())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Close)
+
+	srv.SetLatency(10 * time.Millisecond)
+
+	return srv
+}
+
+func newScrapeTestExporter(t *testing.T, srv *nitrotest.Server, partitions []string) prometheus.Collector {
+	e, err := collector.NewExporter(srv.URL, "user", "pass", false, log.NewNopLogger(), targetInstance(srv.URL), collector.Options{Partitions: partitions})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return e
+}
+
+func counterValue(c *prometheus.CounterVec, nsInstance string) float64 {
+	m := &dto.Metric{}
+	c.WithLabelValues(nsInstance).Write(m)
+
+	return m.GetCounter().GetValue()
+}
+
+func TestScrapeCoalesces(t *testing.T) {
+	defer func(d time.Duration) {
+		*minScrapeInterval = d
+	}(*minScrapeInterval)
+
+	*minScrapeInterval = 0
+	resetCollections(t)
+
+	srv := scrapeTestServer(t)
+	nsInstance := targetInstance(srv.URL)
+
+	const scrapes = 5
+
+	results := make([][]*dto.MetricFamily, scrapes)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < scrapes; i++ {
+		wg.Add(1)
+
+		go func(i int, exporter prometheus.Collector) {
+			defer wg.Done()
+
+			families, err := scrape(srv.URL, "adc", false, nil, exporter)
+			if err != nil {
+				t.Error(err)
+			}
+
+			results[i] = families
+		}(i, newScrapeTestExporter(t, srv, nil))
+	}
+
+	wg.Wait()
+
+	if n := srv.Requests("config/login"); n != 1 {
+		t.Errorf("logged in %d times for %d concurrent scrapes, want 1", n, scrapes)
+	}
+
+	if got := counterValue(coalescedScrapes, nsInstance); got != scrapes-1 {
+		t.Errorf("scrapes coalesced = %v, want %d", got, scrapes-1)
+	}
+
+	for i, families := range results {
+		if len(families) == 0 || len(families) != len(results[0]) {
+			t.Errorf("scrape %d returned %d metric families, want the %d of the shared collection", i, len(families), len(results[0]))
+		}
+	}
+}
+
+func TestScrapeDoesNotCoalesceDifferentCollections(t *testing.T) {
+	defer func(d time.Duration) {
+		*minScrapeInterval = d
+	}(*minScrapeInterval)
+
+	*minScrapeInterval = time.Minute
+	resetCollections(t)
+
+	srv := scrapeTestServer(t)
+	nsInstance := targetInstance(srv.URL)
+
+	scrapes := []struct {
+		module     string
+		ignoreCert bool
+		partitions []string
+	}{
+		{module: "adc"},
+		{module: "adc", partitions: []string{"default"}},
+		{module: "adc", ignoreCert: true},
+		// The exporter does not match the module here; only the key the collection is held under matters.
+		{module: "sdx"},
+	}
+
+	var wg sync.WaitGroup
+
+	for _, s := range scrapes {
+		wg.Add(1)
+
+		go func(module string, ignoreCert bool, partitions []string, exporter prometheus.Collector) {
+			defer wg.Done()
+
+			_, err := scrape(srv.URL, module, ignoreCert, partitions, exporter)
+			if err != nil {
+				t.Error(err)
+			}
+		}(s.module, s.ignoreCert, s.partitions, newScrapeTestExporter(t, srv, s.partitions))
+	}
+
+	wg.Wait()
+
+	if n := srv.Requests("config/login"); n != len(scrapes) {
+		t.Errorf("logged in %d times, want once for each of the %d collections", n, len(scrapes))
+	}
+
+	if got := counterValue(coalescedScrapes, nsInstance) + counterValue(throttledScrapes, nsInstance); got != 0 {
+		t.Errorf("%v scrapes were coalesced or throttled, want none", got)
+	}
+}
+
+func TestScrapeMinInterval(t *testing.T) {
+	defer func(d time.Duration) {
+		*minScrapeInterval = d
+	}(*minScrapeInterval)
+
+	*minScrapeInterval = time.Minute
+	resetCollections(t)
+
+	srv := scrapeTestServer(t)
+	nsInstance := targetInstance(srv.URL)
+
+	first, err := scrape(srv.URL, "adc", false, nil, newScrapeTestExporter(t, srv, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		families, err := scrape(srv.URL, "adc", false, nil, newScrapeTestExporter(t, srv, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The families are shared with the first scrape, not gathered again.
+		if len(families) != len(first) || &families[0] != &first[0] {
+			t.Error("scrape within min_scrape_interval did not return the previous collection")
+		}
+	}
+
+	if n := srv.Requests("config/login"); n != 1 {
+		t.Errorf("logged in %d times, want 1", n)
+	}
+
+	if got := counterValue(throttledScrapes, nsInstance); got != 2 {
+		t.Errorf("scrapes throttled = %v, want 2", got)
+	}
+
+	if got := counterValue(coalescedScrapes, nsInstance); got != 0 {
+		t.Errorf("scrapes coalesced = %v, want 0", got)
+	}
+}
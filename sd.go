@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-kit/kit/log/level"
+)
+
+// sdTargetGroup is a group of targets in the Prometheus http_sd_config format.
+type sdTargetGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels"`
+}
+
+// sdHandler serves the targets in the configuration file, and their discovered HA and cluster peers, for Prometheus HTTP service discovery.
+// Each target is pointed at the /netscaler endpoint of this exporter, with the target parameter set, so Prometheus needs no relabelling.
+func sdHandler(w http.ResponseWriter, r *http.Request) {
+	groups := []sdTargetGroup{}
+
+	for _, t := range targetPoller.list() {
+		labels := t.sdLabels()
+		labels["__metrics_path__"] = "/netscaler"
+		labels["__param_target"] = t.URL
+
+		if t.Module != "" {
+			labels["__param_module"] = t.Module
+		}
+
+		if _, ok := labels["instance"]; !ok {
+			labels["instance"] = t.URL
+		}
+
+		groups = append(groups, sdTargetGroup{
+			Targets: []string{r.Host},
+			Labels:  labels,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	err := json.NewEncoder(w).Encode(groups)
+	if err != nil {
+		level.Error(logger).Log("msg", "error writing service discovery response", "err", err)
+	}
+}